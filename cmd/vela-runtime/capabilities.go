@@ -0,0 +1,53 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-vela/pkg-runtime/runtime"
+
+	"github.com/urfave/cli/v2"
+)
+
+// capabilitiesCommand represents the command for displaying
+// the features supported by the daemon for the runtime.
+var capabilitiesCommand = &cli.Command{
+	Name:   "capabilities",
+	Usage:  "display the features supported by the daemon for the runtime",
+	Action: capabilities,
+}
+
+// capabilities displays the features supported by the
+// daemon based off the configuration provided.
+func capabilities(c *cli.Context) error {
+	// setup the runtime
+	r, err := runtime.New(setupRuntime(c))
+	if err != nil {
+		return err
+	}
+
+	// check if the runtime supports reporting capabilities
+	reporter, ok := r.(runtime.CapabilityReporter)
+	if !ok {
+		return fmt.Errorf("runtime driver %s does not support reporting capabilities", c.String("runtime.driver"))
+	}
+
+	caps, err := reporter.Capabilities(context.Background())
+	if err != nil {
+		return err
+	}
+
+	output, err := json.MarshalIndent(caps, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(output))
+
+	return nil
+}
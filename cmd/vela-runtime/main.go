@@ -39,6 +39,13 @@ func main() {
 
 	app.Flags = flags()
 
+	// Package Commands
+
+	app.Commands = []*cli.Command{
+		capabilitiesCommand,
+		reapCommand,
+	}
+
 	// Package Start
 
 	err := app.Run(os.Args)
@@ -0,0 +1,88 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-vela/pkg-runtime/runtime"
+
+	"github.com/urfave/cli/v2"
+)
+
+// reapCommand represents the command for removing
+// orphaned resources for builds from the runtime.
+var reapCommand = &cli.Command{
+	Name:   "reap",
+	Usage:  "remove orphaned resources for builds from the runtime",
+	Action: reap,
+	Flags: []cli.Flag{
+		&cli.DurationFlag{
+			EnvVars: []string{"VELA_REAP_OLDER_THAN", "REAP_OLDER_THAN"},
+			Name:    "older-than",
+			Usage:   "duration a resource must exist, or the heartbeat for a pod must be stale, before it is removed",
+			Value:   24 * time.Hour,
+		},
+		&cli.BoolFlag{
+			EnvVars: []string{"VELA_REAP_DRY_RUN", "REAP_DRY_RUN"},
+			Name:    "dry-run",
+			Usage:   "list the resources without removing them",
+		},
+	},
+}
+
+// reap removes orphaned resources for builds
+// based off the configuration provided.
+func reap(c *cli.Context) error {
+	// setup the runtime
+	r, err := runtime.New(setupRuntime(c))
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	// check if the runtime supports reaping
+	switch reaper := r.(type) {
+	case runtime.Reaper:
+		report, err := reaper.Reap(ctx, c.Duration("older-than"), c.Bool("dry-run"))
+		if err != nil {
+			return err
+		}
+
+		printReaped(report.DryRun, "container", report.Containers)
+		printReaped(report.DryRun, "network", report.Networks)
+		printReaped(report.DryRun, "volume", report.Volumes)
+	case runtime.PodReaper:
+		report, err := reaper.Reap(ctx, c.Duration("older-than"), c.Bool("dry-run"))
+		if err != nil {
+			return err
+		}
+
+		printReaped(report.DryRun, "pod", report.Pods)
+		printReaped(report.DryRun, "secret", report.Secrets)
+		printReaped(report.DryRun, "persistent volume claim", report.Claims)
+		printReaped(report.DryRun, "network policy", report.Policies)
+	default:
+		return fmt.Errorf("runtime driver %s does not support reaping", c.String("runtime.driver"))
+	}
+
+	return nil
+}
+
+// printReaped is a helper function to print
+// the resources of a kind that were reaped.
+func printReaped(dryRun bool, kind string, names []string) {
+	action := "removed"
+	if dryRun {
+		action = "would remove"
+	}
+
+	for _, name := range names {
+		fmt.Printf("%s %s %s\n", action, kind, name)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 
 	"github.com/go-vela/pkg-runtime/runtime"
+	"github.com/go-vela/types/pipeline"
 
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
@@ -18,8 +19,6 @@ import (
 )
 
 // run executes the package based off the configuration provided.
-//
-// nolint: funlen // ignore function length due to comments
 func run(c *cli.Context) error {
 	// set the log level for the plugin
 	switch c.String("runtime.log.level") {
@@ -54,11 +53,7 @@ func run(c *cli.Context) error {
 	}
 
 	// setup the runtime
-	r, err := runtime.New(&runtime.Setup{
-		Driver:     c.String("runtime.driver"),
-		ConfigFile: c.String("runtime.config"),
-		Namespace:  c.String("runtime.namespace"),
-	})
+	r, err := runtime.New(setupRuntime(c))
 	if err != nil {
 		logrus.Fatal(err)
 	}
@@ -66,6 +61,28 @@ func run(c *cli.Context) error {
 	// setup the context
 	ctx := context.Background()
 
+	return runPipeline(ctx, r, p)
+}
+
+// runPipeline executes the pipeline with the runtime provided.
+//
+// nolint: funlen // ignore function length due to comments
+func runPipeline(ctx context.Context, r runtime.Engine, p *pipeline.Build) error {
+	logrus.Infof("setting up build for pipeline %s", p.ID)
+	err := r.SetupBuild(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		logrus.Infof("removing build for pipeline %s", p.ID)
+		// remove the build after removing the other resources
+		err := r.RemoveBuild(ctx, p)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+	}()
+
 	logrus.Infof("creating network for pipeline %s", p.ID)
 	err = r.CreateNetwork(ctx, p)
 	if err != nil {
@@ -121,6 +138,12 @@ func run(c *cli.Context) error {
 		}
 	}
 
+	logrus.Infof("assembling build for pipeline %s", p.ID)
+	err = r.AssembleBuild(ctx, p)
+	if err != nil {
+		return err
+	}
+
 	for _, step := range p.Steps {
 		// https://golang.org/doc/faq#closures_and_goroutines
 		tmp := step
@@ -0,0 +1,120 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package main
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-vela/pkg-runtime/runtime"
+	"github.com/go-vela/pkg-runtime/runtime/kubernetes"
+	"github.com/go-vela/types/pipeline"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// recordEngine represents a runtime recording the calls for
+// the build, with the containers exiting once they're run.
+type recordEngine struct {
+	runtime.Engine
+
+	calls []string
+}
+
+// SetupBuild records the build was set up.
+func (r *recordEngine) SetupBuild(ctx context.Context, b *pipeline.Build) error {
+	r.calls = append(r.calls, "SetupBuild")
+
+	return r.Engine.SetupBuild(ctx, b)
+}
+
+// AssembleBuild records the build was assembled.
+func (r *recordEngine) AssembleBuild(ctx context.Context, b *pipeline.Build) error {
+	r.calls = append(r.calls, "AssembleBuild")
+
+	return r.Engine.AssembleBuild(ctx, b)
+}
+
+// RemoveBuild records the build was removed.
+func (r *recordEngine) RemoveBuild(ctx context.Context, b *pipeline.Build) error {
+	r.calls = append(r.calls, "RemoveBuild")
+
+	return r.Engine.RemoveBuild(ctx, b)
+}
+
+// RunContainer records the container was run.
+func (r *recordEngine) RunContainer(ctx context.Context, ctn *pipeline.Container, b *pipeline.Build) error {
+	r.calls = append(r.calls, "RunContainer "+ctn.Name)
+
+	return r.Engine.RunContainer(ctx, ctn, b)
+}
+
+// TailContainer returns empty logs for the container.
+func (r *recordEngine) TailContainer(ctx context.Context, ctn *pipeline.Container) (io.ReadCloser, error) {
+	return ioutil.NopCloser(strings.NewReader("")), nil
+}
+
+// WaitContainer returns once the container is run.
+func (r *recordEngine) WaitContainer(ctx context.Context, ctn *pipeline.Container) error {
+	return nil
+}
+
+func TestVelaRuntime_RunPipeline_Kubernetes(t *testing.T) {
+	// setup types
+	_pipeline := &pipeline.Build{
+		ID:      "github-octocat-1",
+		Version: "1",
+		Steps: pipeline.ContainerSlice{
+			{
+				ID:     "step-github-octocat-1-init",
+				Image:  "#init",
+				Name:   "init",
+				Number: 1,
+				Pull:   "not_present",
+			},
+			{
+				ID:        "step-github-octocat-1-echo",
+				Commands:  []string{"echo hello"},
+				Directory: "/vela/src/github.com/octocat/helloworld",
+				Image:     "alpine:latest",
+				Name:      "echo",
+				Number:    2,
+				Pull:      "not_present",
+			},
+		},
+	}
+
+	_engine, err := kubernetes.NewMock(&v1.Pod{})
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	_runtime := &recordEngine{Engine: _engine}
+
+	// run test
+	err = runPipeline(context.Background(), _runtime, _pipeline)
+	if err != nil {
+		t.Errorf("runPipeline returned err: %v", err)
+	}
+
+	want := []string{"SetupBuild", "AssembleBuild", "RunContainer echo", "RemoveBuild"}
+
+	if !reflect.DeepEqual(_runtime.calls, want) {
+		t.Errorf("runPipeline calls are %v, want %v", _runtime.calls, want)
+	}
+
+	// check the pod for the build is removed
+	_, err = _engine.Kubernetes.CoreV1().Pods("test").
+		Get(context.Background(), _pipeline.ID, metav1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("runPipeline did not remove pod %s: %v", _pipeline.ID, err)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package main
+
+import (
+	"github.com/go-vela/pkg-runtime/runtime"
+
+	"github.com/urfave/cli/v2"
+)
+
+// setupRuntime captures the configuration for the
+// runtime from all of the runtime flags provided.
+func setupRuntime(c *cli.Context) *runtime.Setup {
+	return &runtime.Setup{
+		Driver:                 c.String("runtime.driver"),
+		ConfigFile:             c.String("runtime.config"),
+		Host:                   c.String("runtime.host"),
+		TLSCA:                  c.String("runtime.tls.ca"),
+		TLSCert:                c.String("runtime.tls.cert"),
+		TLSKey:                 c.String("runtime.tls.key"),
+		Timeout:                c.Duration("runtime.timeout"),
+		HostVolumes:            c.StringSlice("runtime.volumes"),
+		NetworkDriver:          c.String("runtime.network.driver"),
+		NetworkSubnets:         c.StringSlice("runtime.network.subnets"),
+		NetworkInternal:        c.Bool("runtime.network.internal"),
+		NetworkIPv6:            c.Bool("runtime.network.ipv6"),
+		NetworkDNS:             c.StringSlice("runtime.network.dns"),
+		NetworkDNSSearch:       c.StringSlice("runtime.network.dns-search"),
+		NetworkExtraHosts:      c.StringSlice("runtime.network.extra-hosts"),
+		EgressPolicy:           c.Bool("runtime.egress.policy"),
+		EgressAllow:            c.StringSlice("runtime.egress.allow"),
+		EgressProxy:            c.String("runtime.egress.proxy"),
+		Namespace:              c.String("runtime.namespace"),
+		PodTemplate:            c.String("runtime.pod-template"),
+		WorkspaceVolume:        c.String("runtime.workspace.volume"),
+		WorkspaceStorageClass:  c.String("runtime.workspace.storage-class"),
+		WorkspaceSize:          c.String("runtime.workspace.size"),
+		PrivilegedImages:       c.StringSlice("runtime.privileged-images"),
+		AllowedImages:          c.StringSlice("runtime.allowed-images"),
+		DeniedImages:           c.StringSlice("runtime.denied-images"),
+		AllowedRegistries:      c.StringSlice("runtime.allowed-registries"),
+		DeniedRegistries:       c.StringSlice("runtime.denied-registries"),
+		RegistryMirrors:        c.StringSlice("runtime.registry-mirrors"),
+		RegistryMirrorFallback: c.Bool("runtime.registry-mirror-fallback"),
+		SignatureKeys:          c.StringSlice("runtime.signature-keys"),
+		SignedImages:           c.StringSlice("runtime.signed-images"),
+		Platform:               c.String("runtime.platform"),
+		LogLimitBytes:          c.String("runtime.log-limit.bytes"),
+		LogLimitLines:          c.Int64("runtime.log-limit.lines"),
+		LogLimitTail:           c.Int("runtime.log-limit.tail"),
+		LogLimitKill:           c.Bool("runtime.log-limit.kill"),
+		SecretFiles:            c.StringSlice("runtime.secret-files"),
+		WorkerID:               c.String("runtime.worker-id"),
+		ImageGCMaxAge:          c.Duration("runtime.image-gc.max-age"),
+		ImageGCInterval:        c.Duration("runtime.image-gc.interval"),
+		ImageGCHighWater:       c.String("runtime.image-gc.high-water"),
+		ImageGCLowWater:        c.String("runtime.image-gc.low-water"),
+		ImageGCProtectedImages: c.StringSlice("runtime.image-gc.protected-images"),
+	}
+}
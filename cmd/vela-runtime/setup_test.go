@@ -0,0 +1,71 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-vela/pkg-runtime/runtime"
+
+	"github.com/urfave/cli/v2"
+)
+
+func TestVelaRuntime_SetupRuntime(t *testing.T) {
+	// setup types
+	var got *runtime.Setup
+
+	app := cli.NewApp()
+	app.Flags = flags()
+	app.Action = func(c *cli.Context) error {
+		got = setupRuntime(c)
+
+		return nil
+	}
+
+	// run test
+	err := app.Run([]string{
+		"vela-runtime",
+		"--runtime.driver", "docker",
+		"--runtime.registry-mirrors", "docker.io=mirror.company.com",
+		"--runtime.allowed-images", "alpine",
+		"--runtime.signed-images", "target/vela-*",
+		"--runtime.image-gc.max-age", "24h",
+		"--runtime.log-limit.lines", "100",
+		"--runtime.secret-files", "token",
+		"--runtime.worker-id", "worker-1",
+		"--runtime.network.subnets", "10.10.0.0/16=24",
+	})
+	if err != nil {
+		t.Errorf("Run returned err: %v", err)
+	}
+
+	if got == nil {
+		t.Fatalf("setupRuntime was not called")
+	}
+
+	want := map[string]interface{}{
+		"Driver":          "docker",
+		"RegistryMirrors": []string{"docker.io=mirror.company.com"},
+		"AllowedImages":   []string{"alpine"},
+		"SignedImages":    []string{"target/vela-*"},
+		"ImageGCMaxAge":   24 * time.Hour,
+		"ImageGCInterval": 5 * time.Minute,
+		"LogLimitLines":   int64(100),
+		"SecretFiles":     []string{"token"},
+		"WorkerID":        "worker-1",
+		"NetworkSubnets":  []string{"10.10.0.0/16=24"},
+		"NetworkDriver":   "bridge",
+	}
+
+	for field, value := range want {
+		actual := reflect.ValueOf(got).Elem().FieldByName(field).Interface()
+
+		if !reflect.DeepEqual(actual, value) {
+			t.Errorf("setupRuntime %s is %v, want %v", field, actual, value)
+		}
+	}
+}
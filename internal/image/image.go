@@ -6,6 +6,8 @@ package image
 
 import (
 	"github.com/docker/distribution/reference"
+
+	"github.com/opencontainers/go-digest"
 )
 
 // Parse digests the provided image into a fully
@@ -85,3 +87,42 @@ func IsPrivilegedImage(image, privileged string) (bool, error) {
 
 	return match, nil
 }
+
+// ParseWithDigest digests the provided image into a fully
+// qualified canonical reference pinned to the provided digest.
+// If the image is already pinned to a digest, it will return
+// the fully qualified canonical reference for the image.
+func ParseWithDigest(_image, _digest string) (string, error) {
+	// parse the image provided into a fully qualified canonical reference
+	//
+	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/image#ParseWithError
+	_canonical, err := ParseWithError(_image)
+	if err != nil {
+		return _canonical, err
+	}
+
+	// https://pkg.go.dev/github.com/docker/distribution/reference?tab=doc#ParseNamed
+	_named, err := reference.ParseNamed(_canonical)
+	if err != nil {
+		return _canonical, err
+	}
+
+	// check if the image is already pinned to a digest
+	if _, ok := _named.(reference.Digested); ok {
+		return _canonical, nil
+	}
+
+	// https://pkg.go.dev/github.com/opencontainers/go-digest#Parse
+	d, err := digest.Parse(_digest)
+	if err != nil {
+		return _canonical, err
+	}
+
+	// https://pkg.go.dev/github.com/docker/distribution/reference?tab=doc#WithDigest
+	_pinned, err := reference.WithDigest(_named, d)
+	if err != nil {
+		return _canonical, err
+	}
+
+	return _pinned.String(), nil
+}
@@ -186,3 +186,72 @@ func TestImage_IsPrivilegedImage(t *testing.T) {
 		})
 	}
 }
+
+func TestImage_ParseWithDigest(t *testing.T) {
+	// setup types
+	digest := "sha256:1a3f5e7d9c1b3a5f7e9d1c3b5a7f9e1d3c5b7a9f1e3d5d7c9b1a3f5e7d9c1b3a"
+
+	// setup tests
+	tests := []struct {
+		failure bool
+		image   string
+		digest  string
+		want    string
+	}{
+		{
+			failure: false,
+			image:   "golang",
+			digest:  digest,
+			want:    "docker.io/library/golang:latest@" + digest,
+		},
+		{
+			failure: false,
+			image:   "gcr.io/library/golang:1.14",
+			digest:  digest,
+			want:    "gcr.io/library/golang:1.14@" + digest,
+		},
+		{
+			failure: false,
+			image:   "golang@sha256:9e1d3c5b7a9f1e3d5d7c9b1a3f5e7d9c1b3a1a3f5e7d9c1b3a5f7e9d1c3b5a7f",
+			digest:  digest,
+			want:    "docker.io/library/golang@sha256:9e1d3c5b7a9f1e3d5d7c9b1a3f5e7d9c1b3a1a3f5e7d9c1b3a5f7e9d1c3b5a7f",
+		},
+		{
+			failure: true,
+			image:   "golang",
+			digest:  "foo",
+			want:    "docker.io/library/golang:latest",
+		},
+		{
+			failure: true,
+			image:   "!@#$%^&*()",
+			digest:  digest,
+			want:    "!@#$%^&*()",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got, err := ParseWithDigest(test.image, test.digest)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("ParseWithDigest should have returned err")
+			}
+
+			if !strings.EqualFold(got, test.want) {
+				t.Errorf("ParseWithDigest is %s want %s", got, test.want)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ParseWithDigest returned err: %v", err)
+		}
+
+		if !strings.EqualFold(got, test.want) {
+			t.Errorf("ParseWithDigest is %s want %s", got, test.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package image
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+)
+
+// ParseMirrors digests the provided list of registry mirrors,
+// in the form <registry>=<mirror>, into a map of registries
+// to the mirror that should be used in their place.
+func ParseMirrors(mirrors []string) (map[string]string, error) {
+	m := make(map[string]string)
+
+	// iterate through all mirrors provided
+	for _, mirror := range mirrors {
+		// split the mirror into the registry and mirror parts
+		parts := strings.SplitN(mirror, "=", 2)
+
+		// nolint: gomnd // ignore magic number
+		if len(parts) != 2 ||
+			len(strings.TrimSpace(parts[0])) == 0 ||
+			len(strings.TrimSpace(parts[1])) == 0 {
+			return nil, fmt.Errorf("invalid registry mirror provided: %s", mirror)
+		}
+
+		registry := normalizeRegistry(strings.TrimSpace(parts[0]))
+		host := strings.TrimSuffix(strings.TrimSpace(parts[1]), "/")
+
+		// check if the mirror contains a scheme
+		if strings.Contains(host, "://") {
+			return nil, fmt.Errorf("invalid registry mirror provided: %s (scheme not supported)", mirror)
+		}
+
+		m[registry] = host
+	}
+
+	return m, nil
+}
+
+// ParseWithMirror digests the provided image into a fully
+// qualified canonical reference and rewrites the registry
+// with the matching mirror. If no mirror matches the
+// registry, it will return the canonical reference.
+func ParseWithMirror(_image string, mirrors map[string]string) (string, error) {
+	// parse the image provided into a fully qualified canonical reference
+	//
+	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/image#ParseWithError
+	_canonical, err := ParseWithError(_image)
+	if err != nil {
+		return _canonical, err
+	}
+
+	// check if any mirrors were provided
+	if len(mirrors) == 0 {
+		return _canonical, nil
+	}
+
+	// https://pkg.go.dev/github.com/docker/distribution/reference?tab=doc#ParseNamed
+	_named, err := reference.ParseNamed(_canonical)
+	if err != nil {
+		return _canonical, err
+	}
+
+	// capture the registry hosting the image
+	//
+	// https://pkg.go.dev/github.com/docker/distribution/reference?tab=doc#Domain
+	registry := reference.Domain(_named)
+
+	// check if a mirror exists for the registry
+	mirror, ok := mirrors[registry]
+	if !ok {
+		return _canonical, nil
+	}
+
+	// replace the registry with the mirror
+	_mirrored := mirror + strings.TrimPrefix(_canonical, registry)
+
+	// ensure the mirrored image is a valid reference
+	//
+	// https://pkg.go.dev/github.com/docker/distribution/reference?tab=doc#ParseNamed
+	_, err = reference.ParseNamed(_mirrored)
+	if err != nil {
+		return _canonical, fmt.Errorf("invalid mirror %s for image %s: %w", mirror, _image, err)
+	}
+
+	return _mirrored, nil
+}
+
+// normalizeRegistry is a helper function to convert
+// legacy registry names to their canonical form.
+func normalizeRegistry(registry string) string {
+	// check if the registry is the legacy Docker Hub registry
+	if strings.EqualFold(registry, "index.docker.io") {
+		return "docker.io"
+	}
+
+	return strings.ToLower(registry)
+}
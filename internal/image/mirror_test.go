@@ -0,0 +1,156 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package image
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestImage_ParseMirrors(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		mirrors []string
+		want    map[string]string
+	}{
+		{
+			failure: false,
+			mirrors: []string{"docker.io=mirror.company.com:5000", "gcr.io=mirror.company.com/gcr/"},
+			want: map[string]string{
+				"docker.io": "mirror.company.com:5000",
+				"gcr.io":    "mirror.company.com/gcr",
+			},
+		},
+		{
+			failure: false,
+			mirrors: []string{"index.docker.io=mirror.company.com:5000"},
+			want:    map[string]string{"docker.io": "mirror.company.com:5000"},
+		},
+		{
+			failure: false,
+			mirrors: []string{},
+			want:    map[string]string{},
+		},
+		{
+			failure: true,
+			mirrors: []string{"docker.io"},
+		},
+		{
+			failure: true,
+			mirrors: []string{"docker.io="},
+		},
+		{
+			failure: true,
+			mirrors: []string{"docker.io=https://mirror.company.com"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got, err := ParseMirrors(test.mirrors)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("ParseMirrors should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ParseMirrors returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParseMirrors is %v want %v", got, test.want)
+		}
+	}
+}
+
+func TestImage_ParseWithMirror(t *testing.T) {
+	// setup types
+	mirrors := map[string]string{
+		"docker.io": "mirror.company.com:5000",
+		"gcr.io":    "mirror.company.com/gcr",
+		"quay.io":   "!@#$%^&*()",
+	}
+
+	// setup tests
+	tests := []struct {
+		failure bool
+		image   string
+		mirrors map[string]string
+		want    string
+	}{
+		{
+			failure: false,
+			image:   "golang",
+			mirrors: mirrors,
+			want:    "mirror.company.com:5000/library/golang:latest",
+		},
+		{
+			failure: false,
+			image:   "index.docker.io/target/vela-git:v0.4.0",
+			mirrors: mirrors,
+			want:    "mirror.company.com:5000/target/vela-git:v0.4.0",
+		},
+		{
+			failure: false,
+			image:   "gcr.io/library/golang:1.14",
+			mirrors: mirrors,
+			want:    "mirror.company.com/gcr/library/golang:1.14",
+		},
+		{
+			failure: false,
+			image:   "docker.company.com/foo/bar",
+			mirrors: mirrors,
+			want:    "docker.company.com/foo/bar:latest",
+		},
+		{
+			failure: false,
+			image:   "golang",
+			mirrors: nil,
+			want:    "docker.io/library/golang:latest",
+		},
+		{
+			failure: true,
+			image:   "quay.io/foo/bar",
+			mirrors: mirrors,
+			want:    "quay.io/foo/bar:latest",
+		},
+		{
+			failure: true,
+			image:   "!@#$%^&*()",
+			mirrors: mirrors,
+			want:    "!@#$%^&*()",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got, err := ParseWithMirror(test.image, test.mirrors)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("ParseWithMirror should have returned err")
+			}
+
+			if got != test.want {
+				t.Errorf("ParseWithMirror is %s want %s", got, test.want)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ParseWithMirror returned err: %v", err)
+		}
+
+		if got != test.want {
+			t.Errorf("ParseWithMirror is %s want %s", got, test.want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package image
+
+import (
+	"fmt"
+	"strings"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// defaultOS represents the operating system used
+// when a platform only provides an architecture.
+const defaultOS = "linux"
+
+// operatingSystems represents the set of supported
+// operating systems for an image platform.
+var operatingSystems = map[string]bool{
+	"darwin":  true,
+	"freebsd": true,
+	"linux":   true,
+	"windows": true,
+}
+
+// architectures represents the set of supported
+// architectures for an image platform mapped to
+// the normalized architecture and default variant.
+var architectures = map[string][2]string{
+	"386":     {"386", ""},
+	"i386":    {"386", ""},
+	"i686":    {"386", ""},
+	"amd64":   {"amd64", ""},
+	"x86_64":  {"amd64", ""},
+	"x86-64":  {"amd64", ""},
+	"arm":     {"arm", ""},
+	"armhf":   {"arm", "v7"},
+	"armel":   {"arm", "v6"},
+	"arm64":   {"arm64", ""},
+	"aarch64": {"arm64", ""},
+	"ppc64le": {"ppc64le", ""},
+	"s390x":   {"s390x", ""},
+	"riscv64": {"riscv64", ""},
+}
+
+// ParsePlatform digests the provided platform, in the form
+// <os>/<arch>[/<variant>] or <arch>, into the normalized
+// OCI representation of the platform. If the platform
+// is empty, it will return nil.
+func ParsePlatform(platform string) (*specs.Platform, error) {
+	// check if a platform was provided
+	if len(strings.TrimSpace(platform)) == 0 {
+		return nil, nil
+	}
+
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(platform)), "/")
+
+	// check if only an architecture was provided
+	if len(parts) == 1 {
+		parts = []string{defaultOS, parts[0]}
+	}
+
+	// check if too many parts were provided
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("invalid platform %s: must be in the form <os>/<arch>[/<variant>]", platform)
+	}
+
+	// check if the operating system is supported
+	if !operatingSystems[parts[0]] {
+		return nil, fmt.Errorf("invalid platform %s: unsupported operating system %s", platform, parts[0])
+	}
+
+	// check if the architecture is supported
+	arch, ok := architectures[parts[1]]
+	if !ok {
+		return nil, fmt.Errorf("invalid platform %s: unsupported architecture %s", platform, parts[1])
+	}
+
+	p := &specs.Platform{
+		OS:           parts[0],
+		Architecture: arch[0],
+		Variant:      arch[1],
+	}
+
+	// check if a variant was provided
+	if len(parts) == 3 {
+		p.Variant = parts[2]
+	}
+
+	// normalize the default variant for the architecture
+	//
+	// https://github.com/containerd/containerd/blob/main/platforms/database.go
+	if p.Architecture == "arm64" && p.Variant == "v8" {
+		p.Variant = ""
+	}
+
+	return p, nil
+}
+
+// FormatPlatform returns the string representation, in the
+// form <os>/<arch>[/<variant>], of the provided platform.
+// If the platform is nil, it will return an empty string.
+func FormatPlatform(p *specs.Platform) string {
+	// check if a platform was provided
+	if p == nil {
+		return ""
+	}
+
+	// check if a variant was provided
+	if len(p.Variant) == 0 {
+		return fmt.Sprintf("%s/%s", p.OS, p.Architecture)
+	}
+
+	return fmt.Sprintf("%s/%s/%s", p.OS, p.Architecture, p.Variant)
+}
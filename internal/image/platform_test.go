@@ -0,0 +1,120 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package image
+
+import (
+	"reflect"
+	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestImage_ParsePlatform(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure  bool
+		platform string
+		want     *specs.Platform
+	}{
+		{
+			failure:  false,
+			platform: "linux/amd64",
+			want:     &specs.Platform{OS: "linux", Architecture: "amd64"},
+		},
+		{
+			failure:  false,
+			platform: "linux/arm64/v8",
+			want:     &specs.Platform{OS: "linux", Architecture: "arm64"},
+		},
+		{
+			failure:  false,
+			platform: "linux/arm/v7",
+			want:     &specs.Platform{OS: "linux", Architecture: "arm", Variant: "v7"},
+		},
+		{
+			failure:  false,
+			platform: "aarch64",
+			want:     &specs.Platform{OS: "linux", Architecture: "arm64"},
+		},
+		{
+			failure:  false,
+			platform: "Linux/x86_64",
+			want:     &specs.Platform{OS: "linux", Architecture: "amd64"},
+		},
+		{
+			failure:  false,
+			platform: "armhf",
+			want:     &specs.Platform{OS: "linux", Architecture: "arm", Variant: "v7"},
+		},
+		{
+			failure:  false,
+			platform: "",
+			want:     nil,
+		},
+		{
+			failure:  true,
+			platform: "docker",
+		},
+		{
+			failure:  true,
+			platform: "plan9/amd64",
+		},
+		{
+			failure:  true,
+			platform: "linux/arm64/v8/foo",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got, err := ParsePlatform(test.platform)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("ParsePlatform for %s should have returned err", test.platform)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ParsePlatform for %s returned err: %v", test.platform, err)
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParsePlatform for %s is %v, want %v", test.platform, got, test.want)
+		}
+	}
+}
+
+func TestImage_FormatPlatform(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		platform *specs.Platform
+		want     string
+	}{
+		{
+			platform: &specs.Platform{OS: "linux", Architecture: "amd64"},
+			want:     "linux/amd64",
+		},
+		{
+			platform: &specs.Platform{OS: "linux", Architecture: "arm", Variant: "v7"},
+			want:     "linux/arm/v7",
+		},
+		{
+			platform: nil,
+			want:     "",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got := FormatPlatform(test.platform)
+
+		if got != test.want {
+			t.Errorf("FormatPlatform is %s, want %s", got, test.want)
+		}
+	}
+}
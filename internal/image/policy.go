@@ -0,0 +1,178 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package image
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+)
+
+const (
+	// RuleDeniedRegistry represents the policy rule
+	// for an image hosted in a denied registry.
+	RuleDeniedRegistry = "denied registry"
+	// RuleDeniedImage represents the policy rule
+	// for an image matching a denied pattern.
+	RuleDeniedImage = "denied image"
+	// RuleNotAllowed represents the policy rule for an
+	// image not matching any allowed pattern or registry.
+	RuleNotAllowed = "not allowed"
+	// RuleInvalidImage represents the policy rule
+	// for an image that can not be parsed.
+	RuleInvalidImage = "invalid image"
+)
+
+// Policy represents the set of rules for determining
+// which images are permitted to run for a runtime.
+//
+// Deny rules always take precedence over allow rules.
+// When no allow rules are provided, all images that
+// don't match a deny rule are permitted.
+type Policy struct {
+	// specifies a list of image patterns that are permitted to run
+	//
+	// https://pkg.go.dev/github.com/docker/distribution/reference#FamiliarMatch
+	AllowedImages []string
+	// specifies a list of image patterns that are not permitted to run
+	//
+	// https://pkg.go.dev/github.com/docker/distribution/reference#FamiliarMatch
+	DeniedImages []string
+	// specifies a list of registries that are permitted to host images
+	AllowedRegistries []string
+	// specifies a list of registries that are not permitted to host images
+	DeniedRegistries []string
+}
+
+// PolicyError represents an error returned when
+// an image is blocked by a rule from the Policy.
+type PolicyError struct {
+	// image that was blocked by the policy
+	Image string
+	// rule from the policy that blocked the image
+	Rule string
+	// pattern or registry from the rule that matched the image
+	Pattern string
+}
+
+// Error implements the error interface for the PolicyError.
+func (e *PolicyError) Error() string {
+	// check if a pattern was captured for the rule
+	if len(e.Pattern) == 0 {
+		return fmt.Sprintf("image %s blocked by image policy: %s", e.Image, e.Rule)
+	}
+
+	return fmt.Sprintf("image %s blocked by image policy: %s %s", e.Image, e.Rule, e.Pattern)
+}
+
+// Empty returns true if the provided Policy has no rules.
+func (p *Policy) Empty() bool {
+	// check if the policy is nil
+	if p == nil {
+		return true
+	}
+
+	return len(p.AllowedImages) == 0 &&
+		len(p.DeniedImages) == 0 &&
+		len(p.AllowedRegistries) == 0 &&
+		len(p.DeniedRegistries) == 0
+}
+
+// Validate verifies the provided image is permitted to run
+// by the Policy. If the image is blocked, it will return
+// a PolicyError containing the rule that blocked the image.
+func (p *Policy) Validate(_image string) error {
+	// check if the policy has any rules
+	if p.Empty() {
+		return nil
+	}
+
+	// parse the image provided into a
+	// named, fully qualified reference
+	//
+	// https://pkg.go.dev/github.com/docker/distribution/reference?tab=doc#ParseAnyReference
+	_reference, err := reference.ParseAnyReference(_image)
+	if err != nil {
+		return &PolicyError{Image: _image, Rule: RuleInvalidImage}
+	}
+
+	// ensure we have the canonical form of the named reference
+	//
+	// https://pkg.go.dev/github.com/docker/distribution/reference?tab=doc#ParseNamed
+	_canonical, err := reference.ParseNamed(_reference.String())
+	if err != nil {
+		return &PolicyError{Image: _image, Rule: RuleInvalidImage}
+	}
+
+	// capture the registry hosting the image
+	//
+	// https://pkg.go.dev/github.com/docker/distribution/reference?tab=doc#Domain
+	registry := reference.Domain(_canonical)
+
+	// check if the image is hosted in a denied registry
+	for _, pattern := range p.DeniedRegistries {
+		if matchRegistry(registry, pattern) {
+			return &PolicyError{Image: _image, Rule: RuleDeniedRegistry, Pattern: pattern}
+		}
+	}
+
+	// add default tag "latest" when tag does not exist
+	_tagged := reference.TagNameOnly(_canonical)
+
+	// check if the image matches a denied pattern
+	for _, pattern := range p.DeniedImages {
+		// https://pkg.go.dev/github.com/docker/distribution/reference#FamiliarMatch
+		match, err := reference.FamiliarMatch(pattern, _tagged)
+		if err != nil {
+			return err
+		}
+
+		if match {
+			return &PolicyError{Image: _image, Rule: RuleDeniedImage, Pattern: pattern}
+		}
+	}
+
+	// check if any allow rules were provided
+	if len(p.AllowedImages) == 0 && len(p.AllowedRegistries) == 0 {
+		return nil
+	}
+
+	// check if the image is hosted in an allowed registry
+	for _, pattern := range p.AllowedRegistries {
+		if matchRegistry(registry, pattern) {
+			return nil
+		}
+	}
+
+	// check if the image matches an allowed pattern
+	for _, pattern := range p.AllowedImages {
+		// https://pkg.go.dev/github.com/docker/distribution/reference#FamiliarMatch
+		match, err := reference.FamiliarMatch(pattern, _tagged)
+		if err != nil {
+			return err
+		}
+
+		if match {
+			return nil
+		}
+	}
+
+	return &PolicyError{Image: _image, Rule: RuleNotAllowed}
+}
+
+// matchRegistry is a helper function to check if the
+// registry matches the provided pattern. The pattern
+// supports shell globbing, i.e. "*.company.com".
+func matchRegistry(registry, pattern string) bool {
+	// https://pkg.go.dev/path#Match
+	match, err := path.Match(normalizeRegistry(pattern), strings.ToLower(registry))
+	if err != nil {
+		return false
+	}
+
+	return match
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package image
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestImage_Policy_Validate(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name    string
+		policy  *Policy
+		image   string
+		failure bool
+		rule    string
+	}{
+		{
+			name:   "nil policy",
+			policy: nil,
+			image:  "alpine",
+		},
+		{
+			name:   "empty policy",
+			policy: new(Policy),
+			image:  "alpine",
+		},
+		{
+			name:    "denied registry",
+			policy:  &Policy{DeniedRegistries: []string{"docker.io"}},
+			image:   "alpine:latest",
+			failure: true,
+			rule:    RuleDeniedRegistry,
+		},
+		{
+			name:    "denied legacy registry",
+			policy:  &Policy{DeniedRegistries: []string{"index.docker.io"}},
+			image:   "index.docker.io/library/alpine:latest",
+			failure: true,
+			rule:    RuleDeniedRegistry,
+		},
+		{
+			name:    "denied registry with glob",
+			policy:  &Policy{DeniedRegistries: []string{"*.company.com"}},
+			image:   "docker.company.com/foo/bar:v0.1.0",
+			failure: true,
+			rule:    RuleDeniedRegistry,
+		},
+		{
+			name:    "denied image",
+			policy:  &Policy{DeniedImages: []string{"alpine"}},
+			image:   "alpine:3.14",
+			failure: true,
+			rule:    RuleDeniedImage,
+		},
+		{
+			name:   "denied image on different tag",
+			policy: &Policy{DeniedImages: []string{"alpine:3.13"}},
+			image:  "alpine:3.14",
+		},
+		{
+			name:   "allowed image",
+			policy: &Policy{AllowedImages: []string{"target/vela-*"}},
+			image:  "target/vela-git:v0.4.0",
+		},
+		{
+			name:   "allowed registry",
+			policy: &Policy{AllowedRegistries: []string{"docker.company.com"}},
+			image:  "docker.company.com/foo/bar",
+		},
+		{
+			name:    "not allowed",
+			policy:  &Policy{AllowedImages: []string{"target/vela-*"}, AllowedRegistries: []string{"gcr.io"}},
+			image:   "alpine:latest",
+			failure: true,
+			rule:    RuleNotAllowed,
+		},
+		{
+			name: "deny takes precedence over allow",
+			policy: &Policy{
+				AllowedRegistries: []string{"docker.io"},
+				DeniedImages:      []string{"target/vela-docker"},
+			},
+			image:   "target/vela-docker:latest",
+			failure: true,
+			rule:    RuleDeniedImage,
+		},
+		{
+			name:    "invalid image",
+			policy:  &Policy{AllowedImages: []string{"alpine"}},
+			image:   "!@#$%^&*()",
+			failure: true,
+			rule:    RuleInvalidImage,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.policy.Validate(test.image)
+
+			if test.failure {
+				if err == nil {
+					t.Errorf("Validate should have returned err")
+				}
+
+				var policyErr *PolicyError
+				if !errors.As(err, &policyErr) {
+					t.Errorf("Validate returned err %v, want PolicyError", err)
+
+					return
+				}
+
+				if policyErr.Rule != test.rule {
+					t.Errorf("Validate rule is %s want %s", policyErr.Rule, test.rule)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Validate returned err: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+// Package logs provides the ability for Vela to capture
+// structured log records, with the stream and timestamp
+// for each line, from the output of a container.
+//
+// Usage:
+//
+// 	import "github.com/go-vela/pkg-runtime/internal/logs"
+package logs
@@ -0,0 +1,414 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package logs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+const (
+	// LimitBytes represents the limit on the
+	// number of bytes of output for a container.
+	LimitBytes = "bytes"
+	// LimitLines represents the limit on the
+	// number of lines of output for a container.
+	LimitLines = "lines"
+
+	// maxTailLineSize represents the maximum size in bytes
+	// of each line retained from the end of the output.
+	maxTailLineSize = 64 * 1024
+
+	// limitChunkSize represents the size in bytes of
+	// each read from the underlying container output.
+	limitChunkSize = 32 * 1024
+)
+
+// Limit represents the configuration for limiting
+// the size of the output from a container.
+type Limit struct {
+	// specifies the maximum number of bytes of output
+	Bytes int64
+	// specifies the maximum number of lines of output
+	Lines int64
+	// specifies the number of lines retained from the end
+	// of the output after the limit has been exceeded
+	Tail int
+	// specifies to kill the container when the limit is exceeded
+	Kill bool
+}
+
+// Enabled returns true if any limits are
+// configured for the output from a container.
+func (l *Limit) Enabled() bool {
+	return l != nil && (l.Bytes > 0 || l.Lines > 0)
+}
+
+// LimitError represents the error returned once all
+// output has been read from a container that exceeded
+// the configured limit for the output.
+type LimitError struct {
+	// limit that was exceeded (bytes or lines)
+	Limit string
+	// value configured for the limit
+	Value int64
+}
+
+// Error implements the error interface for the LimitError.
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("log limit exceeded: output exceeded %d %s", e.Value, e.Limit)
+}
+
+// Marker returns the line written in place of
+// the output truncated from the container.
+func (e *LimitError) Marker(tail int) string {
+	// check if lines are retained from the end of the output
+	if tail > 0 {
+		return fmt.Sprintf("--- log limit of %d %s exceeded: output truncated, showing the last %d lines ---", e.Value, e.Limit, tail)
+	}
+
+	return fmt.Sprintf("--- log limit of %d %s exceeded: output truncated ---", e.Value, e.Limit)
+}
+
+// limitReader represents an io.ReadCloser that
+// limits the size of the output from a container.
+type limitReader struct {
+	output     io.ReadCloser
+	limit      Limit
+	onExceeded func(*LimitError)
+	chunk      []byte
+
+	bytes int64
+	lines int64
+	// last byte of the output within the limit
+	last byte
+
+	// limit that was exceeded by the output
+	exceeded *LimitError
+	// lines retained from the end of the truncated output
+	tail [][]byte
+	// line from the truncated output without a trailing newline
+	partial []byte
+
+	// limited output not yet returned to the caller
+	limited bytes.Buffer
+	err     error
+}
+
+// NewLimitReader creates an io.ReadCloser that limits the
+// size of the output from a container. The provided function
+// is called once when the limit is exceeded, and a LimitError
+// is returned in place of io.EOF after all output is read.
+func NewLimitReader(output io.ReadCloser, limit *Limit, onExceeded func(*LimitError)) io.ReadCloser {
+	return &limitReader{
+		output:     output,
+		limit:      *limit,
+		onExceeded: onExceeded,
+		chunk:      make([]byte, limitChunkSize),
+	}
+}
+
+// Read reads the limited output from the container.
+func (r *limitReader) Read(p []byte) (int, error) {
+	for r.limited.Len() == 0 {
+		// check if the container output has been exhausted
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		n, err := r.output.Read(r.chunk)
+
+		r.capture(r.chunk[:n])
+
+		// check if the container output has been exhausted
+		if err != nil {
+			r.finish(err)
+		}
+	}
+
+	return r.limited.Read(p)
+}
+
+// Close closes the underlying container output.
+func (r *limitReader) Close() error {
+	return r.output.Close()
+}
+
+// capture is a helper function to write the output
+// within the limit and retain the truncated output.
+func (r *limitReader) capture(output []byte) {
+	// check if the limit has already been exceeded
+	if r.exceeded != nil {
+		r.retain(output)
+
+		return
+	}
+
+	cut := len(output)
+
+	// check if the output exceeds the limit on bytes
+	if r.limit.Bytes > 0 && r.bytes+int64(cut) > r.limit.Bytes {
+		cut = int(r.limit.Bytes - r.bytes)
+		r.exceeded = &LimitError{Limit: LimitBytes, Value: r.limit.Bytes}
+	}
+
+	// check if the output exceeds the limit on lines
+	if r.limit.Lines > 0 {
+		lines := r.lines
+
+		for i := 0; i < cut; i++ {
+			// check if the line starts after the limit
+			if lines >= r.limit.Lines {
+				cut = i
+				r.exceeded = &LimitError{Limit: LimitLines, Value: r.limit.Lines}
+
+				break
+			}
+
+			if output[i] == '\n' {
+				lines++
+			}
+		}
+
+		r.lines = lines
+	}
+
+	r.bytes += int64(cut)
+	r.limited.Write(output[:cut])
+
+	if cut > 0 {
+		r.last = output[cut-1]
+	}
+
+	// check if the output was truncated
+	if r.exceeded == nil {
+		return
+	}
+
+	// check if the truncation is in the middle of a line
+	if r.bytes > 0 && r.last != '\n' {
+		r.limited.WriteByte('\n')
+	}
+
+	r.limited.WriteString(r.exceeded.Marker(r.limit.Tail) + "\n")
+
+	// notify the limit was exceeded
+	if r.onExceeded != nil {
+		r.onExceeded(r.exceeded)
+	}
+
+	r.retain(output[cut:])
+}
+
+// retain is a helper function to retain the configured
+// number of lines from the end of the truncated output.
+func (r *limitReader) retain(output []byte) {
+	// check if lines are retained from the end of the output
+	if r.limit.Tail <= 0 {
+		return
+	}
+
+	for len(output) > 0 {
+		i := bytes.IndexByte(output, '\n')
+		if i < 0 {
+			r.partial = appendLine(r.partial, output)
+
+			return
+		}
+
+		r.push(appendLine(r.partial, output[:i+1]))
+
+		r.partial = nil
+		output = output[i+1:]
+	}
+}
+
+// push is a helper function to add a line to the lines
+// retained from the end of the truncated output.
+func (r *limitReader) push(line []byte) {
+	r.tail = append(r.tail, line)
+
+	// check if too many lines are retained
+	if len(r.tail) > r.limit.Tail {
+		r.tail = r.tail[1:]
+	}
+}
+
+// finish is a helper function to write the lines retained
+// from the end of the truncated output and capture the
+// error returned after all output is read.
+func (r *limitReader) finish(err error) {
+	// check if the output ended without a trailing newline
+	if len(r.partial) > 0 {
+		r.push(append(r.partial, '\n'))
+
+		r.partial = nil
+	}
+
+	for _, line := range r.tail {
+		r.limited.Write(line)
+	}
+
+	r.tail = nil
+	r.err = err
+
+	// check if the output was truncated
+	if err == io.EOF && r.exceeded != nil {
+		r.err = r.exceeded
+	}
+}
+
+// appendLine is a helper function to append output
+// to a line up to the maximum size of a line.
+func appendLine(line, output []byte) []byte {
+	// check if the line has space for the output
+	if len(line)+len(output) <= maxTailLineSize {
+		return append(line, output...)
+	}
+
+	room := maxTailLineSize - len(line)
+	if room <= 0 {
+		return line
+	}
+
+	return append(line, output[:room]...)
+}
+
+// limitRecords represents a Reader that limits
+// the size of the log records from a container.
+type limitRecords struct {
+	records    Reader
+	limit      Limit
+	onExceeded func(*LimitError)
+
+	bytes int64
+	lines int64
+
+	// limit that was exceeded by the log records
+	exceeded *LimitError
+	// log records retained from the end of the truncated output
+	tail []*Record
+	// log records not yet returned to the caller
+	pending []*Record
+	err     error
+}
+
+// NewLimitRecords creates a Reader that limits the size of
+// the log records from a container. The provided function
+// is called once when the limit is exceeded, and a LimitError
+// is returned in place of io.EOF after all records are read.
+func NewLimitRecords(records Reader, limit *Limit, onExceeded func(*LimitError)) Reader {
+	return &limitRecords{
+		records:    records,
+		limit:      *limit,
+		onExceeded: onExceeded,
+	}
+}
+
+// Next returns the next log record from the container.
+func (r *limitRecords) Next() (*Record, error) {
+	for len(r.pending) == 0 {
+		// check if the log records have been exhausted
+		if r.err != nil {
+			return nil, r.err
+		}
+
+		record, err := r.records.Next()
+		if err != nil {
+			r.finish(err)
+
+			continue
+		}
+
+		r.capture(record)
+	}
+
+	record := r.pending[0]
+	r.pending = r.pending[1:]
+
+	return record, nil
+}
+
+// Close closes the underlying log records.
+func (r *limitRecords) Close() error {
+	return r.records.Close()
+}
+
+// capture is a helper function to return the log record
+// within the limit or retain the truncated log record.
+func (r *limitRecords) capture(record *Record) {
+	// check if the limit has already been exceeded
+	if r.exceeded != nil {
+		r.retain(record)
+
+		return
+	}
+
+	// count the bytes for the line and trailing newline
+	size := int64(len(record.Line)) + 1
+
+	switch {
+	case r.limit.Bytes > 0 && r.bytes+size > r.limit.Bytes:
+		r.exceeded = &LimitError{Limit: LimitBytes, Value: r.limit.Bytes}
+	case r.limit.Lines > 0 && r.lines >= r.limit.Lines:
+		r.exceeded = &LimitError{Limit: LimitLines, Value: r.limit.Lines}
+	default:
+		r.bytes += size
+		r.lines++
+		r.pending = append(r.pending, record)
+
+		return
+	}
+
+	r.pending = append(r.pending, &Record{
+		Stream:    StreamStdout,
+		Timestamp: record.Timestamp,
+		Line:      r.exceeded.Marker(r.limit.Tail),
+	})
+
+	// notify the limit was exceeded
+	if r.onExceeded != nil {
+		r.onExceeded(r.exceeded)
+	}
+
+	r.retain(record)
+}
+
+// retain is a helper function to retain the configured number
+// of log records from the end of the truncated output.
+func (r *limitRecords) retain(record *Record) {
+	// check if log records are retained from the end of the output
+	if r.limit.Tail <= 0 {
+		return
+	}
+
+	// check if the line is too large to retain
+	if len(record.Line) > maxTailLineSize {
+		record.Line = record.Line[:maxTailLineSize]
+	}
+
+	r.tail = append(r.tail, record)
+
+	// check if too many log records are retained
+	if len(r.tail) > r.limit.Tail {
+		r.tail = r.tail[1:]
+	}
+}
+
+// finish is a helper function to return the log records
+// retained from the end of the truncated output and capture
+// the error returned after all log records are read.
+func (r *limitRecords) finish(err error) {
+	r.pending = append(r.pending, r.tail...)
+
+	r.tail = nil
+	r.err = err
+
+	// check if the output was truncated
+	if err == io.EOF && r.exceeded != nil {
+		r.err = r.exceeded
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package logs
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLogs_Limit_Enabled(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		limit *Limit
+		want  bool
+	}{
+		{
+			limit: &Limit{Bytes: 1024},
+			want:  true,
+		},
+		{
+			limit: &Limit{Lines: 10},
+			want:  true,
+		},
+		{
+			limit: &Limit{Tail: 10, Kill: true},
+			want:  false,
+		},
+		{
+			limit: nil,
+			want:  false,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got := test.limit.Enabled()
+
+		if got != test.want {
+			t.Errorf("Enabled for %v is %v, want %v", test.limit, got, test.want)
+		}
+	}
+}
+
+func TestLogs_NewLimitReader(t *testing.T) {
+	// setup types
+	output := "one\ntwo\nthree\nfour\nfive\nsix"
+
+	// setup tests
+	tests := []struct {
+		name     string
+		limit    *Limit
+		want     string
+		exceeded *LimitError
+	}{
+		{
+			name:  "within limit",
+			limit: &Limit{Bytes: 1024, Lines: 10},
+			want:  output,
+		},
+		{
+			name:  "exact limit",
+			limit: &Limit{Bytes: int64(len(output))},
+			want:  output,
+		},
+		{
+			name:     "bytes",
+			limit:    &Limit{Bytes: 10},
+			want:     "one\ntwo\nth\n--- log limit of 10 bytes exceeded: output truncated ---\n",
+			exceeded: &LimitError{Limit: LimitBytes, Value: 10},
+		},
+		{
+			name:     "lines",
+			limit:    &Limit{Lines: 2},
+			want:     "one\ntwo\n--- log limit of 2 lines exceeded: output truncated ---\n",
+			exceeded: &LimitError{Limit: LimitLines, Value: 2},
+		},
+		{
+			name:     "lines before bytes",
+			limit:    &Limit{Bytes: 12, Lines: 2},
+			want:     "one\ntwo\n--- log limit of 2 lines exceeded: output truncated ---\n",
+			exceeded: &LimitError{Limit: LimitLines, Value: 2},
+		},
+		{
+			name:     "head and tail",
+			limit:    &Limit{Lines: 2, Tail: 2},
+			want:     "one\ntwo\n--- log limit of 2 lines exceeded: output truncated, showing the last 2 lines ---\nfive\nsix\n",
+			exceeded: &LimitError{Limit: LimitLines, Value: 2},
+		},
+		{
+			name:     "tail larger than truncated output",
+			limit:    &Limit{Lines: 4, Tail: 10},
+			want:     "one\ntwo\nthree\nfour\n--- log limit of 4 lines exceeded: output truncated, showing the last 10 lines ---\nfive\nsix\n",
+			exceeded: &LimitError{Limit: LimitLines, Value: 4},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		// read the output in every possible chunk size
+		for size := 1; size <= len(output); size++ {
+			calls := 0
+
+			r := NewLimitReader(
+				&chunkReader{output: output, size: size},
+				test.limit,
+				func(*LimitError) { calls++ },
+			)
+
+			got, err := ioutil.ReadAll(r)
+
+			if test.exceeded == nil {
+				if err != nil {
+					t.Errorf("NewLimitReader for %s returned err: %v", test.name, err)
+				}
+			} else {
+				var limitErr *LimitError
+				if !errors.As(err, &limitErr) || !reflect.DeepEqual(limitErr, test.exceeded) {
+					t.Errorf("NewLimitReader for %s returned err %v, want %v", test.name, err, test.exceeded)
+				}
+			}
+
+			if string(got) != test.want {
+				t.Errorf("NewLimitReader for %s with chunk size %d is %q, want %q", test.name, size, got, test.want)
+			}
+
+			if test.exceeded != nil && calls != 1 {
+				t.Errorf("NewLimitReader for %s notified %d times, want 1", test.name, calls)
+			}
+		}
+	}
+}
+
+func TestLogs_NewLimitRecords(t *testing.T) {
+	// setup types
+	output := "one\ntwo\nthree\nfour\nfive\nsix\n"
+
+	lines := func(records []*Record) []string {
+		got := []string{}
+
+		for _, record := range records {
+			got = append(got, record.Line)
+		}
+
+		return got
+	}
+
+	// setup tests
+	tests := []struct {
+		name     string
+		limit    *Limit
+		want     []string
+		exceeded *LimitError
+	}{
+		{
+			name:  "within limit",
+			limit: &Limit{Lines: 10},
+			want:  []string{"one", "two", "three", "four", "five", "six"},
+		},
+		{
+			name:     "bytes",
+			limit:    &Limit{Bytes: 10},
+			want:     []string{"one", "two", "--- log limit of 10 bytes exceeded: output truncated ---"},
+			exceeded: &LimitError{Limit: LimitBytes, Value: 10},
+		},
+		{
+			name:     "head and tail",
+			limit:    &Limit{Lines: 2, Tail: 1},
+			want:     []string{"one", "two", "--- log limit of 2 lines exceeded: output truncated, showing the last 1 lines ---", "six"},
+			exceeded: &LimitError{Limit: LimitLines, Value: 2},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		calls := 0
+
+		r := NewLimitRecords(
+			NewReader(ioutil.NopCloser(strings.NewReader(output)), StreamStdout),
+			test.limit,
+			func(*LimitError) { calls++ },
+		)
+
+		got := []*Record{}
+
+		var err error
+
+		for {
+			var record *Record
+
+			record, err = r.Next()
+			if err != nil {
+				break
+			}
+
+			got = append(got, record)
+		}
+
+		if test.exceeded == nil && err != io.EOF {
+			t.Errorf("NewLimitRecords for %s returned err: %v", test.name, err)
+		}
+
+		if test.exceeded != nil {
+			var limitErr *LimitError
+			if !errors.As(err, &limitErr) || !reflect.DeepEqual(limitErr, test.exceeded) {
+				t.Errorf("NewLimitRecords for %s returned err %v, want %v", test.name, err, test.exceeded)
+			}
+
+			if calls != 1 {
+				t.Errorf("NewLimitRecords for %s notified %d times, want 1", test.name, calls)
+			}
+		}
+
+		if !reflect.DeepEqual(lines(got), test.want) {
+			t.Errorf("NewLimitRecords for %s is %v, want %v", test.name, lines(got), test.want)
+		}
+	}
+}
+
+// chunkReader represents an io.ReadCloser returning
+// the output in chunks of a fixed size.
+type chunkReader struct {
+	output string
+	size   int
+}
+
+// Read returns the next chunk of the output.
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if len(r.output) == 0 {
+		return 0, io.EOF
+	}
+
+	n := r.size
+	if n > len(r.output) {
+		n = len(r.output)
+	}
+
+	if n > len(p) {
+		n = len(p)
+	}
+
+	copy(p, r.output[:n])
+	r.output = r.output[n:]
+
+	return n, nil
+}
+
+// Close is a no-op for the chunkReader.
+func (r *chunkReader) Close() error {
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package logs
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// StreamStdout represents the stream for
+	// standard output from a container.
+	StreamStdout = "stdout"
+	// StreamStderr represents the stream for
+	// standard error from a container.
+	StreamStderr = "stderr"
+)
+
+// Record represents a single line of
+// output captured from a container.
+type Record struct {
+	// stream the line was written to (stdout or stderr)
+	Stream string
+	// time the line was written by the container
+	Timestamp time.Time
+	// content of the line without the trailing newline
+	Line string
+}
+
+// Reader represents a stream of log
+// records captured from a container.
+type Reader interface {
+	// Next returns the next log record from the container.
+	// It returns io.EOF when no more records are available.
+	Next() (*Record, error)
+	// Close closes the underlying stream of output.
+	Close() error
+}
+
+// ParseLine digests the provided line of output, prefixed
+// with an RFC 3339 timestamp, into a log record for the
+// stream. If the line has no timestamp, the record will
+// have a zero timestamp and contain the entire line.
+func ParseLine(stream, line string) *Record {
+	// split the timestamp from the line
+	timestamp, line := SplitTimestamp(line)
+
+	return &Record{
+		Stream:    stream,
+		Timestamp: timestamp,
+		Line:      strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"),
+	}
+}
+
+// SplitTimestamp splits the RFC 3339 timestamp prefix from
+// the provided output. If the output has no timestamp, it
+// will return a zero timestamp and the entire output.
+func SplitTimestamp(output string) (time.Time, string) {
+	parts := strings.SplitN(output, " ", 2)
+
+	// parse the timestamp for the output
+	//
+	// https://pkg.go.dev/time#Parse
+	timestamp, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(parts[0]))
+	if err != nil {
+		return time.Time{}, output
+	}
+
+	// check if the output has content after the timestamp
+	if len(parts) == 1 {
+		return timestamp, ""
+	}
+
+	return timestamp, parts[1]
+}
+
+// reader represents a Reader capturing log
+// records from a single stream of output.
+type reader struct {
+	closer io.Closer
+	reader *bufio.Reader
+	stream string
+}
+
+// NewReader creates a Reader capturing log records, with
+// RFC 3339 timestamps, from a single stream of output.
+func NewReader(rc io.ReadCloser, stream string) Reader {
+	return &reader{
+		closer: rc,
+		reader: bufio.NewReader(rc),
+		stream: stream,
+	}
+}
+
+// Next returns the next log record from the stream.
+func (r *reader) Next() (*Record, error) {
+	// read the next line from the stream
+	line, err := r.reader.ReadString('\n')
+	if len(line) > 0 {
+		return ParseLine(r.stream, line), nil
+	}
+
+	return nil, err
+}
+
+// Close closes the underlying stream of output.
+func (r *reader) Close() error {
+	return r.closer.Close()
+}
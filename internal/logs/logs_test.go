@@ -0,0 +1,104 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package logs
+
+import (
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogs_ParseLine(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		stream string
+		line   string
+		want   *Record
+	}{
+		{
+			stream: StreamStdout,
+			line:   "2021-10-19T01:29:03.123456789Z hello world\n",
+			want: &Record{
+				Stream:    StreamStdout,
+				Timestamp: time.Date(2021, 10, 19, 1, 29, 3, 123456789, time.UTC),
+				Line:      "hello world",
+			},
+		},
+		{
+			stream: StreamStderr,
+			line:   "2021-10-19T01:29:03Z \r\n",
+			want: &Record{
+				Stream:    StreamStderr,
+				Timestamp: time.Date(2021, 10, 19, 1, 29, 3, 0, time.UTC),
+				Line:      "",
+			},
+		},
+		{
+			stream: StreamStdout,
+			line:   "2021-10-19T01:29:03Z",
+			want: &Record{
+				Stream:    StreamStdout,
+				Timestamp: time.Date(2021, 10, 19, 1, 29, 3, 0, time.UTC),
+				Line:      "",
+			},
+		},
+		{
+			stream: StreamStdout,
+			line:   "hello world\n",
+			want: &Record{
+				Stream: StreamStdout,
+				Line:   "hello world",
+			},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got := ParseLine(test.stream, test.line)
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParseLine for %q is %+v, want %+v", test.line, got, test.want)
+		}
+	}
+}
+
+func TestLogs_NewReader(t *testing.T) {
+	// setup types
+	output := "2021-10-19T01:29:03Z hello\n2021-10-19T01:29:04Z world\n2021-10-19T01:29:05Z partial"
+
+	want := []*Record{
+		{Stream: StreamStdout, Timestamp: time.Date(2021, 10, 19, 1, 29, 3, 0, time.UTC), Line: "hello"},
+		{Stream: StreamStdout, Timestamp: time.Date(2021, 10, 19, 1, 29, 4, 0, time.UTC), Line: "world"},
+		{Stream: StreamStdout, Timestamp: time.Date(2021, 10, 19, 1, 29, 5, 0, time.UTC), Line: "partial"},
+	}
+
+	// run test
+	r := NewReader(ioutil.NopCloser(strings.NewReader(output)), StreamStdout)
+	defer r.Close()
+
+	got := []*Record{}
+
+	for {
+		record, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Errorf("Next returned err: %v", err)
+
+			break
+		}
+
+		got = append(got, record)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewReader is %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,12 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+// Package network provides the ability for Vela to manage
+// and manipulate the settings for a network provided
+// for a build.
+//
+// Usage:
+//
+// 	import "github.com/go-vela/pkg-runtime/internal/network"
+package network
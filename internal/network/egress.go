@@ -0,0 +1,87 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package network
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// EgressRule represents a destination the
+// containers for a build are allowed to reach.
+type EgressRule struct {
+	// CIDR is the range of addresses allowed
+	CIDR *net.IPNet
+	// Port is the port allowed, or zero for all ports
+	Port int
+	// Protocol is the protocol (TCP, UDP or SCTP) for the port
+	Protocol string
+}
+
+// ParseEgress digests the provided list of egress rules, in
+// the form <cidr>[=<port>[/<protocol>]], into the rules for the
+// destinations containers are allowed to reach. When no protocol
+// is provided for the port, TCP is used.
+func ParseEgress(rules []string) ([]*EgressRule, error) {
+	r := []*EgressRule{}
+
+	// iterate through all rules provided
+	for _, rule := range rules {
+		// split the rule into the cidr and port parts
+		parts := strings.SplitN(strings.TrimSpace(rule), "=", 2)
+
+		// https://pkg.go.dev/net#ParseCIDR
+		_, cidr, err := net.ParseCIDR(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid egress rule provided: %s", rule)
+		}
+
+		_rule := &EgressRule{CIDR: cidr}
+
+		// nolint: gomnd // ignore magic number
+		//
+		// check if a port is provided for the rule
+		if len(parts) == 2 {
+			// split the port into the port and protocol parts
+			port := strings.SplitN(parts[1], "/", 2)
+
+			_rule.Port, err = strconv.Atoi(port[0])
+			if err != nil || _rule.Port < 1 || _rule.Port > 65535 {
+				return nil, fmt.Errorf("invalid egress rule provided: %s (invalid port %s)", rule, port[0])
+			}
+
+			_rule.Protocol = "TCP"
+
+			// nolint: gomnd // ignore magic number
+			//
+			// check if a protocol is provided for the port
+			if len(port) == 2 {
+				_rule.Protocol = strings.ToUpper(port[1])
+			}
+
+			switch _rule.Protocol {
+			case "TCP", "UDP", "SCTP":
+			default:
+				return nil, fmt.Errorf("invalid egress rule provided: %s (invalid protocol %s)", rule, port[1])
+			}
+		}
+
+		r = append(r, _rule)
+	}
+
+	return r, nil
+}
+
+// String returns the rule in the form <cidr>[=<port>/<protocol>].
+func (r *EgressRule) String() string {
+	// check if a port is provided for the rule
+	if r.Port == 0 {
+		return r.CIDR.String()
+	}
+
+	return fmt.Sprintf("%s=%d/%s", r.CIDR.String(), r.Port, r.Protocol)
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package network
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNetwork_ParseEgress(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		rules   []string
+		want    []string
+	}{
+		{
+			failure: false,
+			rules:   []string{"10.0.0.0/8", "192.168.1.10/32=443", "fd00::/8=53/udp"},
+			want:    []string{"10.0.0.0/8", "192.168.1.10/32=443/TCP", "fd00::/8=53/UDP"},
+		},
+		{
+			failure: false,
+			rules:   []string{},
+			want:    []string{},
+		},
+		{
+			failure: true,
+			rules:   []string{"10.0.0.1"},
+		},
+		{
+			failure: true,
+			rules:   []string{"10.0.0.0/8=https"},
+		},
+		{
+			failure: true,
+			rules:   []string{"10.0.0.0/8=70000"},
+		},
+		{
+			failure: true,
+			rules:   []string{"10.0.0.0/8=443/icmp"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got, err := ParseEgress(test.rules)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("ParseEgress for %v should have returned err", test.rules)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ParseEgress for %v returned err: %v", test.rules, err)
+		}
+
+		rules := []string{}
+		for _, rule := range got {
+			rules = append(rules, rule.String())
+		}
+
+		if !reflect.DeepEqual(rules, test.want) {
+			t.Errorf("ParseEgress is %v, want %v", rules, test.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package network
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// HostGateway represents the special address that
+// the daemon resolves to the address of the host.
+const HostGateway = "host-gateway"
+
+// ValidateDNS verifies the provided list of
+// DNS servers are all valid addresses.
+func ValidateDNS(servers []string) error {
+	// iterate through all servers provided
+	for _, server := range servers {
+		// https://pkg.go.dev/net#ParseIP
+		if net.ParseIP(server) == nil {
+			return fmt.Errorf("invalid dns server provided: %s", server)
+		}
+	}
+
+	return nil
+}
+
+// ValidateExtraHosts verifies the provided list of extra
+// hosts, in the form <host>:<ip>, are all valid entries.
+func ValidateExtraHosts(hosts []string) error {
+	// iterate through all hosts provided
+	for _, host := range hosts {
+		// split the host into the name and address parts
+		//
+		// The address is split on the first colon,
+		// because an IPv6 address contains colons.
+		parts := strings.SplitN(host, ":", 2)
+
+		// nolint: gomnd // ignore magic number
+		if len(parts) != 2 || len(strings.TrimSpace(parts[0])) == 0 {
+			return fmt.Errorf("invalid extra host provided: %s", host)
+		}
+
+		// check if the address is valid
+		if parts[1] != HostGateway && net.ParseIP(parts[1]) == nil {
+			return fmt.Errorf("invalid extra host provided: %s (invalid address %s)", host, parts[1])
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package network
+
+import (
+	"testing"
+)
+
+func TestNetwork_ValidateDNS(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		servers []string
+	}{
+		{
+			failure: false,
+			servers: []string{"1.1.1.1", "2606:4700:4700::1111"},
+		},
+		{
+			failure: false,
+			servers: []string{},
+		},
+		{
+			failure: true,
+			servers: []string{"dns.company.com"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		err := ValidateDNS(test.servers)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("ValidateDNS for %v should have returned err", test.servers)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ValidateDNS for %v returned err: %v", test.servers, err)
+		}
+	}
+}
+
+func TestNetwork_ValidateExtraHosts(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		hosts   []string
+	}{
+		{
+			failure: false,
+			hosts:   []string{"registry.company.com:10.0.0.5", "ipv6.company.com:fd00::5", "host.docker.internal:host-gateway"},
+		},
+		{
+			failure: false,
+			hosts:   []string{},
+		},
+		{
+			failure: true,
+			hosts:   []string{"registry.company.com"},
+		},
+		{
+			failure: true,
+			hosts:   []string{":10.0.0.5"},
+		},
+		{
+			failure: true,
+			hosts:   []string{"registry.company.com:foo"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		err := ValidateExtraHosts(test.hosts)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("ValidateExtraHosts for %v should have returned err", test.hosts)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ValidateExtraHosts for %v returned err: %v", test.hosts, err)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package network
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultIPv4Size represents the default prefix
+	// length for subnets from an IPv4 pool.
+	DefaultIPv4Size = 24
+	// DefaultIPv6Size represents the default prefix
+	// length for subnets from an IPv6 pool.
+	DefaultIPv6Size = 64
+)
+
+// Pool represents a range of addresses
+// subnets for networks are allocated from.
+type Pool struct {
+	// Base is the range of addresses for the pool
+	Base *net.IPNet
+	// Size is the prefix length for subnets from the pool
+	Size int
+}
+
+// ParsePools digests the provided list of subnet pools, in
+// the form <cidr>[=<size>], into the pools subnets are
+// allocated from. When no size is provided, the subnets
+// from the pool use the default size for the address family.
+func ParsePools(pools []string) ([]*Pool, error) {
+	p := []*Pool{}
+
+	// iterate through all pools provided
+	for _, pool := range pools {
+		// split the pool into the cidr and size parts
+		parts := strings.SplitN(strings.TrimSpace(pool), "=", 2)
+
+		// https://pkg.go.dev/net#ParseCIDR
+		_, base, err := net.ParseCIDR(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid subnet pool provided: %s", pool)
+		}
+
+		ones, bits := base.Mask.Size()
+
+		// use the default size for the address family
+		size := DefaultIPv4Size
+		if base.IP.To4() == nil {
+			size = DefaultIPv6Size
+		}
+
+		// use the pool as a single subnet when it
+		// is smaller than the default size
+		if ones > size {
+			size = ones
+		}
+
+		// nolint: gomnd // ignore magic number
+		//
+		// check if a size is provided for the pool
+		if len(parts) == 2 {
+			size, err = strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(parts[1]), "/"))
+			if err != nil {
+				return nil, fmt.Errorf("invalid subnet pool provided: %s", pool)
+			}
+		}
+
+		// check if the size is within the range for the pool
+		if size < ones || size > bits {
+			return nil, fmt.Errorf("invalid subnet pool provided: %s (size must be between %d and %d)", pool, ones, bits)
+		}
+
+		p = append(p, &Pool{Base: base, Size: size})
+	}
+
+	return p, nil
+}
+
+// IPv6 returns true when the pool contains IPv6 addresses.
+func (p *Pool) IPv6() bool {
+	return p.Base.IP.To4() == nil
+}
+
+// String returns the pool in the form <cidr>=<size>.
+func (p *Pool) String() string {
+	return fmt.Sprintf("%s=%d", p.Base.String(), p.Size)
+}
+
+// Next returns the first subnet from the pool that
+// doesn't overlap with any of the used subnets.
+func (p *Pool) Next(used []*net.IPNet) (*net.IPNet, bool) {
+	ones, bits := p.Base.Mask.Size()
+
+	// capture the range of addresses for the pool
+	start := toInt(p.Base.IP)
+	end := new(big.Int).Add(start, new(big.Int).Lsh(big.NewInt(1), uint(bits-ones)))
+
+	// capture the number of addresses for each subnet
+	step := new(big.Int).Lsh(big.NewInt(1), uint(bits-p.Size))
+	mask := net.CIDRMask(p.Size, bits)
+
+	for n := start; n.Cmp(end) < 0; {
+		subnet := &net.IPNet{IP: toIP(n, bits), Mask: mask}
+
+		free := true
+		next := new(big.Int).Add(n, step)
+
+		// iterate through all used subnets
+		for _, u := range used {
+			if !Overlaps(subnet, u) {
+				continue
+			}
+
+			free = false
+
+			// skip past the used subnet when it
+			// is larger than the subnet
+			last := new(big.Int).Add(toInt(lastIP(u)), big.NewInt(1))
+			if last.Cmp(next) > 0 {
+				next = last
+			}
+		}
+
+		if free {
+			return subnet, true
+		}
+
+		n = next
+	}
+
+	return nil, false
+}
+
+// Overlaps returns true when the subnets share any addresses.
+func Overlaps(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
+// toInt is a helper function to convert
+// the address to an integer.
+func toInt(ip net.IP) *big.Int {
+	// check if the address is IPv4
+	if v4 := ip.To4(); v4 != nil {
+		return new(big.Int).SetBytes(v4)
+	}
+
+	return new(big.Int).SetBytes(ip.To16())
+}
+
+// toIP is a helper function to convert the
+// integer to an address of the length provided.
+func toIP(n *big.Int, bits int) net.IP {
+	// nolint: gomnd // ignore magic number
+	ip := make(net.IP, bits/8)
+
+	return n.FillBytes(ip)
+}
+
+// lastIP is a helper function to capture
+// the last address for the subnet.
+func lastIP(subnet *net.IPNet) net.IP {
+	ip := make(net.IP, len(subnet.IP))
+
+	for i := range subnet.IP {
+		ip[i] = subnet.IP[i] | ^subnet.Mask[i]
+	}
+
+	return ip
+}
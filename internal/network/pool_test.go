@@ -0,0 +1,162 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package network
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestNetwork_ParsePools(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		pools   []string
+		want    []string
+	}{
+		{
+			failure: false,
+			pools:   []string{"10.200.0.0/16", "172.30.0.0/16=26", "fd00:1234::/48"},
+			want:    []string{"10.200.0.0/16=24", "172.30.0.0/16=26", "fd00:1234::/48=64"},
+		},
+		{
+			failure: false,
+			pools:   []string{"10.200.1.7/16=/20"},
+			want:    []string{"10.200.0.0/16=20"},
+		},
+		{
+			failure: false,
+			pools:   []string{"10.200.0.0/28"},
+			want:    []string{"10.200.0.0/28=28"},
+		},
+		{
+			failure: false,
+			pools:   []string{},
+			want:    []string{},
+		},
+		{
+			failure: true,
+			pools:   []string{"10.200.0.0"},
+		},
+		{
+			failure: true,
+			pools:   []string{"10.200.0.0/16=foo"},
+		},
+		{
+			failure: true,
+			pools:   []string{"10.200.0.0/16=8"},
+		},
+		{
+			failure: true,
+			pools:   []string{"10.200.0.0/16=33"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got, err := ParsePools(test.pools)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("ParsePools for %v should have returned err", test.pools)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ParsePools for %v returned err: %v", test.pools, err)
+		}
+
+		pools := []string{}
+		for _, pool := range got {
+			pools = append(pools, pool.String())
+		}
+
+		if !reflect.DeepEqual(pools, test.want) {
+			t.Errorf("ParsePools is %v, want %v", pools, test.want)
+		}
+	}
+}
+
+func TestNetwork_Pool_Next(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		pool string
+		used []string
+		want string
+	}{
+		{
+			pool: "10.200.0.0/16",
+			used: []string{},
+			want: "10.200.0.0/24",
+		},
+		{
+			pool: "10.200.0.0/16",
+			used: []string{"10.200.0.0/24", "10.200.1.0/24", "172.17.0.0/16"},
+			want: "10.200.2.0/24",
+		},
+		{
+			pool: "10.200.0.0/16",
+			used: []string{"10.200.0.0/20", "10.200.16.128/25"},
+			want: "10.200.17.0/24",
+		},
+		{
+			pool: "10.200.0.0/16",
+			used: []string{"10.0.0.0/8"},
+			want: "",
+		},
+		{
+			pool: "10.200.0.0/23",
+			used: []string{"10.200.0.0/24", "10.200.1.0/24"},
+			want: "",
+		},
+		{
+			pool: "fd00:1234::/48",
+			used: []string{"fd00:1234::/64", "10.200.0.0/24"},
+			want: "fd00:1234:0:1::/64",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		pools, err := ParsePools([]string{test.pool})
+		if err != nil {
+			t.Errorf("ParsePools for %s returned err: %v", test.pool, err)
+		}
+
+		used := []*net.IPNet{}
+
+		for _, u := range test.used {
+			_, subnet, err := net.ParseCIDR(u)
+			if err != nil {
+				t.Errorf("unable to parse subnet %s: %v", u, err)
+			}
+
+			used = append(used, subnet)
+		}
+
+		got, ok := pools[0].Next(used)
+
+		if len(test.want) == 0 {
+			if ok {
+				t.Errorf("Next for %s is %s, want none", test.pool, got)
+			}
+
+			continue
+		}
+
+		if !ok {
+			t.Errorf("Next for %s returned none, want %s", test.pool, test.want)
+
+			continue
+		}
+
+		if got.String() != test.want {
+			t.Errorf("Next for %s is %s, want %s", test.pool, got, test.want)
+		}
+	}
+}
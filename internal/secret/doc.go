@@ -0,0 +1,12 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+// Package secret provides the ability for Vela to deliver
+// the secrets for a container as files, rather than in
+// the environment, for the runtime.
+//
+// Usage:
+//
+// 	import "github.com/go-vela/pkg-runtime/internal/secret"
+package secret
@@ -0,0 +1,81 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package secret
+
+import (
+	"path"
+	"strings"
+
+	"github.com/go-vela/types/pipeline"
+)
+
+// Mount represents the path in the container
+// where the files for secrets are mounted.
+const Mount = "/vela/secrets"
+
+// Is checks if the environment variable for
+// the pipeline container is from a secret.
+//
+// The executor injects the value for each secret into
+// the environment using the upper case for the target.
+func Is(ctn *pipeline.Container, name string) bool {
+	// iterate through each secret for the container
+	for _, secret := range ctn.Secrets {
+		if strings.EqualFold(secret.Target, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Path returns the path in the container
+// for the file containing the secret.
+func Path(name string) string {
+	return path.Join(Mount, name)
+}
+
+// File checks if the secret for the environment variable
+// is delivered as a file, by matching the target for the
+// secret against the provided list of secret names.
+func File(name string, names []string) bool {
+	// iterate through each secret name provided
+	for _, file := range names {
+		if strings.EqualFold(file, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Files splits the environment for the pipeline container
+// into the environment without the secrets delivered as
+// files and the files, by name, for those secrets. Only
+// the secrets matching the provided list of secret names
+// are delivered as files, so any other secrets remain in
+// the environment.
+//
+// A <name>_FILE environment variable, containing the path
+// to the file, is added in place of each secret.
+func Files(ctn *pipeline.Container, names []string) (map[string]string, map[string]string) {
+	env := make(map[string]string)
+	files := make(map[string]string)
+
+	// iterate through each element in the container environment
+	for k, v := range ctn.Environment {
+		// check if the environment variable is from a secret delivered as a file
+		if !Is(ctn, k) || !File(k, names) {
+			env[k] = v
+
+			continue
+		}
+
+		files[k] = v
+		env[k+"_FILE"] = Path(k)
+	}
+
+	return env, files
+}
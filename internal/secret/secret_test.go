@@ -0,0 +1,129 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package secret
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-vela/types/pipeline"
+)
+
+func TestSecret_Is(t *testing.T) {
+	// setup types
+	_ctn := &pipeline.Container{
+		Secrets: pipeline.StepSecretSlice{{Source: "docker_password", Target: "docker_password"}},
+	}
+
+	// setup tests
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{
+			name: "DOCKER_PASSWORD",
+			want: true,
+		},
+		{
+			name: "docker_password",
+			want: true,
+		},
+		{
+			name: "DOCKER_USERNAME",
+			want: false,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got := Is(_ctn, test.name)
+
+		if got != test.want {
+			t.Errorf("Is for %s is %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestSecret_Files(t *testing.T) {
+	// setup types
+	_ctn := &pipeline.Container{
+		Environment: map[string]string{
+			"DOCKER_PASSWORD": "sup3rs3cr3t",
+			"DOCKER_USERNAME": "octocat",
+			"NPM_TOKEN":       "t0k3n",
+		},
+		Secrets: pipeline.StepSecretSlice{
+			{Source: "docker_password", Target: "docker_password"},
+			{Source: "npm_token", Target: "npm_token"},
+		},
+	}
+
+	// setup tests
+	tests := []struct {
+		name      string
+		names     []string
+		wantEnv   map[string]string
+		wantFiles map[string]string
+	}{
+		{
+			name:  "file secret",
+			names: []string{"docker_password"},
+			wantEnv: map[string]string{
+				"DOCKER_PASSWORD_FILE": "/vela/secrets/DOCKER_PASSWORD",
+				"DOCKER_USERNAME":      "octocat",
+				"NPM_TOKEN":            "t0k3n",
+			},
+			wantFiles: map[string]string{
+				"DOCKER_PASSWORD": "sup3rs3cr3t",
+			},
+		},
+		{
+			name:  "file secrets",
+			names: []string{"DOCKER_PASSWORD", "NPM_TOKEN"},
+			wantEnv: map[string]string{
+				"DOCKER_PASSWORD_FILE": "/vela/secrets/DOCKER_PASSWORD",
+				"DOCKER_USERNAME":      "octocat",
+				"NPM_TOKEN_FILE":       "/vela/secrets/NPM_TOKEN",
+			},
+			wantFiles: map[string]string{
+				"DOCKER_PASSWORD": "sup3rs3cr3t",
+				"NPM_TOKEN":       "t0k3n",
+			},
+		},
+		{
+			name:  "not a secret",
+			names: []string{"DOCKER_USERNAME"},
+			wantEnv: map[string]string{
+				"DOCKER_PASSWORD": "sup3rs3cr3t",
+				"DOCKER_USERNAME": "octocat",
+				"NPM_TOKEN":       "t0k3n",
+			},
+			wantFiles: map[string]string{},
+		},
+		{
+			name:  "no file secrets",
+			names: nil,
+			wantEnv: map[string]string{
+				"DOCKER_PASSWORD": "sup3rs3cr3t",
+				"DOCKER_USERNAME": "octocat",
+				"NPM_TOKEN":       "t0k3n",
+			},
+			wantFiles: map[string]string{},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		gotEnv, gotFiles := Files(_ctn, test.names)
+
+		if !reflect.DeepEqual(gotEnv, test.wantEnv) {
+			t.Errorf("Files for %s env is %v, want %v", test.name, gotEnv, test.wantEnv)
+		}
+
+		if !reflect.DeepEqual(gotFiles, test.wantFiles) {
+			t.Errorf("Files for %s files is %v, want %v", test.name, gotFiles, test.wantFiles)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+// Package signature provides the ability for Vela to verify
+// cosign-style signatures for an image provided for a
+// container against locally configured public keys.
+//
+// Usage:
+//
+// 	import "github.com/go-vela/pkg-runtime/internal/signature"
+package signature
@@ -0,0 +1,287 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// mockToken represents the bearer token issued by the mock registry.
+const mockToken = "vela"
+
+// MockRegistry represents an in-memory stand-in for an OCI
+// registry capable of hosting images and their signatures.
+//
+// This is intended for running tests only.
+type MockRegistry struct {
+	// https://pkg.go.dev/net/http/httptest#Server
+	server *httptest.Server
+	// private key used for signing images
+	key *ecdsa.PrivateKey
+
+	mutex     sync.Mutex
+	manifests map[string][]byte
+	blobs     map[string][]byte
+}
+
+// NewMockRegistry creates and starts a MockRegistry
+// with a generated key for signing images.
+//
+// This function is intended for running tests only.
+func NewMockRegistry() (*MockRegistry, error) {
+	// https://pkg.go.dev/crypto/ecdsa#GenerateKey
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &MockRegistry{
+		key:       key,
+		manifests: make(map[string][]byte),
+		blobs:     make(map[string][]byte),
+	}
+
+	// https://pkg.go.dev/net/http/httptest#NewTLSServer
+	m.server = httptest.NewTLSServer(http.HandlerFunc(m.serve))
+
+	return m, nil
+}
+
+// Close shuts down the MockRegistry.
+func (m *MockRegistry) Close() {
+	m.server.Close()
+}
+
+// Host returns the host for the MockRegistry.
+func (m *MockRegistry) Host() string {
+	u, _ := url.Parse(m.server.URL)
+
+	return u.Host
+}
+
+// Client returns an HTTP client trusting the MockRegistry.
+func (m *MockRegistry) Client() *http.Client {
+	return m.server.Client()
+}
+
+// Key returns the private key used for signing images.
+func (m *MockRegistry) Key() crypto.Signer {
+	return m.key
+}
+
+// PublicKey returns the PEM encoded public key
+// for the private key used for signing images.
+func (m *MockRegistry) PublicKey() ([]byte, error) {
+	return EncodePublicKey(m.key.Public())
+}
+
+// EncodePublicKey returns the PEM encoded form of the public key.
+func EncodePublicKey(key crypto.PublicKey) ([]byte, error) {
+	// https://pkg.go.dev/crypto/x509#MarshalPKIXPublicKey
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// Push adds an image manifest to the MockRegistry
+// and returns the digest for the manifest.
+func (m *MockRegistry) Push(repository, tag string) string {
+	config := m.blob([]byte(fmt.Sprintf(`{"architecture":"amd64","os":"linux","tag":"%s"}`, tag)))
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+		"config": descriptor{
+			MediaType: "application/vnd.oci.image.config.v1+json",
+			Digest:    config,
+		},
+		"layers": []descriptor{},
+	})
+
+	_digest := digest(body)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.manifests[repository+":"+tag] = body
+	m.manifests[repository+":"+_digest] = body
+
+	return _digest
+}
+
+// Sign adds a signature for the image manifest
+// digest to the MockRegistry using the key.
+func (m *MockRegistry) Sign(repository, _digest string, key crypto.Signer) error {
+	return m.sign(repository, _digest, key, false)
+}
+
+// Tamper adds a signature for the image manifest digest to
+// the MockRegistry where the payload was modified after
+// the signature was created.
+func (m *MockRegistry) Tamper(repository, _digest string) error {
+	return m.sign(repository, _digest, m.key, true)
+}
+
+// sign is a helper function to create the cosign
+// signature manifest for the image manifest digest.
+func (m *MockRegistry) sign(repository, _digest string, key crypto.Signer, tamper bool) error {
+	p := new(payload)
+	p.Critical.Identity.DockerReference = m.Host() + "/" + repository
+	p.Critical.Image.DockerManifestDigest = _digest
+	p.Critical.Type = payloadType
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(data)
+
+	sig, err := key.Sign(rand.Reader, sum[:], crypto.SHA256)
+	if err != nil {
+		return err
+	}
+
+	// check if the payload should be modified after signing
+	if tamper {
+		p.Critical.Image.DockerManifestDigest = strings.Replace(_digest, "sha256:", "sha256:0", 1)[:len(_digest)]
+
+		data, err = json.Marshal(p)
+		if err != nil {
+			return err
+		}
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+		"layers": []descriptor{
+			{
+				MediaType: "application/vnd.dev.cosign.simplesigning.v1+json",
+				Digest:    m.blob(data),
+				Size:      int64(len(data)),
+				Annotations: map[string]string{
+					annotation: base64.StdEncoding.EncodeToString(sig),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.manifests[repository+":"+strings.Replace(_digest, ":", "-", 1)+".sig"] = body
+
+	return nil
+}
+
+// Mirror adds all images and signatures from the origin
+// MockRegistry to the MockRegistry, like a registry mirror
+// caching the images from the origin registry.
+func (m *MockRegistry) Mirror(origin *MockRegistry) {
+	origin.mutex.Lock()
+	defer origin.mutex.Unlock()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for ref, body := range origin.manifests {
+		m.manifests[ref] = body
+	}
+
+	for _digest, data := range origin.blobs {
+		m.blobs[_digest] = data
+	}
+}
+
+// blob is a helper function to add
+// the blob to the MockRegistry.
+func (m *MockRegistry) blob(data []byte) string {
+	_digest := digest(data)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.blobs[_digest] = data
+
+	return _digest
+}
+
+// serve is a helper function to handle
+// requests for the MockRegistry.
+func (m *MockRegistry) serve(w http.ResponseWriter, r *http.Request) {
+	// handle requests for a token
+	if r.URL.Path == "/token" {
+		fmt.Fprintf(w, `{"token":"%s"}`, mockToken)
+
+		return
+	}
+
+	// require a token for all other requests
+	if r.Header.Get("Authorization") != "Bearer "+mockToken {
+		w.Header().Set(
+			"WWW-Authenticate",
+			fmt.Sprintf(`Bearer realm="%s/token",service="%s",scope="repository:vela:pull"`, m.server.URL, m.Host()),
+		)
+		w.WriteHeader(http.StatusUnauthorized)
+
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v2/")
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// handle requests for a manifest
+	if i := strings.LastIndex(path, "/manifests/"); i > 0 {
+		body, ok := m.manifests[path[:i]+":"+path[i+len("/manifests/"):]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = w.Write(body)
+
+		return
+	}
+
+	// handle requests for a blob
+	if i := strings.LastIndex(path, "/blobs/"); i > 0 {
+		body, ok := m.blobs[path[i+len("/blobs/"):]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = w.Write(body)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNotFound)
+}
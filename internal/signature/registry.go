@@ -0,0 +1,253 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package signature
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// maxResponseSize represents the maximum size in bytes
+// of a manifest or blob fetched from the registry.
+const maxResponseSize = 4 << 20
+
+// manifestTypes represents the list of media types
+// accepted when fetching a manifest from the registry.
+var manifestTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// challengeParams represents the expression for capturing
+// the parameters from an authentication challenge.
+var challengeParams = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// errNotFound represents the error returned when
+// a manifest or blob does not exist in the registry.
+var errNotFound = errors.New("not found in registry")
+
+// manifest represents the subset of an OCI image
+// manifest needed for verifying signatures.
+type manifest struct {
+	Layers []descriptor `json:"layers"`
+}
+
+// descriptor represents the subset of an OCI content
+// descriptor needed for verifying signatures.
+type descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// registry represents a minimal client for
+// the OCI distribution API of a registry.
+type registry struct {
+	client *http.Client
+	host   string
+	// bearer token captured from the registry authentication challenge
+	token string
+}
+
+// newRegistry creates a registry client for the provided domain.
+func newRegistry(client *http.Client, domain string) *registry {
+	host := domain
+
+	// check if the domain is Docker Hub
+	if strings.EqualFold(domain, "docker.io") {
+		host = "registry-1.docker.io"
+	}
+
+	return &registry{
+		client: client,
+		host:   host,
+	}
+}
+
+// Manifest fetches the manifest for the repository and
+// reference from the registry. It returns the raw manifest
+// and the digest computed from the manifest contents.
+func (r *registry) Manifest(ctx context.Context, repository, ref string) ([]byte, string, error) {
+	path := fmt.Sprintf("/v2/%s/manifests/%s", repository, ref)
+
+	body, err := r.get(ctx, path, manifestTypes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return body, digest(body), nil
+}
+
+// Blob fetches the blob for the repository and digest from the
+// registry and verifies the contents match the digest.
+func (r *registry) Blob(ctx context.Context, repository, _digest string) ([]byte, error) {
+	path := fmt.Sprintf("/v2/%s/blobs/%s", repository, _digest)
+
+	body, err := r.get(ctx, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// verify the blob contents match the digest
+	if !strings.EqualFold(digest(body), _digest) {
+		return nil, fmt.Errorf("blob %s does not match digest", _digest)
+	}
+
+	return body, nil
+}
+
+// get is a helper function to send a GET request to the
+// registry and handle the authentication challenge.
+func (r *registry) get(ctx context.Context, path string, accept []string) ([]byte, error) {
+	resp, err := r.do(ctx, path, accept)
+	if err != nil {
+		return nil, err
+	}
+
+	// check if the registry requested authentication
+	if resp.StatusCode == http.StatusUnauthorized && len(r.token) == 0 {
+		challenge := resp.Header.Get("WWW-Authenticate")
+
+		resp.Body.Close()
+
+		// capture a token from the authentication challenge
+		err = r.authenticate(ctx, challenge)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err = r.do(ctx, path, accept)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		break
+	case http.StatusNotFound:
+		return nil, errNotFound
+	default:
+		return nil, fmt.Errorf("unexpected status %d from registry %s for %s", resp.StatusCode, r.host, path)
+	}
+
+	return ioutil.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+}
+
+// do is a helper function to send a GET request to the registry.
+func (r *registry) do(ctx context.Context, path string, accept []string) (*http.Response, error) {
+	u := url.URL{Scheme: "https", Host: r.host, Path: path}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(accept) > 0 {
+		req.Header.Set("Accept", strings.Join(accept, ", "))
+	}
+
+	if len(r.token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+
+	return r.client.Do(req)
+}
+
+// authenticate is a helper function to capture an anonymous
+// token from the Bearer authentication challenge.
+//
+// https://docs.docker.com/registry/spec/auth/token/
+func (r *registry) authenticate(ctx context.Context, challenge string) error {
+	// check if the challenge is for Bearer authentication
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return fmt.Errorf("unsupported authentication challenge from registry %s: %s", r.host, challenge)
+	}
+
+	params := parseChallenge(challenge[len("bearer "):])
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil || len(realm.Host) == 0 {
+		return fmt.Errorf("invalid authentication realm from registry %s: %s", r.host, params["realm"])
+	}
+
+	query := realm.Query()
+
+	for _, key := range []string{"service", "scope"} {
+		if len(params[key]) > 0 {
+			query.Set(key, params[key])
+		}
+	}
+
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from authentication realm %s", resp.StatusCode, realm.Host)
+	}
+
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+
+	err = json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&token)
+	if err != nil {
+		return err
+	}
+
+	r.token = token.Token
+	if len(r.token) == 0 {
+		r.token = token.AccessToken
+	}
+
+	return nil
+}
+
+// parseChallenge is a helper function to parse the
+// parameters from an authentication challenge.
+func parseChallenge(challenge string) map[string]string {
+	params := make(map[string]string)
+
+	for _, match := range challengeParams.FindAllStringSubmatch(challenge, -1) {
+		params[strings.ToLower(match[1])] = match[2]
+	}
+
+	return params
+}
+
+// digest is a helper function to compute
+// the sha256 digest for the provided content.
+func digest(content []byte) string {
+	sum := sha256.Sum256(content)
+
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
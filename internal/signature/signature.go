@@ -0,0 +1,413 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package signature
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/docker/distribution/reference"
+
+	"github.com/go-vela/pkg-runtime/internal/image"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ReasonUnsigned represents the reason for
+	// an image without any signatures.
+	ReasonUnsigned = "unsigned"
+	// ReasonInvalid represents the reason for an image
+	// without a signature matching the configured keys
+	// or with a signature that has been tampered with.
+	ReasonInvalid = "invalid signature"
+	// ReasonUnavailable represents the reason for an image
+	// where the signatures could not be retrieved.
+	ReasonUnavailable = "unable to verify"
+
+	// annotation represents the annotation on a signature
+	// layer containing the base64 encoded signature.
+	annotation = "dev.cosignproject.cosign/signature"
+
+	// payloadType represents the type of a cosign payload.
+	payloadType = "cosign container image signature"
+
+	// defaultTimeout represents the default timeout
+	// for requests to the registry.
+	defaultTimeout = 30 * time.Second
+)
+
+// Error represents an error returned when the
+// signature for an image can not be verified.
+type Error struct {
+	// image that failed verification
+	Image string
+	// reason the image failed verification
+	Reason string
+	// underlying error that caused the failure
+	Err error
+}
+
+// Error implements the error interface for the Error.
+func (e *Error) Error() string {
+	// check if an underlying error was captured
+	if e.Err == nil {
+		return fmt.Sprintf("image %s failed signature verification: %s", e.Image, e.Reason)
+	}
+
+	return fmt.Sprintf("image %s failed signature verification: %s: %v", e.Image, e.Reason, e.Err)
+}
+
+// Unwrap returns the underlying error for the Error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// payload represents the cosign simple signing payload.
+//
+// https://github.com/sigstore/cosign/blob/main/specs/SIGNATURE_SPEC.md
+type payload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+// Verifier represents the configuration necessary
+// for verifying signatures for images offline.
+//
+// Offline verification only relies on the registry
+// hosting the image and the locally configured keys.
+type Verifier struct {
+	// specifies a list of public keys trusted for signatures
+	Keys []crypto.PublicKey
+	// specifies a list of image patterns that require signatures
+	//
+	// https://pkg.go.dev/github.com/docker/distribution/reference#FamiliarMatch
+	Images []string
+	// specifies the HTTP client used for requests to the registry
+	Client *http.Client
+}
+
+// New creates a Verifier from the provided list of paths
+// to PEM encoded public keys and list of image patterns.
+// No Verifier is returned when neither are provided.
+func New(keys, images []string) (*Verifier, error) {
+	// check if any image patterns were provided
+	if len(images) == 0 {
+		// check if any public keys were provided
+		if len(keys) > 0 {
+			return nil, fmt.Errorf("no signed images provided for public keys")
+		}
+
+		return nil, nil
+	}
+
+	// check if any public keys were provided
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no public keys provided for signed images")
+	}
+
+	v := &Verifier{
+		Images: images,
+		Client: &http.Client{Timeout: defaultTimeout},
+	}
+
+	// iterate through all keys provided
+	for _, file := range keys {
+		// nolint: gosec // ignore file inclusion via variable
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read public key %s: %w", file, err)
+		}
+
+		key, err := ParsePublicKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse public key %s: %w", file, err)
+		}
+
+		v.Keys = append(v.Keys, key)
+	}
+
+	return v, nil
+}
+
+// ParsePublicKey digests the provided PEM encoded public key.
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	// https://pkg.go.dev/crypto/x509#ParsePKIXPublicKey
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch key.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// Required returns true if the provided image
+// must be signed to be permitted to run.
+func (v *Verifier) Required(_image string) (bool, error) {
+	// check if the verifier is nil
+	if v == nil {
+		return false, nil
+	}
+
+	// https://pkg.go.dev/github.com/docker/distribution/reference?tab=doc#ParseNormalizedNamed
+	_named, err := reference.ParseNormalizedNamed(_image)
+	if err != nil {
+		return false, err
+	}
+
+	// iterate through all image patterns provided
+	for _, pattern := range v.Images {
+		// https://pkg.go.dev/github.com/docker/distribution/reference#FamiliarMatch
+		match, err := reference.FamiliarMatch(pattern, reference.TagNameOnly(_named))
+		if err != nil {
+			return false, err
+		}
+
+		if match {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Verify checks the provided image has a valid signature from
+// one of the configured keys. If the image requires a signature,
+// it will return the verified manifest digest for the image.
+// If the image doesn't require a signature, it will return an
+// empty digest.
+//
+// The signatures are retrieved from the registry mirror for
+// the image when one is provided, and from the origin registry
+// when they can't be retrieved from the mirror.
+func (v *Verifier) Verify(ctx context.Context, _image string, mirrors map[string]string) (string, error) {
+	// check if the image requires a signature
+	required, err := v.Required(_image)
+	if err != nil {
+		return "", &Error{Image: _image, Reason: ReasonUnavailable, Err: err}
+	}
+
+	if !required {
+		return "", nil
+	}
+
+	// https://pkg.go.dev/github.com/docker/distribution/reference?tab=doc#ParseNormalizedNamed
+	_named, err := reference.ParseNormalizedNamed(_image)
+	if err != nil {
+		return "", &Error{Image: _image, Reason: ReasonUnavailable, Err: err}
+	}
+
+	_named = reference.TagNameOnly(_named)
+
+	// capture the registries hosting the image
+	sources := []reference.Named{_named}
+
+	// parse image with the registry mirrors
+	//
+	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/image#ParseWithMirror
+	_mirror, err := image.ParseWithMirror(_image, mirrors)
+	if err == nil && !strings.EqualFold(_mirror, _named.String()) {
+		// https://pkg.go.dev/github.com/docker/distribution/reference?tab=doc#ParseNormalizedNamed
+		mirrored, err := reference.ParseNormalizedNamed(_mirror)
+		if err == nil {
+			sources = []reference.Named{mirrored, _named}
+		}
+	}
+
+	// iterate through all registries hosting the image
+	for i, source := range sources {
+		var _digest string
+
+		_digest, err = v.verify(ctx, _image, _named, source)
+
+		// check if the signatures could be retrieved from the registry
+		var sigErr *Error
+		if err == nil || !errors.As(err, &sigErr) || sigErr.Reason != ReasonUnavailable {
+			return _digest, err
+		}
+
+		// check if there is another registry to fall back to
+		if i < len(sources)-1 {
+			logrus.Warnf("unable to verify image %s from mirror, falling back to origin: %v", _image, err)
+		}
+	}
+
+	return "", err
+}
+
+// verify is a helper function to verify the image has
+// a valid signature from one of the configured keys
+// using the signatures from the source registry.
+func (v *Verifier) verify(ctx context.Context, _image string, _named, source reference.Named) (string, error) {
+	// https://pkg.go.dev/github.com/docker/distribution/reference?tab=doc#Domain
+	r := newRegistry(v.Client, reference.Domain(source))
+
+	// https://pkg.go.dev/github.com/docker/distribution/reference?tab=doc#Path
+	repository := reference.Path(source)
+
+	// capture the reference to the image manifest
+	ref := ""
+	if tagged, ok := source.(reference.Tagged); ok {
+		ref = tagged.Tag()
+	}
+
+	if digested, ok := source.(reference.Digested); ok {
+		ref = digested.Digest().String()
+	}
+
+	// send API call to capture the image manifest
+	_, _digest, err := r.Manifest(ctx, repository, ref)
+	if err != nil {
+		return "", &Error{Image: _image, Reason: ReasonUnavailable, Err: err}
+	}
+
+	// check if the manifest matches the pinned digest
+	if strings.HasPrefix(ref, "sha256:") && !strings.EqualFold(ref, _digest) {
+		return "", &Error{Image: _image, Reason: ReasonInvalid, Err: errors.New("manifest does not match digest")}
+	}
+
+	// send API call to capture the signature manifest
+	//
+	// https://github.com/sigstore/cosign/blob/main/specs/SIGNATURE_SPEC.md
+	raw, _, err := r.Manifest(ctx, repository, strings.Replace(_digest, ":", "-", 1)+".sig")
+	if errors.Is(err, errNotFound) {
+		return "", &Error{Image: _image, Reason: ReasonUnsigned}
+	}
+
+	if err != nil {
+		return "", &Error{Image: _image, Reason: ReasonUnavailable, Err: err}
+	}
+
+	m := new(manifest)
+
+	err = json.Unmarshal(raw, m)
+	if err != nil {
+		return "", &Error{Image: _image, Reason: ReasonInvalid, Err: err}
+	}
+
+	// check if any signatures exist in the manifest
+	if len(m.Layers) == 0 {
+		return "", &Error{Image: _image, Reason: ReasonUnsigned}
+	}
+
+	// iterate through all signatures in the manifest
+	for _, layer := range m.Layers {
+		err = v.verifyLayer(ctx, r, repository, _named, _digest, layer)
+		if err == nil {
+			return _digest, nil
+		}
+	}
+
+	return "", &Error{Image: _image, Reason: ReasonInvalid, Err: err}
+}
+
+// verifyLayer is a helper function to verify the signature
+// layer matches the manifest digest for the image and is
+// signed by one of the configured keys.
+//
+// nolint: lll // ignore long line length due to variable names
+func (v *Verifier) verifyLayer(ctx context.Context, r *registry, repository string, _named reference.Named, _digest string, layer descriptor) error {
+	// capture the signature from the layer annotation
+	sig, err := base64.StdEncoding.DecodeString(layer.Annotations[annotation])
+	if err != nil || len(sig) == 0 {
+		return errors.New("no signature found on layer")
+	}
+
+	// send API call to capture the signature payload
+	data, err := r.Blob(ctx, repository, layer.Digest)
+	if err != nil {
+		return err
+	}
+
+	// verify the payload is signed by one of the keys
+	if !v.verifySignature(data, sig) {
+		return errors.New("signature does not match any public key")
+	}
+
+	p := new(payload)
+
+	err = json.Unmarshal(data, p)
+	if err != nil {
+		return err
+	}
+
+	// verify the payload is a cosign signature
+	if !strings.EqualFold(p.Critical.Type, payloadType) {
+		return fmt.Errorf("unsupported payload type %s", p.Critical.Type)
+	}
+
+	// verify the payload is for the image manifest
+	if !strings.EqualFold(p.Critical.Image.DockerManifestDigest, _digest) {
+		return errors.New("payload does not match manifest digest")
+	}
+
+	// verify the payload identity is for the image repository
+	identity, err := reference.ParseNormalizedNamed(p.Critical.Identity.DockerReference)
+	if err != nil || !strings.EqualFold(identity.Name(), _named.Name()) {
+		return errors.New("payload does not match image repository")
+	}
+
+	return nil
+}
+
+// verifySignature is a helper function to check if the
+// signature for the payload matches one of the keys.
+func (v *Verifier) verifySignature(data, sig []byte) bool {
+	sum := sha256.Sum256(data)
+
+	// iterate through all keys provided
+	for _, key := range v.Keys {
+		switch k := key.(type) {
+		case *ecdsa.PublicKey:
+			// https://pkg.go.dev/crypto/ecdsa#VerifyASN1
+			if ecdsa.VerifyASN1(k, sum[:], sig) {
+				return true
+			}
+		case *rsa.PublicKey:
+			// https://pkg.go.dev/crypto/rsa#VerifyPKCS1v15
+			if rsa.VerifyPKCS1v15(k, crypto.SHA256, sum[:], sig) == nil {
+				return true
+			}
+		case ed25519.PublicKey:
+			// https://pkg.go.dev/crypto/ed25519#Verify
+			if ed25519.Verify(k, data, sig) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
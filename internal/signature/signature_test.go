@@ -0,0 +1,414 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package signature
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestSignature_New(t *testing.T) {
+	// setup types
+	_registry, err := NewMockRegistry()
+	if err != nil {
+		t.Errorf("unable to create mock registry: %v", err)
+	}
+
+	defer _registry.Close()
+
+	key, err := _registry.PublicKey()
+	if err != nil {
+		t.Errorf("unable to encode public key: %v", err)
+	}
+
+	dir := t.TempDir()
+
+	err = ioutil.WriteFile(filepath.Join(dir, "cosign.pub"), key, 0600)
+	if err != nil {
+		t.Errorf("unable to write public key: %v", err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, "invalid.pub"), []byte("foo"), 0600)
+	if err != nil {
+		t.Errorf("unable to write public key: %v", err)
+	}
+
+	// setup tests
+	tests := []struct {
+		failure bool
+		keys    []string
+		images  []string
+		want    bool
+	}{
+		{
+			failure: false,
+			keys:    []string{filepath.Join(dir, "cosign.pub")},
+			images:  []string{"target/vela-*"},
+			want:    true,
+		},
+		{
+			failure: false,
+			keys:    []string{},
+			images:  []string{},
+			want:    false,
+		},
+		{
+			failure: true,
+			keys:    []string{filepath.Join(dir, "cosign.pub")},
+			images:  []string{},
+		},
+		{
+			failure: true,
+			keys:    []string{},
+			images:  []string{"target/vela-*"},
+		},
+		{
+			failure: true,
+			keys:    []string{filepath.Join(dir, "invalid.pub")},
+			images:  []string{"target/vela-*"},
+		},
+		{
+			failure: true,
+			keys:    []string{filepath.Join(dir, "notfound.pub")},
+			images:  []string{"target/vela-*"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got, err := New(test.keys, test.images)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("New should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("New returned err: %v", err)
+		}
+
+		if (got != nil) != test.want {
+			t.Errorf("New is %v, want enabled %v", got, test.want)
+		}
+	}
+}
+
+func TestSignature_ParsePublicKey(t *testing.T) {
+	// setup types
+	_ecdsa, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Errorf("unable to generate key: %v", err)
+	}
+
+	_ed25519, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Errorf("unable to generate key: %v", err)
+	}
+
+	ecdsaPEM, _ := EncodePublicKey(_ecdsa.Public())
+	ed25519PEM, _ := EncodePublicKey(_ed25519)
+
+	// setup tests
+	tests := []struct {
+		failure bool
+		data    []byte
+	}{
+		{
+			failure: false,
+			data:    ecdsaPEM,
+		},
+		{
+			failure: false,
+			data:    ed25519PEM,
+		},
+		{
+			failure: true,
+			data:    []byte("foo"),
+		},
+		{
+			failure: true,
+			data:    []byte("-----BEGIN PUBLIC KEY-----\nZm9v\n-----END PUBLIC KEY-----\n"),
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_, err := ParsePublicKey(test.data)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("ParsePublicKey should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ParsePublicKey returned err: %v", err)
+		}
+	}
+}
+
+func TestSignature_Verify(t *testing.T) {
+	// setup types
+	_registry, err := NewMockRegistry()
+	if err != nil {
+		t.Errorf("unable to create mock registry: %v", err)
+	}
+
+	defer _registry.Close()
+
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Errorf("unable to generate key: %v", err)
+	}
+
+	signed := _registry.Push("vela/signed", "v1")
+
+	err = _registry.Sign("vela/signed", signed, _registry.Key())
+	if err != nil {
+		t.Errorf("unable to sign image: %v", err)
+	}
+
+	_ = _registry.Push("vela/unsigned", "v1")
+
+	wrong := _registry.Push("vela/wrong", "v1")
+
+	err = _registry.Sign("vela/wrong", wrong, other)
+	if err != nil {
+		t.Errorf("unable to sign image: %v", err)
+	}
+
+	tampered := _registry.Push("vela/tampered", "v1")
+
+	err = _registry.Tamper("vela/tampered", tampered)
+	if err != nil {
+		t.Errorf("unable to tamper image: %v", err)
+	}
+
+	_verifier := &Verifier{
+		Keys:   []crypto.PublicKey{_registry.Key().Public()},
+		Images: []string{_registry.Host() + "/vela/*"},
+		Client: _registry.Client(),
+	}
+
+	// setup tests
+	tests := []struct {
+		failure bool
+		image   string
+		reason  string
+		want    string
+	}{
+		{
+			failure: false,
+			image:   _registry.Host() + "/vela/signed:v1",
+			want:    signed,
+		},
+		{
+			failure: false,
+			image:   _registry.Host() + "/vela/signed@" + signed,
+			want:    signed,
+		},
+		{
+			failure: false,
+			image:   "alpine:latest",
+			want:    "",
+		},
+		{
+			failure: true,
+			image:   _registry.Host() + "/vela/unsigned:v1",
+			reason:  ReasonUnsigned,
+		},
+		{
+			failure: true,
+			image:   _registry.Host() + "/vela/wrong:v1",
+			reason:  ReasonInvalid,
+		},
+		{
+			failure: true,
+			image:   _registry.Host() + "/vela/tampered:v1",
+			reason:  ReasonInvalid,
+		},
+		{
+			failure: true,
+			image:   _registry.Host() + "/vela/notfound:v1",
+			reason:  ReasonUnavailable,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got, err := _verifier.Verify(context.Background(), test.image, nil)
+
+		if test.failure {
+			var sigErr *Error
+			if !errors.As(err, &sigErr) {
+				t.Errorf("Verify for %s returned err %v, want Error", test.image, err)
+
+				continue
+			}
+
+			if sigErr.Reason != test.reason {
+				t.Errorf("Verify for %s reason is %s, want %s", test.image, sigErr.Reason, test.reason)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Verify for %s returned err: %v", test.image, err)
+		}
+
+		if got != test.want {
+			t.Errorf("Verify for %s is %s, want %s", test.image, got, test.want)
+		}
+	}
+}
+
+func TestSignature_Verify_Mirror(t *testing.T) {
+	// setup types
+	_origin, err := NewMockRegistry()
+	if err != nil {
+		t.Errorf("unable to create mock registry: %v", err)
+	}
+
+	defer _origin.Close()
+
+	_mirror, err := NewMockRegistry()
+	if err != nil {
+		t.Errorf("unable to create mock registry: %v", err)
+	}
+
+	defer _mirror.Close()
+
+	signed := _origin.Push("vela/signed", "v1")
+
+	err = _origin.Sign("vela/signed", signed, _origin.Key())
+	if err != nil {
+		t.Errorf("unable to sign image: %v", err)
+	}
+
+	_ = _origin.Push("vela/unsigned", "v1")
+
+	_mirror.Mirror(_origin)
+
+	// create an origin registry that is no longer reachable
+	_offline, err := NewMockRegistry()
+	if err != nil {
+		t.Errorf("unable to create mock registry: %v", err)
+	}
+
+	offline := _offline.Push("vela/signed", "v2")
+
+	err = _offline.Sign("vela/signed", offline, _origin.Key())
+	if err != nil {
+		t.Errorf("unable to sign image: %v", err)
+	}
+
+	_mirror.Mirror(_offline)
+
+	_offline.Close()
+
+	_verifier := &Verifier{
+		Keys:   []crypto.PublicKey{_origin.Key().Public()},
+		Images: []string{_origin.Host() + "/vela/*", _offline.Host() + "/vela/*"},
+		Client: _mirror.Client(),
+	}
+
+	// setup tests
+	tests := []struct {
+		name    string
+		failure bool
+		image   string
+		mirrors map[string]string
+		reason  string
+		want    string
+	}{
+		{
+			name:    "from mirror",
+			failure: false,
+			image:   _offline.Host() + "/vela/signed:v2",
+			mirrors: map[string]string{_offline.Host(): _mirror.Host()},
+			want:    offline,
+		},
+		{
+			name:    "fallback to origin",
+			failure: false,
+			image:   _origin.Host() + "/vela/signed:v1",
+			mirrors: map[string]string{_origin.Host(): "127.0.0.1:1"},
+			want:    signed,
+		},
+		{
+			name:    "unsigned from mirror",
+			failure: true,
+			image:   _origin.Host() + "/vela/unsigned:v1",
+			mirrors: map[string]string{_origin.Host(): _mirror.Host()},
+			reason:  ReasonUnsigned,
+		},
+		{
+			name:    "unavailable from mirror and origin",
+			failure: true,
+			image:   _offline.Host() + "/vela/signed:v2",
+			mirrors: map[string]string{_offline.Host(): "127.0.0.1:1"},
+			reason:  ReasonUnavailable,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := _verifier.Verify(context.Background(), test.image, test.mirrors)
+
+			if test.failure {
+				var sigErr *Error
+				if !errors.As(err, &sigErr) {
+					t.Errorf("Verify returned err %v, want Error", err)
+
+					return
+				}
+
+				if sigErr.Reason != test.reason {
+					t.Errorf("Verify reason is %s, want %s", sigErr.Reason, test.reason)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Verify returned err: %v", err)
+			}
+
+			if got != test.want {
+				t.Errorf("Verify is %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSignature_Verify_Nil(t *testing.T) {
+	// setup types
+	var _verifier *Verifier
+
+	// run test
+	got, err := _verifier.Verify(context.Background(), "alpine:latest", nil)
+	if err != nil {
+		t.Errorf("Verify returned err: %v", err)
+	}
+
+	if len(got) > 0 {
+		t.Errorf("Verify is %s, want empty digest", got)
+	}
+}
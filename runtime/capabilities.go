@@ -0,0 +1,31 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package runtime
+
+import (
+	"context"
+
+	"github.com/go-vela/pkg-runtime/runtime/docker"
+)
+
+// Capabilities represents the API version negotiated
+// with the daemon and the features supported with it.
+type Capabilities = docker.Capabilities
+
+// CapabilityReporter represents an optional interface for a
+// runtime capable of reporting the features supported by the
+// daemon, which also checks the daemon is reachable.
+//
+// Use a type assertion on the Engine to check for support:
+//
+// 	if reporter, ok := engine.(runtime.CapabilityReporter); ok {
+// 		caps, err := reporter.Capabilities(ctx)
+// 	}
+type CapabilityReporter interface {
+	// Capabilities defines a function that negotiates the
+	// API version with the daemon and captures the features
+	// supported with it.
+	Capabilities(context.Context) (*Capabilities, error)
+}
@@ -21,11 +21,16 @@ func (c *client) InspectBuild(ctx context.Context, b *pipeline.Build) ([]byte, e
 }
 
 // SetupBuild prepares the pipeline build.
-// This is a no-op for docker.
+// This negotiates the Docker API version with the daemon,
+// so a daemon outside of the supported range fails the
+// build before any resources are created for it.
 func (c *client) SetupBuild(ctx context.Context, b *pipeline.Build) error {
-	logrus.Tracef("no-op: setting up for build %s", b.ID)
+	logrus.Tracef("setting up for build %s", b.ID)
 
-	return nil
+	// capture the features supported by the daemon
+	_, err := c.Capabilities(ctx)
+
+	return err
 }
 
 // AssembleBuild finalizes pipeline build setup.
@@ -37,9 +42,27 @@ func (c *client) AssembleBuild(ctx context.Context, b *pipeline.Build) error {
 }
 
 // RemoveBuild deletes (kill, remove) the pipeline build metadata.
-// This is a no-op for docker.
+// This removes unused images when image garbage collection is
+// enabled and the configured interval has passed since images
+// were last removed, otherwise it is a no-op for docker.
 func (c *client) RemoveBuild(ctx context.Context, b *pipeline.Build) error {
-	logrus.Tracef("no-op: removing build %s", b.ID)
+	// check if image garbage collection is enabled and due
+	if !c.config.GC.enabled() || !c.collectDue() {
+		logrus.Tracef("no-op: removing build %s", b.ID)
+
+		return nil
+	}
+
+	logrus.Tracef("removing build %s", b.ID)
+
+	// remove unused images between builds
+	//
+	// Failing to remove images should not fail the build,
+	// so any error is only logged for the administrators.
+	_, err := c.CollectImages(ctx)
+	if err != nil {
+		logrus.Errorf("unable to collect unused images after build %s: %v", b.ID, err)
+	}
 
 	return nil
 }
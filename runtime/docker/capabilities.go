@@ -0,0 +1,168 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/versions"
+
+	"github.com/sirupsen/logrus"
+)
+
+// nolint: godot // ignore comment ending in a list
+//
+// MinVersion represents the minimum Docker API version
+// supported by the client.
+//
+// The Docker API version is negotiated with the daemon,
+// and the client refuses to run builds on a daemon with
+// an API version older than this.
+//
+// https://docs.docker.com/engine/api/#api-version-matrix
+//
+// For example (use the compatibility matrix above for reference):
+//
+// * the Docker version of v1.12 has a maximum API version of v1.24
+const MinVersion = "1.24"
+
+// nolint: godot // ignore comment ending in a list
+//
+// MaxVersion represents the maximum Docker API version
+// supported by the client.
+//
+// The Docker API version is negotiated with the daemon,
+// so a daemon with a newer API version is used with this,
+// and the client refuses to run builds with a newer API
+// version set in the environment (DOCKER_API_VERSION).
+//
+// https://docs.docker.com/engine/api/#api-version-matrix
+//
+// For example (use the compatibility matrix above for reference):
+//
+// * the Docker version of v20.10 has a maximum API version of v1.41
+const MaxVersion = "1.41"
+
+// Capabilities represents the Docker API version negotiated
+// with the daemon and the features supported with it.
+type Capabilities struct {
+	// APIVersion is the Docker API version negotiated with the daemon
+	APIVersion string `json:"api_version"`
+	// DaemonAPIVersion is the maximum Docker API version for the daemon
+	DaemonAPIVersion string `json:"daemon_api_version,omitempty"`
+	// OSType is the operating system for the daemon
+	OSType string `json:"os_type,omitempty"`
+	// Mounts indicates the daemon supports mounts for containers (v1.25)
+	Mounts bool `json:"mounts"`
+	// PullPlatform indicates the daemon supports the platform for pulling images (v1.32)
+	PullPlatform bool `json:"pull_platform"`
+	// Platform indicates the daemon supports the platform for creating containers (v1.41)
+	Platform bool `json:"platform"`
+}
+
+// Capabilities negotiates the Docker API version with
+// the daemon and captures the features supported with it.
+//
+// The result is captured once for the client, so any
+// later calls don't send API calls to the daemon. The
+// API calls are sent without holding the lock for the
+// client, so other builds aren't blocked by them.
+func (c *client) Capabilities(ctx context.Context) (*Capabilities, error) {
+	// check if the capabilities were already captured
+	if caps := c.captured(); caps != nil {
+		return caps, nil
+	}
+
+	// negotiating the Docker API version updates the
+	// Docker client, so only one call negotiates it
+	c.negotiate.Lock()
+	defer c.negotiate.Unlock()
+
+	// check if the capabilities were captured while waiting
+	if caps := c.captured(); caps != nil {
+		return caps, nil
+	}
+
+	logrus.Trace("negotiating docker API version with daemon")
+
+	// send API call to negotiate the Docker API version
+	//
+	// https://godoc.org/github.com/docker/docker/client#Client.NegotiateAPIVersion
+	c.Docker.NegotiateAPIVersion(ctx)
+
+	// send API call to capture the details for the daemon
+	//
+	// https://godoc.org/github.com/docker/docker/client#Client.Ping
+	ping, err := c.Docker.Ping(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to reach docker daemon: %w", err)
+	}
+
+	// check if the Docker API version for the daemon is older than the minimum
+	if len(ping.APIVersion) > 0 && versions.LessThan(ping.APIVersion, MinVersion) {
+		return nil, fmt.Errorf("docker daemon API version %s is older than the minimum supported version %s", ping.APIVersion, MinVersion)
+	}
+
+	// capture the Docker API version negotiated with the daemon
+	//
+	// https://godoc.org/github.com/docker/docker/client#Client.ClientVersion
+	version := strings.TrimPrefix(c.Docker.ClientVersion(), "v")
+
+	// check if the Docker API version is newer than the maximum
+	if versions.GreaterThan(version, MaxVersion) {
+		// lower the Docker API version to the maximum
+		//
+		// The version isn't lowered when it is set in the
+		// environment (DOCKER_API_VERSION) for the client.
+		//
+		// https://godoc.org/github.com/docker/docker/client#Client.NegotiateAPIVersionPing
+		c.Docker.NegotiateAPIVersionPing(types.Ping{APIVersion: MaxVersion})
+
+		version = strings.TrimPrefix(c.Docker.ClientVersion(), "v")
+	}
+
+	// check if the Docker API version is outside the supported range
+	switch {
+	case versions.LessThan(version, MinVersion):
+		return nil, fmt.Errorf("docker API version %s is older than the minimum supported version %s", version, MinVersion)
+	case versions.GreaterThan(version, MaxVersion):
+		return nil, fmt.Errorf("docker API version %s is newer than the maximum supported version %s", version, MaxVersion)
+	}
+
+	caps := &Capabilities{
+		APIVersion:       version,
+		DaemonAPIVersion: ping.APIVersion,
+		OSType:           ping.OSType,
+		Mounts:           versions.GreaterThanOrEqualTo(version, "1.25"),
+		PullPlatform:     versions.GreaterThanOrEqualTo(version, "1.32"),
+		Platform:         versions.GreaterThanOrEqualTo(version, "1.41"),
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// check if the capabilities were captured by another call
+	if c.capabilities != nil {
+		return c.capabilities, nil
+	}
+
+	c.capabilities = caps
+
+	logrus.Debugf("using docker API version %s with daemon", version)
+
+	return c.capabilities, nil
+}
+
+// captured is a helper function to capture the
+// capabilities already captured for the client.
+func (c *client) captured() *Capabilities {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.capabilities
+}
@@ -0,0 +1,469 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/versions"
+	docker "github.com/docker/docker/client"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/go-vela/types/pipeline"
+)
+
+// daemon is a helper function to create a stand-in
+// daemon reporting the Docker API version provided.
+func daemon(t *testing.T, version string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// check if the request is for the ping endpoint
+		if !strings.HasSuffix(r.URL.Path, "/_ping") {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		w.Header().Set("API-Version", version)
+		w.Header().Set("OSType", "linux")
+
+		_, _ = w.Write([]byte("OK"))
+	}))
+}
+
+func TestDocker_Capabilities(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name    string
+		failure bool
+		version string
+		want    *Capabilities
+	}{
+		{
+			name:    "minimum daemon",
+			failure: false,
+			version: "1.24",
+			want: &Capabilities{
+				APIVersion:       "1.24",
+				DaemonAPIVersion: "1.24",
+				OSType:           "linux",
+			},
+		},
+		{
+			name:    "older daemon",
+			failure: false,
+			version: "1.30",
+			want: &Capabilities{
+				APIVersion:       "1.30",
+				DaemonAPIVersion: "1.30",
+				OSType:           "linux",
+				Mounts:           true,
+			},
+		},
+		{
+			name:    "maximum daemon",
+			failure: false,
+			version: "1.41",
+			want: &Capabilities{
+				APIVersion:       "1.41",
+				DaemonAPIVersion: "1.41",
+				OSType:           "linux",
+				Mounts:           true,
+				PullPlatform:     true,
+				Platform:         true,
+			},
+		},
+		{
+			name:    "newer daemon",
+			failure: false,
+			version: "1.43",
+			want: &Capabilities{
+				APIVersion:       "1.41",
+				DaemonAPIVersion: "1.43",
+				OSType:           "linux",
+				Mounts:           true,
+				PullPlatform:     true,
+				Platform:         true,
+			},
+		},
+		{
+			name:    "unsupported daemon",
+			failure: true,
+			version: "1.20",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := daemon(t, test.version)
+			defer server.Close()
+
+			_engine, err := New(WithHost(strings.Replace(server.URL, "http://", "tcp://", 1)))
+			if err != nil {
+				t.Errorf("unable to create runtime engine: %v", err)
+			}
+
+			got, err := _engine.Capabilities(context.Background())
+
+			if test.failure {
+				if err == nil {
+					t.Errorf("Capabilities should have returned err")
+				}
+
+				// check the capabilities aren't captured for the client
+				if _engine.capabilities != nil {
+					t.Errorf("Capabilities captured %+v", _engine.capabilities)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Capabilities returned err: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Capabilities is %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDocker_Capabilities_Unreachable(t *testing.T) {
+	// setup types
+	server := daemon(t, MaxVersion)
+	server.Close()
+
+	_engine, err := New(WithHost(strings.Replace(server.URL, "http://", "tcp://", 1)))
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	// run test
+	_, err = _engine.Capabilities(context.Background())
+	if err == nil {
+		t.Errorf("Capabilities should have returned err")
+	}
+
+	err = _engine.SetupBuild(context.Background(), _pipeline)
+	if err == nil {
+		t.Errorf("SetupBuild should have returned err")
+	}
+}
+
+func TestDocker_Capabilities_Environment(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name    string
+		failure bool
+		version string
+		want    string
+	}{
+		{
+			name:    "supported version",
+			failure: false,
+			version: "1.40",
+			want:    "1.40",
+		},
+		{
+			name:    "newer version",
+			failure: true,
+			version: "1.43",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := daemon(t, "1.43")
+			defer server.Close()
+
+			// set the Docker API version in the environment
+			err := os.Setenv("DOCKER_API_VERSION", test.version)
+			if err != nil {
+				t.Errorf("unable to set environment: %v", err)
+			}
+
+			defer os.Unsetenv("DOCKER_API_VERSION")
+
+			_engine, err := New(WithHost(strings.Replace(server.URL, "http://", "tcp://", 1)))
+			if err != nil {
+				t.Errorf("unable to create runtime engine: %v", err)
+			}
+
+			got, err := _engine.Capabilities(context.Background())
+
+			if test.failure {
+				if err == nil {
+					t.Errorf("Capabilities should have returned err")
+				}
+
+				// check the capabilities aren't captured for the client
+				if _engine.capabilities != nil {
+					t.Errorf("Capabilities captured %+v", _engine.capabilities)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Capabilities returned err: %v", err)
+			}
+
+			if got.APIVersion != test.want {
+				t.Errorf("Capabilities API version is %s, want %s", got.APIVersion, test.want)
+			}
+		})
+	}
+}
+
+func TestDocker_Capabilities_Unlocked(t *testing.T) {
+	// setup types
+	pinged := make(chan struct{}, 1)
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+
+		// wait for the test to release the daemon
+		<-release
+
+		w.Header().Set("API-Version", MaxVersion)
+		w.Header().Set("OSType", "linux")
+
+		_, _ = w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	_engine, err := New(WithHost(strings.Replace(server.URL, "http://", "tcp://", 1)))
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	// run test
+	results := make(chan *Capabilities, 2)
+
+	for i := 0; i < 2; i++ {
+		go func() {
+			got, err := _engine.Capabilities(context.Background())
+			if err != nil {
+				t.Errorf("Capabilities returned err: %v", err)
+			}
+
+			results <- got
+		}()
+	}
+
+	<-pinged
+
+	// check the lock for the client isn't held while reaching the daemon
+	locked := make(chan struct{})
+
+	go func() {
+		_engine.mutex.Lock()
+		defer _engine.mutex.Unlock()
+
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Errorf("Capabilities held the lock for the client while reaching the daemon")
+	}
+
+	close(release)
+
+	first, second := <-results, <-results
+
+	if first == nil || first != second || first != _engine.capabilities {
+		t.Errorf("Capabilities are %+v and %+v, want the captured %+v", first, second, _engine.capabilities)
+	}
+}
+
+// versionDocker represents a Docker client negotiating
+// the Docker API version provided with the daemon and
+// recording the configuration for containers.
+type versionDocker struct {
+	docker.CommonAPIClient
+
+	version string
+	// the version is set in the environment (DOCKER_API_VERSION)
+	override bool
+	hosts    []*container.HostConfig
+	created  []*specs.Platform
+}
+
+// ClientVersion returns the Docker API version for the daemon.
+func (d *versionDocker) ClientVersion() string {
+	return d.version
+}
+
+// NegotiateAPIVersionPing lowers the Docker API version to the
+// version provided unless it is set in the environment.
+func (d *versionDocker) NegotiateAPIVersionPing(p types.Ping) {
+	if !d.override && versions.LessThan(p.APIVersion, strings.TrimPrefix(d.version, "v")) {
+		d.version = p.APIVersion
+	}
+}
+
+// ContainerCreate records the host config and platform for the container.
+//
+// nolint: lll // ignore long line length due to variable names
+func (d *versionDocker) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, name string) (container.ContainerCreateCreatedBody, error) {
+	d.hosts = append(d.hosts, hostConfig)
+	d.created = append(d.created, platform)
+
+	return d.CommonAPIClient.ContainerCreate(ctx, config, hostConfig, networkingConfig, platform, name)
+}
+
+func TestDocker_RunContainer_Capabilities(t *testing.T) {
+	// setup types
+	_arm64 := *_pipeline
+	_arm64.Worker = pipeline.Worker{Platform: "linux/arm64"}
+
+	// setup tests
+	tests := []struct {
+		name     string
+		failure  bool
+		version  string
+		override bool
+		platform string
+		pipeline *pipeline.Build
+		mounts   bool
+		created  *specs.Platform
+	}{
+		{
+			name:     "minimum daemon",
+			failure:  false,
+			version:  "v1.24",
+			pipeline: _pipeline,
+			mounts:   false,
+			created:  nil,
+		},
+		{
+			name:     "older daemon",
+			failure:  false,
+			version:  "v1.32",
+			platform: "linux/amd64",
+			pipeline: _pipeline,
+			mounts:   true,
+			created:  nil,
+		},
+		{
+			name:     "older daemon with build platform",
+			failure:  true,
+			version:  "v1.30",
+			platform: "linux/amd64",
+			pipeline: &_arm64,
+		},
+		{
+			name:     "maximum daemon",
+			failure:  false,
+			version:  "v1.41",
+			platform: "linux/amd64",
+			pipeline: &_arm64,
+			mounts:   true,
+			created:  &specs.Platform{OS: "linux", Architecture: "arm64"},
+		},
+		{
+			name:     "newer client",
+			failure:  false,
+			version:  "v1.43",
+			platform: "linux/amd64",
+			pipeline: &_arm64,
+			mounts:   true,
+			created:  &specs.Platform{OS: "linux", Architecture: "arm64"},
+		},
+		{
+			name:     "newer client from environment",
+			failure:  true,
+			version:  "v1.43",
+			override: true,
+			pipeline: _pipeline,
+		},
+		{
+			name:     "unsupported daemon",
+			failure:  true,
+			version:  "v1.20",
+			pipeline: _pipeline,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_engine, err := NewMock(
+				WithHostVolumes([]string{"/etc/ssl/certs:/etc/ssl/certs:ro"}),
+				WithPlatform(test.platform),
+			)
+			if err != nil {
+				t.Errorf("unable to create runtime engine: %v", err)
+			}
+
+			_docker := &versionDocker{CommonAPIClient: _engine.Docker, version: test.version, override: test.override}
+			_engine.Docker = _docker
+
+			err = _engine.RunContainer(context.Background(), _container, test.pipeline)
+
+			if test.failure {
+				if err == nil {
+					t.Errorf("RunContainer should have returned err")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("RunContainer returned err: %v", err)
+			}
+
+			if len(_docker.hosts) != 1 {
+				t.Errorf("RunContainer created %d containers, want 1", len(_docker.hosts))
+
+				return
+			}
+
+			hostConf := _docker.hosts[0]
+
+			// check the mounts are converted to binds for the daemon
+			if test.mounts {
+				if len(hostConf.Mounts) != 2 || len(hostConf.Binds) != 0 {
+					t.Errorf("RunContainer mounts is %v and binds is %v, want mounts", hostConf.Mounts, hostConf.Binds)
+				}
+			} else {
+				want := []string{"github_octocat_1:/vela", "/etc/ssl/certs:/etc/ssl/certs:ro"}
+
+				if len(hostConf.Mounts) != 0 || !reflect.DeepEqual(hostConf.Binds, want) {
+					t.Errorf("RunContainer mounts is %v and binds is %v, want binds %v", hostConf.Mounts, hostConf.Binds, want)
+				}
+			}
+
+			if !reflect.DeepEqual(_docker.created[0], test.created) {
+				t.Errorf("RunContainer created %v, want %v", _docker.created[0], test.created)
+			}
+
+			// check the Docker API version doesn't exceed the maximum
+			if versions.GreaterThan(_engine.capabilities.APIVersion, MaxVersion) {
+				t.Errorf("RunContainer used API version %s, want at most %s", _engine.capabilities.APIVersion, MaxVersion)
+			}
+		})
+	}
+}
@@ -47,6 +47,9 @@ func (c *client) InspectContainer(ctx context.Context, ctn *pipeline.Container)
 func (c *client) RemoveContainer(ctx context.Context, ctn *pipeline.Container) error {
 	logrus.Tracef("removing container %s", ctn.ID)
 
+	// release the image held for the container
+	c.releaseImage(ctn.ID)
+
 	// send API call to inspect the container
 	//
 	// https://godoc.org/github.com/docker/docker/client#Client.ContainerInspect
@@ -87,7 +90,13 @@ func (c *client) RemoveContainer(ctx context.Context, ctn *pipeline.Container) e
 		return err
 	}
 
-	return nil
+	// check if any secrets are delivered as files
+	if len(c.config.SecretFiles) == 0 {
+		return nil
+	}
+
+	// remove the volume for the secrets delivered as files
+	return c.removeSecrets(ctx, ctn)
 }
 
 // RunContainer creates and starts the pipeline container.
@@ -96,10 +105,64 @@ func (c *client) RemoveContainer(ctx context.Context, ctn *pipeline.Container) e
 func (c *client) RunContainer(ctx context.Context, ctn *pipeline.Container, b *pipeline.Build) error {
 	logrus.Tracef("running container %s", ctn.ID)
 
+	// hold the image until the container is created
+	c.holdImage(ctn.ID, ctn.Image)
+	defer c.releaseImage(ctn.ID)
+
+	// check if the image is permitted by the image policy
+	//
+	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/image#Policy.Validate
+	err := c.config.Policy.Validate(ctn.Image)
+	if err != nil {
+		return err
+	}
+
+	// verify the signature for the image
+	//
+	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/signature#Verifier.Verify
+	digest, err := c.config.Verifier.Verify(ctx, ctn.Image, c.config.Mirrors)
+	if err != nil {
+		return err
+	}
+
+	// capture the features supported by the daemon
+	caps, err := c.Capabilities(ctx)
+	if err != nil {
+		return err
+	}
+
+	// capture the secrets delivered as files for the container
+	_ctn, files := c.secretFiles(ctn)
+
 	// allocate new container config from pipeline container
-	containerConf := ctnConfig(ctn)
+	containerConf := ctnConfig(_ctn)
+	// add the labels for the build to the container config
+	containerConf.Labels = c.labels(b)
+
+	// check if a proxy is provided for restricted egress
+	if c.config.Network.Egress && c.config.Network.Proxy != nil {
+		// add the environment for the proxy to the container config
+		c.proxyEnv(containerConf, ctn, b)
+	}
+
 	// allocate new host config with volume data
 	hostConf := hostConfig(b.ID, ctn.Ulimits, c.config.Volumes)
+	// add the dns and hosts for the network to the host config
+	//
+	// https://godoc.org/github.com/docker/docker/api/types/container#HostConfig
+	hostConf.DNS = c.config.Network.DNS
+	hostConf.DNSSearch = c.config.Network.DNSSearch
+	hostConf.ExtraHosts = c.config.Network.ExtraHosts
+
+	// check if any secrets are delivered as files
+	if len(files) > 0 {
+		// create the volume for the files and add the mount for it
+		err = c.createSecrets(ctx, ctn, b, hostConf)
+		if err != nil {
+			return err
+		}
+	}
+
 	// allocate new network config with container name
 	networkConf := netConfig(b.ID, ctn.Name)
 
@@ -134,10 +197,28 @@ func (c *client) RunContainer(ctx context.Context, ctn *pipeline.Container, b *p
 	//
 	// -------------------- End of TODO: --------------------
 
+	// check if the daemon supports mounts for containers
+	if !caps.Mounts {
+		// convert the mounts to binds for the host config
+		hostConf.Binds = mountBinds(hostConf.Mounts)
+		hostConf.Mounts = nil
+	}
+
+	// capture the platform for the build
+	platform := c.platform(b)
+
+	// check if the build requires a platform other than the default
+	//
+	// The image created during setup is for the default
+	// platform, so the image for the build platform must
+	// be pulled unless the pull policy prevents it.
+	override := !strings.EqualFold(image.FormatPlatform(platform), image.FormatPlatform(c.config.Platform)) &&
+		!strings.EqualFold(ctn.Pull, constants.PullNever)
+
 	// check if the container pull policy is on_start
-	if strings.EqualFold(ctn.Pull, constants.PullOnStart) {
-		// send API call to create the image
-		err := c.CreateImage(ctx, ctn)
+	if strings.EqualFold(ctn.Pull, constants.PullOnStart) || override {
+		// send API call to create the image for the platform
+		err := c.createImage(ctx, ctn, platform)
 		if err != nil {
 			return err
 		}
@@ -155,21 +236,60 @@ func (c *client) RunContainer(ctx context.Context, ctn *pipeline.Container, b *p
 		}
 	}
 
+	// check if the image is pulled by digest
+	if isDigest(ctn.Image) {
+		// capture the reference for the image on the host
+		containerConf.Image, err = c.digestImage(ctx, ctn)
+		if err != nil {
+			return err
+		}
+	}
+
+	// check if the image signature was verified
+	if len(digest) > 0 {
+		// pin the container to the verified image
+		containerConf.Image, err = c.pinImage(ctx, ctn, digest, platform)
+		if err != nil {
+			return err
+		}
+	}
+
+	// record the image was used for image garbage collection
+	c.touchImage(ctn.Image)
+
+	// check if the daemon supports the platform for creating containers
+	//
+	// The image was already pulled for the platform, so the
+	// container is created from the image for the platform
+	// on a daemon that doesn't support providing it.
+	if !caps.Platform {
+		platform = nil
+	}
+
 	// send API call to create the container
 	//
 	// https://godoc.org/github.com/docker/docker/client#Client.ContainerCreate
-	_, err := c.Docker.ContainerCreate(
+	_, err = c.Docker.ContainerCreate(
 		ctx,
 		containerConf,
 		hostConf,
 		networkConf,
-		nil,
+		platform,
 		ctn.ID,
 	)
 	if err != nil {
 		return err
 	}
 
+	// check if any secrets are delivered as files
+	if len(files) > 0 {
+		// copy the files for the secrets to the container
+		err = c.copySecrets(ctx, ctn, files)
+		if err != nil {
+			return err
+		}
+	}
+
 	// create options for starting container
 	//
 	// https://godoc.org/github.com/docker/docker/api/types#ContainerStartOptions
@@ -190,6 +310,25 @@ func (c *client) RunContainer(ctx context.Context, ctn *pipeline.Container, b *p
 func (c *client) SetupContainer(ctx context.Context, ctn *pipeline.Container) error {
 	logrus.Tracef("setting up for container %s", ctn.ID)
 
+	// hold the image until the container is created
+	c.holdImage(ctn.ID, ctn.Image)
+
+	// check if the image is permitted by the image policy
+	//
+	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/image#Policy.Validate
+	err := c.config.Policy.Validate(ctn.Image)
+	if err != nil {
+		return err
+	}
+
+	// verify the signature for the image
+	//
+	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/signature#Verifier.Verify
+	_, err = c.config.Verifier.Verify(ctx, ctn.Image, c.config.Mirrors)
+	if err != nil {
+		return err
+	}
+
 	// handle the container pull policy
 	switch ctn.Pull {
 	case constants.PullAlways:
@@ -251,6 +390,7 @@ func (c *client) TailContainer(ctx context.Context, ctn *pipeline.Container) (io
 		ShowStderr: true,
 		Details:    false,
 		Timestamps: false,
+		Since:      c.resumeSince(ctn),
 	}
 
 	// send API call to capture the container logs
@@ -283,7 +423,8 @@ func (c *client) TailContainer(ctx context.Context, ctn *pipeline.Container) (io
 		wc.Close()
 	}()
 
-	return rc, nil
+	// apply the log limits to the logs for the container
+	return c.limitLogs(ctn, rc), nil
 }
 
 // WaitContainer blocks until the pipeline container completes.
@@ -6,8 +6,21 @@ package docker
 
 import (
 	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
 	"testing"
 
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	docker "github.com/docker/docker/client"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/go-vela/pkg-runtime/internal/image"
+	"github.com/go-vela/pkg-runtime/internal/signature"
 	"github.com/go-vela/types/pipeline"
 )
 
@@ -387,3 +400,367 @@ func TestDocker_WaitContainer(t *testing.T) {
 		}
 	}
 }
+
+func TestDocker_ImagePolicy(t *testing.T) {
+	// setup Docker
+	_engine, err := NewMock(
+		WithImagePolicy([]string{"target/vela-*"}, []string{"target/vela-docker"}),
+		WithRegistryPolicy(nil, []string{"gcr.io"}),
+	)
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	// setup tests
+	tests := []struct {
+		failure bool
+		image   string
+		rule    string
+	}{
+		{
+			failure: false,
+			image:   "target/vela-git:v0.4.0",
+		},
+		{
+			failure: true,
+			image:   "target/vela-docker:latest",
+			rule:    image.RuleDeniedImage,
+		},
+		{
+			failure: true,
+			image:   "gcr.io/target/vela-git:v0.4.0",
+			rule:    image.RuleDeniedRegistry,
+		},
+		{
+			failure: true,
+			image:   "alpine:latest",
+			rule:    image.RuleNotAllowed,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		ctn := &pipeline.Container{
+			ID:          "step_github_octocat_1_policy",
+			Directory:   "/vela/src/github.com/octocat/helloworld",
+			Environment: map[string]string{"FOO": "bar"},
+			Image:       test.image,
+			Name:        "policy",
+			Number:      2,
+			Pull:        "not_present",
+		}
+
+		errs := map[string]error{
+			"SetupContainer": _engine.SetupContainer(context.Background(), ctn),
+			"RunContainer":   _engine.RunContainer(context.Background(), ctn, _pipeline),
+		}
+
+		for name, err := range errs {
+			if test.failure {
+				var policyErr *image.PolicyError
+				if !errors.As(err, &policyErr) {
+					t.Errorf("%s returned err %v, want PolicyError", name, err)
+
+					continue
+				}
+
+				if policyErr.Rule != test.rule {
+					t.Errorf("%s rule is %s, want %s", name, policyErr.Rule, test.rule)
+				}
+
+				continue
+			}
+
+			if err != nil {
+				t.Errorf("%s returned err: %v", name, err)
+			}
+		}
+	}
+}
+
+func TestDocker_ImageVerification(t *testing.T) {
+	// setup types
+	_registry, err := signature.NewMockRegistry()
+	if err != nil {
+		t.Errorf("unable to create mock registry: %v", err)
+	}
+
+	defer _registry.Close()
+
+	key, err := _registry.PublicKey()
+	if err != nil {
+		t.Errorf("unable to encode public key: %v", err)
+	}
+
+	file := filepath.Join(t.TempDir(), "cosign.pub")
+
+	err = ioutil.WriteFile(file, key, 0600)
+	if err != nil {
+		t.Errorf("unable to write public key: %v", err)
+	}
+
+	err = _registry.Sign("vela/signed", _registry.Push("vela/signed", "v1"), _registry.Key())
+	if err != nil {
+		t.Errorf("unable to sign image: %v", err)
+	}
+
+	_ = _registry.Push("vela/unsigned", "v1")
+
+	// setup Docker
+	_engine, err := NewMock(
+		WithImageVerification([]string{file}, []string{_registry.Host() + "/vela/*"}),
+	)
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	_engine.config.Verifier.Client = _registry.Client()
+
+	// setup tests
+	tests := []struct {
+		failure bool
+		image   string
+		reason  string
+	}{
+		{
+			failure: false,
+			image:   _registry.Host() + "/vela/signed:v1",
+		},
+		{
+			failure: false,
+			image:   "alpine:latest",
+		},
+		{
+			failure: true,
+			image:   _registry.Host() + "/vela/unsigned:v1",
+			reason:  signature.ReasonUnsigned,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		ctn := &pipeline.Container{
+			ID:          "step_github_octocat_1_verify",
+			Directory:   "/vela/src/github.com/octocat/helloworld",
+			Environment: map[string]string{"FOO": "bar"},
+			Image:       test.image,
+			Name:        "verify",
+			Number:      2,
+			Pull:        "not_present",
+		}
+
+		errs := map[string]error{
+			"SetupContainer": _engine.SetupContainer(context.Background(), ctn),
+			"RunContainer":   _engine.RunContainer(context.Background(), ctn, _pipeline),
+		}
+
+		for name, err := range errs {
+			if test.failure {
+				var sigErr *signature.Error
+				if !errors.As(err, &sigErr) {
+					t.Errorf("%s returned err %v, want signature Error", name, err)
+
+					continue
+				}
+
+				if sigErr.Reason != test.reason {
+					t.Errorf("%s reason is %s, want %s", name, sigErr.Reason, test.reason)
+				}
+
+				continue
+			}
+
+			if err != nil {
+				t.Errorf("%s returned err: %v", name, err)
+			}
+		}
+	}
+}
+
+func TestDocker_ImageVerification_Mirror(t *testing.T) {
+	// setup types
+	_registry, err := signature.NewMockRegistry()
+	if err != nil {
+		t.Errorf("unable to create mock registry: %v", err)
+	}
+
+	defer _registry.Close()
+
+	key, err := _registry.PublicKey()
+	if err != nil {
+		t.Errorf("unable to encode public key: %v", err)
+	}
+
+	file := filepath.Join(t.TempDir(), "cosign.pub")
+
+	err = ioutil.WriteFile(file, key, 0600)
+	if err != nil {
+		t.Errorf("unable to write public key: %v", err)
+	}
+
+	digest := _registry.Push("vela/signed", "v1")
+
+	err = _registry.Sign("vela/signed", digest, _registry.Key())
+	if err != nil {
+		t.Errorf("unable to sign image: %v", err)
+	}
+
+	_mirror := "127.0.0.1:1"
+
+	ctn := &pipeline.Container{
+		ID:          "step_github_octocat_1_verify",
+		Directory:   "/vela/src/github.com/octocat/helloworld",
+		Environment: map[string]string{"FOO": "bar"},
+		Image:       _registry.Host() + "/vela/signed:v1",
+		Name:        "verify",
+		Number:      2,
+		Pull:        "not_present",
+	}
+
+	// setup Docker
+	_engine, err := NewMock(
+		WithImageVerification([]string{file}, []string{_registry.Host() + "/vela/*"}),
+		WithRegistryMirrors([]string{_registry.Host() + "=" + _mirror}),
+	)
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	_engine.config.Verifier.Client = _registry.Client()
+
+	_docker := &digestDocker{CommonAPIClient: _engine.Docker}
+	_engine.Docker = _docker
+
+	want := _mirror + "/vela/signed:v1@" + digest
+
+	// run test
+	err = _engine.RunContainer(context.Background(), ctn, _pipeline)
+	if err != nil {
+		t.Errorf("RunContainer returned err: %v", err)
+	}
+
+	if !reflect.DeepEqual(_docker.pulled, []string{want}) {
+		t.Errorf("RunContainer pulled %v, want %s", _docker.pulled, want)
+	}
+
+	if !reflect.DeepEqual(_docker.created, []string{want}) {
+		t.Errorf("RunContainer created %v, want %s", _docker.created, want)
+	}
+}
+
+// platformDocker represents a Docker client
+// recording the platforms for images and containers.
+type platformDocker struct {
+	docker.CommonAPIClient
+
+	pulled  []string
+	created []*specs.Platform
+}
+
+// ClientVersion returns the Docker API version
+// supporting the platform for containers.
+func (d *platformDocker) ClientVersion() string {
+	return MaxVersion
+}
+
+// ImagePull records the platform for the image.
+func (d *platformDocker) ImagePull(ctx context.Context, ref string, opts types.ImagePullOptions) (io.ReadCloser, error) {
+	d.pulled = append(d.pulled, opts.Platform)
+
+	return d.CommonAPIClient.ImagePull(ctx, ref, opts)
+}
+
+// ContainerCreate records the platform for the container.
+//
+// nolint: lll // ignore long line length due to variable names
+func (d *platformDocker) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, name string) (container.ContainerCreateCreatedBody, error) {
+	d.created = append(d.created, platform)
+
+	return d.CommonAPIClient.ContainerCreate(ctx, config, hostConfig, networkingConfig, platform, name)
+}
+
+func TestDocker_RunContainer_Platform(t *testing.T) {
+	// setup types
+	_arm64 := *_pipeline
+	_arm64.Worker = pipeline.Worker{Platform: "linux/arm64"}
+
+	_routed := *_pipeline
+	_routed.Worker = pipeline.Worker{Platform: "docker"}
+
+	_never := *_container
+	_never.Pull = "never"
+
+	// setup tests
+	tests := []struct {
+		name      string
+		platform  string
+		pipeline  *pipeline.Build
+		container *pipeline.Container
+		pulled    []string
+		want      *specs.Platform
+	}{
+		{
+			name:      "no platform",
+			pipeline:  _pipeline,
+			container: _container,
+			pulled:    nil,
+			want:      nil,
+		},
+		{
+			name:      "default platform",
+			platform:  "linux/amd64",
+			pipeline:  _pipeline,
+			container: _container,
+			pulled:    nil,
+			want:      &specs.Platform{OS: "linux", Architecture: "amd64"},
+		},
+		{
+			name:      "build platform",
+			platform:  "linux/amd64",
+			pipeline:  &_arm64,
+			container: _container,
+			pulled:    []string{"linux/arm64"},
+			want:      &specs.Platform{OS: "linux", Architecture: "arm64"},
+		},
+		{
+			name:      "build platform with pull never",
+			platform:  "linux/amd64",
+			pipeline:  &_arm64,
+			container: &_never,
+			pulled:    nil,
+			want:      &specs.Platform{OS: "linux", Architecture: "arm64"},
+		},
+		{
+			name:      "build routing platform",
+			platform:  "linux/amd64",
+			pipeline:  &_routed,
+			container: _container,
+			pulled:    nil,
+			want:      &specs.Platform{OS: "linux", Architecture: "amd64"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := NewMock(WithPlatform(test.platform))
+		if err != nil {
+			t.Errorf("unable to create runtime engine: %v", err)
+		}
+
+		_docker := &platformDocker{CommonAPIClient: _engine.Docker}
+		_engine.Docker = _docker
+
+		err = _engine.RunContainer(context.Background(), test.container, test.pipeline)
+		if err != nil {
+			t.Errorf("RunContainer for %s returned err: %v", test.name, err)
+		}
+
+		if !reflect.DeepEqual(_docker.pulled, test.pulled) {
+			t.Errorf("RunContainer for %s pulled %v, want %v", test.name, _docker.pulled, test.pulled)
+		}
+
+		if len(_docker.created) != 1 || !reflect.DeepEqual(_docker.created[0], test.want) {
+			t.Errorf("RunContainer for %s created %v, want %v", test.name, _docker.created, test.want)
+		}
+	}
+}
@@ -5,39 +5,97 @@
 package docker
 
 import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
 	docker "github.com/docker/docker/client"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/go-vela/pkg-runtime/internal/image"
+	"github.com/go-vela/pkg-runtime/internal/logs"
+	"github.com/go-vela/pkg-runtime/internal/signature"
 
 	mock "github.com/go-vela/mock/docker"
 )
 
-// nolint: godot // ignore comment ending in a list
-//
-// Version represents the supported Docker API version for the mock.
-//
-// The Docker API version is pinned to ensure compatibility between the
-// Docker API and client. The goal is to maintain n-1 compatibility.
+// Version represents the Docker API version for the mock.
 //
-// The maximum supported Docker API version for the client is here:
-//
-// https://docs.docker.com/engine/api/#api-version-matrix
-//
-// For example (use the compatibility matrix above for reference):
-//
-// * the Docker version of v20.10 has a maximum API version of v1.41
-// * to maintain n-1, the API version is pinned to v1.40
+// The Docker API version for the client is negotiated with
+// the daemon within the range from MinVersion to MaxVersion.
 const Version = "v1.40"
 
 type config struct {
+	// specifies the host for the daemon to use for the Docker client
+	Host string
+	// specifies the TLS certificates for the daemon to use for the Docker client
+	TLS *tlsConfig
+	// specifies the timeout for requests to the daemon to use for the Docker client
+	Timeout time.Duration
 	// specifies a list of privileged images to use for the Docker client
 	Images []string
 	// specifies a list of host volumes to use for the Docker client
 	Volumes []string
+	// specifies the image policy to enforce for the Docker client
+	Policy *image.Policy
+	// specifies a map of registries to mirrors to use for the Docker client
+	Mirrors map[string]string
+	// specifies to pull from the origin registry when a mirror fails for the Docker client
+	MirrorFallback bool
+	// specifies the image signature verifier to use for the Docker client
+	Verifier *signature.Verifier
+	// specifies the image garbage collection to use for the Docker client
+	GC *gcConfig
+	// specifies the default platform for images to use for the Docker client
+	Platform *specs.Platform
+	// specifies the limits on the logs for containers to use for the Docker client
+	LogLimit *logs.Limit
+	// specifies the secrets, by name, delivered to containers as files to use for the Docker client
+	SecretFiles []string
+	// specifies the ID of the worker labeled on resources to use for the Docker client
+	WorkerID string
+	// specifies the settings for the network for builds to use for the Docker client
+	Network *networkConfig
+}
+
+// tlsConfig represents the paths to the TLS
+// certificates for connecting to the daemon.
+type tlsConfig struct {
+	// path to the certificate authority for the daemon
+	CA string
+	// path to the certificate for the client
+	Cert string
+	// path to the key for the certificate for the client
+	Key string
 }
 
 type client struct {
 	config *config
 	// https://godoc.org/github.com/docker/docker/client#CommonAPIClient
 	Docker docker.CommonAPIClient
+
+	// mutex guards the internal state for the Docker client
+	mutex sync.Mutex
+	// used tracks the last time each image was used by the Docker client
+	used map[string]time.Time
+	// inflight tracks the images held for each container until it is created by the Docker client
+	inflight map[string][]string
+	// resume tracks the time to resume tailing the logs for containers reattached by the Docker client
+	resume map[string]time.Time
+	// capabilities tracks the Docker API version negotiated with the daemon by the Docker client
+	capabilities *Capabilities
+	// negotiate serializes negotiating the Docker API version with the daemon for the Docker client
+	negotiate sync.Mutex
+	// subnets tracks the subnets allocated to the network for each build by the Docker client
+	subnets map[string][]*net.IPNet
+	// started tracks the time the Docker client was created
+	started time.Time
+	// collected tracks the last time unused images were removed after a build by the Docker client
+	collected time.Time
 }
 
 // New returns an Engine implementation that
@@ -50,6 +108,20 @@ func New(opts ...ClientOpt) (*client, error) {
 
 	// create new fields
 	c.config = new(config)
+	c.config.Policy = new(image.Policy)
+	c.config.GC = &gcConfig{Interval: defaultGCInterval}
+	c.config.LogLimit = new(logs.Limit)
+	c.config.Network = &networkConfig{Driver: "bridge"}
+	c.used = make(map[string]time.Time)
+	c.inflight = make(map[string][]string)
+	c.resume = make(map[string]time.Time)
+	c.subnets = make(map[string][]*net.IPNet)
+	c.started = time.Now()
+
+	// use the hostname as the default worker ID
+	//
+	// https://pkg.go.dev/os#Hostname
+	c.config.WorkerID, _ = os.Hostname()
 
 	// apply all provided configuration options
 	for _, opt := range opts {
@@ -59,28 +131,160 @@ func New(opts ...ClientOpt) (*client, error) {
 		}
 	}
 
+	// capture the options for connecting to the daemon
+	dockerOpts, err := c.dockerOpts()
+	if err != nil {
+		return nil, err
+	}
+
 	// create new Docker client from environment
+	// and the options for connecting to the daemon
 	//
 	// https://godoc.org/github.com/docker/docker/client#NewClientWithOpts
-	_docker, err := docker.NewClientWithOpts(docker.FromEnv)
+	_docker, err := docker.NewClientWithOpts(dockerOpts...)
 	if err != nil {
 		return nil, err
 	}
 
-	// pin version to ensure we know what Docker API version we're using
-	//
-	// typically this would be inherited from the host environment
-	// but this ensures the version of client being used
-	//
-	// https://godoc.org/github.com/docker/docker/client#WithVersion
-	_ = docker.WithVersion(Version)(_docker)
-
 	// set the Docker client in the runtime client
 	c.Docker = _docker
 
 	return c, nil
 }
 
+// dockerOpts is a helper function to create the options
+// for the Docker client connecting to the daemon.
+//
+// The environment (DOCKER_HOST, DOCKER_CERT_PATH, etc.)
+// is used first, so any host, TLS certificates or timeout
+// provided to the runtime client take precedence over it.
+func (c *client) dockerOpts() ([]docker.Opt, error) {
+	// negotiate the Docker API version with the daemon
+	//
+	// https://godoc.org/github.com/docker/docker/client#FromEnv
+	// https://godoc.org/github.com/docker/docker/client#WithAPIVersionNegotiation
+	opts := []docker.Opt{docker.FromEnv, docker.WithAPIVersionNegotiation()}
+
+	// check if a host for the daemon is provided
+	if len(c.config.Host) > 0 {
+		// check if the host for the daemon is ssh://
+		if isSSH(c.config.Host) {
+			dialer, err := sshDialer(c.config.Host)
+			if err != nil {
+				return nil, err
+			}
+
+			// the dialer replaces the connection for the host
+			//
+			// https://godoc.org/github.com/docker/docker/client#WithDialContext
+			opts = append(opts, docker.WithHost(sshHost), docker.WithDialContext(dialer))
+		} else {
+			// https://godoc.org/github.com/docker/docker/client#WithHost
+			opts = append(opts, docker.WithHost(c.config.Host))
+		}
+	}
+
+	// check if the TLS certificates for the daemon are provided
+	if c.config.TLS != nil {
+		// check if the host for the daemon is ssh://
+		if isSSH(c.config.Host) {
+			return nil, fmt.Errorf("tls not supported for ssh host %s", c.config.Host)
+		}
+
+		// https://godoc.org/github.com/docker/docker/client#WithTLSClientConfig
+		opts = append(opts, docker.WithTLSClientConfig(c.config.TLS.CA, c.config.TLS.Cert, c.config.TLS.Key))
+	}
+
+	// check if a timeout for requests to the daemon is provided
+	if c.config.Timeout > 0 {
+		// the timeout is applied to the transport after the
+		// other options, since they configure the transport
+		opts = append(opts, withTransportTimeout(c.config.Timeout))
+	}
+
+	return opts, nil
+}
+
+// withTransportTimeout is a helper function to limit the time
+// for connecting to the daemon and for the daemon to respond
+// to each request with the headers for the response.
+//
+// Unlike a timeout for the whole request, the body for the
+// response isn't limited, so streams from the daemon, like
+// following logs or waiting for containers, aren't cut off.
+func withTransportTimeout(timeout time.Duration) docker.Opt {
+	return func(c *docker.Client) error {
+		// capture the transport shared with the client
+		//
+		// https://godoc.org/github.com/docker/docker/client#Client.HTTPClient
+		transport, ok := c.HTTPClient().Transport.(*http.Transport)
+		if !ok {
+			return fmt.Errorf("unable to apply timeout to transport: %T", c.HTTPClient().Transport)
+		}
+
+		dial := transport.DialContext
+
+		// check if the transport only has a dialer without a context
+		//
+		// https://pkg.go.dev/net/http#Transport.Dial
+		if dial == nil && transport.Dial != nil {
+			dial = dialContext(transport.Dial)
+		}
+
+		// check if the transport has no dialer
+		if dial == nil {
+			dial = new(net.Dialer).DialContext
+		}
+
+		// https://pkg.go.dev/net/http#Transport
+		transport.Dial = nil
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			return dial(ctx, network, addr)
+		}
+		transport.TLSHandshakeTimeout = timeout
+		transport.ResponseHeaderTimeout = timeout
+
+		return nil
+	}
+}
+
+// dialContext is a helper function to create a dialer
+// with a context from a dialer without one, returning
+// when the context is done before the dial completes.
+func dialContext(dial func(string, string) (net.Conn, error)) func(context.Context, string, string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		type result struct {
+			conn net.Conn
+			err  error
+		}
+
+		done := make(chan result, 1)
+
+		go func() {
+			conn, err := dial(network, addr)
+
+			done <- result{conn: conn, err: err}
+		}()
+
+		select {
+		case r := <-done:
+			return r.conn, r.err
+		case <-ctx.Done():
+			// close the connection if the dial completes after the context
+			go func() {
+				if r := <-done; r.conn != nil {
+					r.conn.Close()
+				}
+			}()
+
+			return nil, ctx.Err()
+		}
+	}
+}
+
 // NewMock returns an Engine implementation that
 // integrates with a mock Docker runtime.
 //
@@ -5,7 +5,16 @@
 package docker
 
 import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
 
 	"github.com/go-vela/types/pipeline"
 
@@ -57,6 +66,95 @@ func TestDocker_New(t *testing.T) {
 	}
 }
 
+// streamDaemon is a helper function to create a stand-in daemon
+// sending the headers for following logs and waiting for containers
+// right away, and the body for them after the delay provided, like
+// the daemon does. Inspecting containers waits for the delay before
+// sending the headers.
+func streamDaemon(t *testing.T, delay time.Duration) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/_ping"):
+			w.Header().Set("API-Version", MaxVersion)
+			w.Header().Set("OSType", "linux")
+
+			_, _ = w.Write([]byte("OK"))
+		case strings.HasSuffix(r.URL.Path, "/logs"), strings.HasSuffix(r.URL.Path, "/wait"):
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+
+			time.Sleep(delay)
+
+			// check if the request is for following logs
+			if strings.HasSuffix(r.URL.Path, "/logs") {
+				_, _ = w.Write([]byte("hello\n"))
+
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"StatusCode":0}`))
+		case strings.HasSuffix(r.URL.Path, "/json"):
+			time.Sleep(delay)
+
+			_, _ = w.Write([]byte(`{"Id":"1"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestDocker_New_Timeout(t *testing.T) {
+	// setup types
+	timeout := 100 * time.Millisecond
+
+	server := streamDaemon(t, 3*timeout)
+	defer server.Close()
+
+	_engine, err := New(
+		WithHost(strings.Replace(server.URL, "http://", "tcp://", 1)),
+		WithTimeout(timeout),
+	)
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	// run tests
+	logs, err := _engine.Docker.ContainerLogs(context.Background(), "1", types.ContainerLogsOptions{
+		Follow:     true,
+		ShowStdout: true,
+	})
+	if err != nil {
+		t.Errorf("ContainerLogs returned err: %v", err)
+	} else {
+		got, err := ioutil.ReadAll(logs)
+		logs.Close()
+
+		// check the stream isn't cut off at the timeout
+		if err != nil || string(got) != "hello\n" {
+			t.Errorf("ContainerLogs is %q with err %v, want %q", got, err, "hello\n")
+		}
+	}
+
+	wait, errs := _engine.Docker.ContainerWait(context.Background(), "1", container.WaitConditionNotRunning)
+
+	select {
+	case body := <-wait:
+		if body.StatusCode != 0 {
+			t.Errorf("ContainerWait status is %d, want 0", body.StatusCode)
+		}
+	case err := <-errs:
+		t.Errorf("ContainerWait returned err: %v", err)
+	}
+
+	// check the timeout applies to the headers for the response
+	_, err = _engine.Docker.ContainerInspect(context.Background(), "1")
+	if err == nil {
+		t.Errorf("ContainerInspect should have returned err")
+	}
+}
+
 // setup global variables used for testing.
 var (
 	_container = &pipeline.Container{
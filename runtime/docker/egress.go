@@ -0,0 +1,147 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+
+	"github.com/go-vela/types/pipeline"
+
+	"github.com/sirupsen/logrus"
+)
+
+// egressProxy represents the container running the
+// proxy connected to the network for builds, which
+// is responsible for allow-listing the destinations
+// containers are able to reach.
+type egressProxy struct {
+	// name or ID of the container running the proxy
+	Container string
+	// port the proxy is listening on
+	Port int
+}
+
+// parseProxy is a helper function to digest the proxy,
+// in the form <container>:<port>, into the container
+// and port for the proxy.
+func parseProxy(proxy string) (*egressProxy, error) {
+	// https://pkg.go.dev/net#SplitHostPort
+	name, port, err := net.SplitHostPort(proxy)
+	if err != nil || len(name) == 0 {
+		return nil, fmt.Errorf("invalid egress proxy provided: %s", proxy)
+	}
+
+	p, err := strconv.Atoi(port)
+	if err != nil || p < 1 || p > 65535 {
+		return nil, fmt.Errorf("invalid egress proxy provided: %s (invalid port %s)", proxy, port)
+	}
+
+	return &egressProxy{Container: name, Port: p}, nil
+}
+
+// URL returns the address containers use for the proxy.
+func (p *egressProxy) URL() string {
+	return fmt.Sprintf("http://%s:%d", p.Container, p.Port)
+}
+
+// connectProxy is a helper function to connect the
+// container running the proxy to the network for the
+// build, so the proxy is reachable from the network
+// that is otherwise restricted from external access.
+func (c *client) connectProxy(ctx context.Context, b *pipeline.Build) error {
+	proxy := c.config.Network.Proxy
+
+	logrus.Tracef("connecting egress proxy %s to network for pipeline %s", proxy.Container, b.ID)
+
+	// send API call to connect the proxy to the network
+	//
+	// https://godoc.org/github.com/docker/docker/client#Client.NetworkConnect
+	err := c.Docker.NetworkConnect(ctx, b.ID, proxy.Container, &network.EndpointSettings{})
+	if err != nil {
+		return fmt.Errorf("unable to connect egress proxy %s to network %s: %w", proxy.Container, b.ID, err)
+	}
+
+	return nil
+}
+
+// disconnectProxy is a helper function to disconnect
+// the container running the proxy from the network,
+// so the network can be removed.
+//
+// Failing to disconnect the proxy is only logged,
+// because the proxy might never have been connected,
+// and removing the network reports any real failure.
+func (c *client) disconnectProxy(ctx context.Context, id string) {
+	proxy := c.config.Network.Proxy
+
+	logrus.Tracef("disconnecting egress proxy %s from network %s", proxy.Container, id)
+
+	// send API call to disconnect the proxy from the network
+	//
+	// https://godoc.org/github.com/docker/docker/client#Client.NetworkDisconnect
+	err := c.Docker.NetworkDisconnect(ctx, id, proxy.Container, true)
+	if err != nil {
+		logrus.Debugf("unable to disconnect egress proxy %s from network %s: %v", proxy.Container, id, err)
+	}
+}
+
+// proxyEnv is a helper function to add the environment
+// for using the proxy to the config for a container.
+//
+// Any environment for the proxy already provided
+// for the container is not replaced.
+func (c *client) proxyEnv(config *container.Config, ctn *pipeline.Container, b *pipeline.Build) {
+	// addresses on the network for the build not using the proxy
+	noProxy := append([]string{"localhost", "127.0.0.1"}, containerNames(b)...)
+
+	env := map[string]string{
+		"HTTP_PROXY":  c.config.Network.Proxy.URL(),
+		"HTTPS_PROXY": c.config.Network.Proxy.URL(),
+		"NO_PROXY":    strings.Join(noProxy, ","),
+	}
+
+	for _, key := range []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY"} {
+		// add the upper and lower case variables, because
+		// tools are inconsistent about which are used
+		for _, k := range []string{key, strings.ToLower(key)} {
+			// check if the variable is provided for the container
+			if _, ok := ctn.Environment[k]; ok {
+				continue
+			}
+
+			config.Env = append(config.Env, fmt.Sprintf("%s=%s", k, env[key]))
+		}
+	}
+}
+
+// containerNames is a helper function to capture the names
+// for all of the pipeline containers in the build, which
+// are the aliases for the containers on the network.
+func containerNames(b *pipeline.Build) []string {
+	names := []string{}
+
+	for _, ctn := range b.Services {
+		names = append(names, ctn.Name)
+	}
+
+	for _, stage := range b.Stages {
+		for _, ctn := range stage.Steps {
+			names = append(names, ctn.Name)
+		}
+	}
+
+	for _, ctn := range b.Steps {
+		names = append(names, ctn.Name)
+	}
+
+	return names
+}
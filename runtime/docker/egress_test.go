@@ -0,0 +1,245 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	docker "github.com/docker/docker/client"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/go-vela/types/pipeline"
+)
+
+func TestDocker_parseProxy(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		proxy   string
+		want    *egressProxy
+	}{
+		{
+			failure: false,
+			proxy:   "vela-proxy:3128",
+			want:    &egressProxy{Container: "vela-proxy", Port: 3128},
+		},
+		{
+			failure: true,
+			proxy:   "vela-proxy",
+		},
+		{
+			failure: true,
+			proxy:   ":3128",
+		},
+		{
+			failure: true,
+			proxy:   "vela-proxy:squid",
+		},
+		{
+			failure: true,
+			proxy:   "vela-proxy:70000",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got, err := parseProxy(test.proxy)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("parseProxy for %s should have returned err", test.proxy)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("parseProxy for %s returned err: %v", test.proxy, err)
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseProxy for %s is %+v, want %+v", test.proxy, got, test.want)
+		}
+	}
+}
+
+// egressDocker represents a Docker client recording
+// the networks and containers for restricted egress.
+type egressDocker struct {
+	docker.CommonAPIClient
+
+	created      map[string]types.NetworkCreate
+	connected    []string
+	disconnected []string
+	env          []string
+}
+
+// NetworkCreate records the options for the network.
+func (d *egressDocker) NetworkCreate(ctx context.Context, name string, opts types.NetworkCreate) (types.NetworkCreateResponse, error) {
+	d.created[name] = opts
+
+	return d.CommonAPIClient.NetworkCreate(ctx, name, opts)
+}
+
+// NetworkConnect records the container connected to the network.
+func (d *egressDocker) NetworkConnect(ctx context.Context, network, container string, config *network.EndpointSettings) error {
+	d.connected = append(d.connected, container+"@"+network)
+
+	return d.CommonAPIClient.NetworkConnect(ctx, network, container, config)
+}
+
+// NetworkDisconnect records the container disconnected from the network.
+func (d *egressDocker) NetworkDisconnect(ctx context.Context, network, container string, force bool) error {
+	d.disconnected = append(d.disconnected, container+"@"+network)
+
+	return d.CommonAPIClient.NetworkDisconnect(ctx, network, container, force)
+}
+
+// ContainerCreate records the environment for the container.
+//
+// nolint: lll // ignore long line length due to variable names
+func (d *egressDocker) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, name string) (container.ContainerCreateCreatedBody, error) {
+	d.env = config.Env
+
+	return d.CommonAPIClient.ContainerCreate(ctx, config, hostConfig, networkingConfig, platform, name)
+}
+
+func TestDocker_EgressPolicy(t *testing.T) {
+	// setup types
+	_proxied := *_container
+	_proxied.Environment = map[string]string{"NO_PROXY": "registry.company.com"}
+
+	// setup tests
+	tests := []struct {
+		name      string
+		proxy     string
+		container *pipeline.Container
+		connected []string
+		env       []string
+	}{
+		{
+			name:      "internal",
+			container: _container,
+			connected: nil,
+			env:       []string{"FOO=bar"},
+		},
+		{
+			name:      "proxy",
+			proxy:     "vela-proxy:3128",
+			container: _container,
+			connected: []string{"vela-proxy@github_octocat_1"},
+			env: []string{
+				"FOO=bar",
+				"HTTP_PROXY=http://vela-proxy:3128",
+				"http_proxy=http://vela-proxy:3128",
+				"HTTPS_PROXY=http://vela-proxy:3128",
+				"https_proxy=http://vela-proxy:3128",
+				"NO_PROXY=localhost,127.0.0.1,postgres,init,clone,echo",
+				"no_proxy=localhost,127.0.0.1,postgres,init,clone,echo",
+			},
+		},
+		{
+			name:      "proxy with environment",
+			proxy:     "vela-proxy:3128",
+			container: &_proxied,
+			connected: []string{"vela-proxy@github_octocat_1"},
+			env: []string{
+				"NO_PROXY=registry.company.com",
+				"HTTP_PROXY=http://vela-proxy:3128",
+				"http_proxy=http://vela-proxy:3128",
+				"HTTPS_PROXY=http://vela-proxy:3128",
+				"https_proxy=http://vela-proxy:3128",
+				"no_proxy=localhost,127.0.0.1,postgres,init,clone,echo",
+			},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_engine, err := NewMock(WithEgressPolicy(true, test.proxy))
+			if err != nil {
+				t.Errorf("unable to create runtime engine: %v", err)
+			}
+
+			_docker := &egressDocker{
+				CommonAPIClient: _engine.Docker,
+				created:         make(map[string]types.NetworkCreate),
+			}
+
+			_engine.Docker = _docker
+
+			err = _engine.CreateNetwork(context.Background(), _pipeline)
+			if err != nil {
+				t.Errorf("CreateNetwork returned err: %v", err)
+			}
+
+			// check the network is restricted from external access
+			if !_docker.created[_pipeline.ID].Internal {
+				t.Errorf("CreateNetwork is %+v, want internal", _docker.created[_pipeline.ID])
+			}
+
+			if !reflect.DeepEqual(_docker.connected, test.connected) {
+				t.Errorf("CreateNetwork connected %v, want %v", _docker.connected, test.connected)
+			}
+
+			err = _engine.RunContainer(context.Background(), test.container, _pipeline)
+			if err != nil {
+				t.Errorf("RunContainer returned err: %v", err)
+			}
+
+			// the order of the environment from the container is random
+			if !sameEnv(_docker.env, test.env) {
+				t.Errorf("RunContainer environment is %v, want %v", _docker.env, test.env)
+			}
+
+			err = _engine.RemoveNetwork(context.Background(), _pipeline)
+			if err != nil {
+				t.Errorf("RemoveNetwork returned err: %v", err)
+			}
+
+			if !reflect.DeepEqual(_docker.disconnected, test.connected) {
+				t.Errorf("RemoveNetwork disconnected %v, want %v", _docker.disconnected, test.connected)
+			}
+		})
+	}
+}
+
+// sameEnv is a helper function to check if
+// the environments contain the same variables.
+func sameEnv(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+
+	vars := make(map[string]bool)
+	for _, v := range got {
+		vars[v] = true
+	}
+
+	for _, v := range want {
+		if !vars[v] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestDocker_containerNames(t *testing.T) {
+	// run test
+	got := strings.Join(containerNames(_pipeline), ",")
+
+	want := "postgres,init,clone,echo"
+	if got != want {
+		t.Errorf("containerNames is %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,310 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/go-vela/pkg-runtime/internal/image"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultGCInterval represents the default minimum
+// duration between removing unused images after builds.
+const defaultGCInterval = 5 * time.Minute
+
+// gcConfig represents the configuration for
+// removing unused images from the host.
+type gcConfig struct {
+	// specifies the minimum duration between removing images after builds
+	Interval time.Duration
+	// specifies the duration an image can go unused before it is removed
+	MaxAge time.Duration
+	// specifies the disk usage in bytes of images that triggers removal
+	HighWater int64
+	// specifies the disk usage in bytes of images to reduce to during removal
+	LowWater int64
+	// specifies a list of image patterns that are never removed
+	Protected []string
+}
+
+// ImageGCReport represents the result of
+// removing unused images from the host.
+type ImageGCReport struct {
+	// specifies the list of images removed from the host
+	Images []string
+	// specifies the disk space in bytes reclaimed from the host
+	Reclaimed int64
+	// specifies the disk usage in bytes of images before removal
+	Before int64
+	// specifies the disk usage in bytes of images after removal
+	After int64
+}
+
+// enabled returns true if any thresholds
+// are configured for removing images.
+func (g *gcConfig) enabled() bool {
+	return g != nil && (g.MaxAge > 0 || g.HighWater > 0)
+}
+
+// collectDue is a helper function to check if the interval
+// has passed since images were last removed after a build,
+// recording the time images are removed when it has.
+func (c *client) collectDue() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+
+	// check if images were removed within the interval
+	if !c.collected.IsZero() && now.Sub(c.collected) < c.config.GC.Interval {
+		return false
+	}
+
+	c.collected = now
+
+	return true
+}
+
+// CollectImages removes unused images from the host.
+//
+// Images are removed when they have gone unused for longer than
+// the configured max age, or in least recently used order when
+// the disk usage of images exceeds the configured high water mark
+// until it is below the configured low water mark. Images in use by
+// a container, held for a container that hasn't been created yet or
+// matching a protected pattern are never removed.
+//
+// The last use for images is only recorded in memory, so images
+// not used since the client was created are treated as last used
+// when the client was created, or when the image was created if
+// that is more recent.
+func (c *client) CollectImages(ctx context.Context) (*ImageGCReport, error) {
+	logrus.Trace("collecting unused images")
+
+	report := new(ImageGCReport)
+
+	// check if image garbage collection is enabled
+	if !c.config.GC.enabled() {
+		return report, nil
+	}
+
+	// send API call to capture the disk usage for the host
+	//
+	// https://godoc.org/github.com/docker/docker/client#Client.DiskUsage
+	usage, err := c.Docker.DiskUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Before = usage.LayersSize
+	report.After = usage.LayersSize
+
+	// capture the images eligible for removal
+	candidates := c.gcCandidates(usage.Images)
+
+	// sort the images in least recently used order
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].used.Before(candidates[j].used)
+	})
+
+	// capture the disk usage to reduce to during removal
+	lowWater := c.config.GC.LowWater
+	if lowWater <= 0 || lowWater > c.config.GC.HighWater {
+		lowWater = c.config.GC.HighWater
+	}
+
+	// check if the disk usage exceeds the high water mark
+	evict := c.config.GC.HighWater > 0 && usage.LayersSize > c.config.GC.HighWater
+
+	for _, candidate := range candidates {
+		expired := c.config.GC.MaxAge > 0 && time.Since(candidate.used) > c.config.GC.MaxAge
+
+		// check if the image should be removed
+		if !expired && !(evict && report.After > lowWater) {
+			continue
+		}
+
+		// create options for removing the image
+		//
+		// https://godoc.org/github.com/docker/docker/api/types#ImageRemoveOptions
+		opts := types.ImageRemoveOptions{
+			Force:         false,
+			PruneChildren: true,
+		}
+
+		logrus.Debugf("removing unused image %s", candidate.name)
+
+		// send API call to remove the image
+		//
+		// https://godoc.org/github.com/docker/docker/client#Client.ImageRemove
+		_, err := c.Docker.ImageRemove(ctx, candidate.summary.ID, opts)
+		if err != nil {
+			logrus.Errorf("unable to remove image %s: %v", candidate.name, err)
+
+			continue
+		}
+
+		// capture the disk space only used by the image
+		reclaimed := candidate.summary.Size
+		if candidate.summary.SharedSize > 0 {
+			reclaimed -= candidate.summary.SharedSize
+		}
+
+		report.Images = append(report.Images, candidate.name)
+		report.Reclaimed += reclaimed
+		report.After -= reclaimed
+
+		c.forgetImage(candidate.summary)
+	}
+
+	logrus.Infof("removed %d unused images reclaiming %d bytes", len(report.Images), report.Reclaimed)
+
+	return report, nil
+}
+
+// gcCandidate represents an image eligible for removal.
+type gcCandidate struct {
+	name    string
+	used    time.Time
+	summary *types.ImageSummary
+}
+
+// gcCandidates is a helper function to capture the
+// images eligible for removal from the host.
+func (c *client) gcCandidates(images []*types.ImageSummary) []gcCandidate {
+	candidates := []gcCandidate{}
+
+	// capture the patterns for images that are never removed
+	protected := append([]string{}, c.config.GC.Protected...)
+	protected = append(protected, c.config.Images...)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// capture the images held for containers
+	held := make(map[string]bool)
+
+	for _, refs := range c.inflight {
+		for _, ref := range refs {
+			held[ref] = true
+		}
+	}
+
+image:
+	for _, summary := range images {
+		// skip images in use by a container
+		if summary.Containers > 0 {
+			continue
+		}
+
+		// skip images held for a container
+		for _, ref := range append(append([]string{}, summary.RepoTags...), summary.RepoDigests...) {
+			if held[heldRef(ref)] {
+				continue image
+			}
+		}
+
+		candidate := gcCandidate{
+			name:    summary.ID,
+			used:    c.started,
+			summary: summary,
+		}
+
+		// check if the image was created after the client
+		created := time.Unix(summary.Created, 0)
+		if created.After(candidate.used) {
+			candidate.used = created
+		}
+
+		for _, tag := range summary.RepoTags {
+			// skip untagged images
+			if tag == "<none>:<none>" {
+				continue
+			}
+
+			// skip images matching a protected pattern
+			for _, pattern := range protected {
+				match, err := image.IsPrivilegedImage(tag, pattern)
+				if err == nil && match {
+					continue image
+				}
+			}
+
+			candidate.name = tag
+
+			// capture the last time the image was used by the runtime
+			used, ok := c.used[image.Parse(tag)]
+			if ok && used.After(candidate.used) {
+				candidate.used = used
+			}
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates
+}
+
+// touchImage is a helper function to record
+// the last time the image was used.
+func (c *client) touchImage(_image string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.used[image.Parse(_image)] = time.Now()
+}
+
+// forgetImage is a helper function to remove
+// the record for the last time the image was used.
+func (c *client) forgetImage(summary *types.ImageSummary) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, tag := range summary.RepoTags {
+		delete(c.used, image.Parse(tag))
+	}
+}
+
+// holdImage is a helper function to record the image
+// is held for the container until it is created, so
+// image garbage collection doesn't remove it after
+// it is pulled for the container.
+func (c *client) holdImage(id, _image string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.inflight[id] = append(c.inflight[id], heldRef(_image))
+}
+
+// releaseImage is a helper function to remove
+// the record for all images held for the container.
+func (c *client) releaseImage(id string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.inflight, id)
+}
+
+// heldRef is a helper function to convert the image
+// reference to the form used for recording held images.
+// The tag is trimmed from a reference with a digest to
+// match the digest references for the image.
+func heldRef(ref string) string {
+	ref = image.Parse(ref)
+
+	// check if the reference contains a digest
+	if i := strings.Index(ref, "@"); i > 0 {
+		return repository(ref) + ref[i:]
+	}
+
+	return ref
+}
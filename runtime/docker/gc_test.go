@@ -0,0 +1,290 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	docker "github.com/docker/docker/client"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// gcDocker represents a Docker client with
+// a fixed set of images for testing removal.
+type gcDocker struct {
+	docker.CommonAPIClient
+
+	mutex   sync.Mutex
+	images  []*types.ImageSummary
+	removed []string
+	// number of times the disk usage was captured
+	usage int
+	// images removed before creating a container
+	created []string
+}
+
+// DiskUsage returns the fixed set of images.
+func (d *gcDocker) DiskUsage(ctx context.Context) (types.DiskUsage, error) {
+	d.mutex.Lock()
+	d.usage++
+	d.mutex.Unlock()
+
+	usage := types.DiskUsage{}
+
+	for _, summary := range d.images {
+		usage.LayersSize += summary.Size
+		usage.Images = append(usage.Images, summary)
+	}
+
+	return usage, nil
+}
+
+// ImageRemove records the image removed.
+func (d *gcDocker) ImageRemove(ctx context.Context, id string, opts types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.removed = append(d.removed, id)
+
+	return []types.ImageDeleteResponseItem{{Deleted: id}}, nil
+}
+
+// ContainerCreate records the images removed before creating the container.
+//
+// nolint: lll // ignore long line length due to variable names
+func (d *gcDocker) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, name string) (container.ContainerCreateCreatedBody, error) {
+	d.mutex.Lock()
+	d.created = append([]string{}, d.removed...)
+	d.mutex.Unlock()
+
+	return d.CommonAPIClient.ContainerCreate(ctx, config, hostConfig, networkingConfig, platform, name)
+}
+
+func TestDocker_CollectImages(t *testing.T) {
+	// setup types
+	old := time.Now().Add(-48 * time.Hour).Unix()
+	recent := time.Now().Add(-1 * time.Hour).Unix()
+	started := time.Now().Add(-72 * time.Hour)
+
+	images := func() []*types.ImageSummary {
+		return []*types.ImageSummary{
+			{ID: "sha256:1", RepoTags: []string{"alpine:latest"}, Created: old, Size: 10},
+			{ID: "sha256:2", RepoTags: []string{"golang:latest"}, Created: recent, Size: 30},
+			{ID: "sha256:3", RepoTags: []string{"target/vela-git:v0.4.0"}, Created: old, Size: 10},
+			{ID: "sha256:4", RepoTags: []string{"node:latest"}, Created: old, Size: 20, Containers: 1},
+			{ID: "sha256:5", RepoTags: []string{"ubuntu:latest"}, Created: old, Size: 20, SharedSize: 5},
+		}
+	}
+
+	// setup tests
+	tests := []struct {
+		name    string
+		gc      *gcConfig
+		started time.Time
+		touched []string
+		want    []string
+		report  *ImageGCReport
+	}{
+		{
+			name:    "disabled",
+			gc:      &gcConfig{},
+			started: started,
+			want:    nil,
+			report:  &ImageGCReport{},
+		},
+		{
+			name:    "max age",
+			gc:      &gcConfig{MaxAge: 24 * time.Hour, Protected: []string{"target/vela-git"}},
+			started: started,
+			want:    []string{"sha256:1", "sha256:5"},
+			report:  &ImageGCReport{Images: []string{"alpine:latest", "ubuntu:latest"}, Reclaimed: 25, Before: 90, After: 65},
+		},
+		{
+			name:    "max age with recent use",
+			gc:      &gcConfig{MaxAge: 24 * time.Hour, Protected: []string{"target/vela-git", "ubuntu"}},
+			started: started,
+			touched: []string{"alpine:latest"},
+			want:    nil,
+			report:  &ImageGCReport{Before: 90, After: 90},
+		},
+		{
+			name:    "high water",
+			gc:      &gcConfig{HighWater: 80, LowWater: 70},
+			started: started,
+			touched: []string{"alpine:latest"},
+			want:    []string{"sha256:3", "sha256:5"},
+			report:  &ImageGCReport{Images: []string{"target/vela-git:v0.4.0", "ubuntu:latest"}, Reclaimed: 25, Before: 90, After: 65},
+		},
+		{
+			name:    "below high water",
+			gc:      &gcConfig{HighWater: 100},
+			started: started,
+			want:    nil,
+			report:  &ImageGCReport{Before: 90, After: 90},
+		},
+		{
+			name:    "max age after restart",
+			gc:      &gcConfig{MaxAge: 24 * time.Hour},
+			started: time.Now(),
+			want:    nil,
+			report:  &ImageGCReport{Before: 90, After: 90},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_docker := &gcDocker{images: images()}
+
+		_engine, err := NewMock()
+		if err != nil {
+			t.Errorf("unable to create runtime engine: %v", err)
+		}
+
+		_engine.config.GC = test.gc
+		_engine.started = test.started
+		_engine.Docker = _docker
+
+		for _, _image := range test.touched {
+			_engine.touchImage(_image)
+		}
+
+		got, err := _engine.CollectImages(context.Background())
+		if err != nil {
+			t.Errorf("CollectImages for %s returned err: %v", test.name, err)
+		}
+
+		if !reflect.DeepEqual(_docker.removed, test.want) {
+			t.Errorf("CollectImages for %s removed %v, want %v", test.name, _docker.removed, test.want)
+		}
+
+		if !reflect.DeepEqual(got, test.report) {
+			t.Errorf("CollectImages for %s is %+v, want %+v", test.name, got, test.report)
+		}
+	}
+}
+
+func TestDocker_CollectImages_InFlight(t *testing.T) {
+	// setup types
+	old := time.Now().Add(-48 * time.Hour).Unix()
+
+	_docker := &gcDocker{
+		images: []*types.ImageSummary{
+			{ID: "sha256:1", RepoTags: []string{"alpine:latest"}, Created: old, Size: 10},
+			{ID: "sha256:3", RepoTags: []string{"target/vela-git:v0.4.0"}, Created: old, Size: 10},
+		},
+	}
+
+	_engine, err := NewMock()
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	_engine.config.GC = &gcConfig{MaxAge: time.Nanosecond}
+
+	_docker.CommonAPIClient = _engine.Docker
+	_engine.Docker = _docker
+
+	// run test
+	err = _engine.SetupContainer(context.Background(), _container)
+	if err != nil {
+		t.Errorf("SetupContainer returned err: %v", err)
+	}
+
+	// collect images while the image is held for the container
+	_, err = _engine.CollectImages(context.Background())
+	if err != nil {
+		t.Errorf("CollectImages returned err: %v", err)
+	}
+
+	wg := sync.WaitGroup{}
+
+	// collect images while the container is created
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := _engine.CollectImages(context.Background())
+			if err != nil {
+				t.Errorf("CollectImages returned err: %v", err)
+			}
+		}()
+	}
+
+	err = _engine.RunContainer(context.Background(), _container, _pipeline)
+	if err != nil {
+		t.Errorf("RunContainer returned err: %v", err)
+	}
+
+	wg.Wait()
+
+	// check the image wasn't removed before the container was created
+	for _, id := range _docker.created {
+		if id == "sha256:3" {
+			t.Errorf("CollectImages removed image %s before creating container", id)
+		}
+	}
+
+	// check the image is removed after the container was created
+	_docker.removed = nil
+
+	_, err = _engine.CollectImages(context.Background())
+	if err != nil {
+		t.Errorf("CollectImages returned err: %v", err)
+	}
+
+	want := []string{"sha256:1", "sha256:3"}
+
+	if !reflect.DeepEqual(_docker.removed, want) {
+		t.Errorf("CollectImages removed %v, want %v", _docker.removed, want)
+	}
+}
+
+func TestDocker_RemoveBuild_Interval(t *testing.T) {
+	// setup types
+	_docker := &gcDocker{}
+
+	_engine, err := NewMock(
+		WithImageGCMaxAge(24*time.Hour),
+		WithImageGCInterval(time.Hour),
+	)
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	_engine.Docker = _docker
+
+	// run test
+	for i := 0; i < 3; i++ {
+		err = _engine.RemoveBuild(context.Background(), _pipeline)
+		if err != nil {
+			t.Errorf("RemoveBuild returned err: %v", err)
+		}
+	}
+
+	if _docker.usage != 1 {
+		t.Errorf("RemoveBuild collected images %d times, want 1", _docker.usage)
+	}
+
+	// collect images once the interval has passed
+	_engine.collected = time.Now().Add(-2 * time.Hour)
+
+	err = _engine.RemoveBuild(context.Background(), _pipeline)
+	if err != nil {
+		t.Errorf("RemoveBuild returned err: %v", err)
+	}
+
+	if _docker.usage != 2 {
+		t.Errorf("RemoveBuild collected images %d times, want 2", _docker.usage)
+	}
+}
@@ -5,6 +5,7 @@
 package docker
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"io"
@@ -12,6 +13,9 @@ import (
 	"strings"
 
 	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
 	"github.com/go-vela/pkg-runtime/internal/image"
 	"github.com/go-vela/types/constants"
 	"github.com/go-vela/types/pipeline"
@@ -23,6 +27,13 @@ import (
 func (c *client) CreateImage(ctx context.Context, ctn *pipeline.Container) error {
 	logrus.Tracef("creating image for container %s", ctn.ID)
 
+	// create the image for the default platform
+	return c.createImage(ctx, ctn, c.config.Platform)
+}
+
+// createImage is a helper function to pull the
+// pipeline container image for the platform.
+func (c *client) createImage(ctx context.Context, ctn *pipeline.Container, platform *specs.Platform) error {
 	// parse image from container
 	//
 	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/image#ParseWithError
@@ -31,28 +42,58 @@ func (c *client) CreateImage(ctx context.Context, ctn *pipeline.Container) error
 		return err
 	}
 
-	// create options for pulling image
+	// parse image from container with the registry mirrors
 	//
-	// https://godoc.org/github.com/docker/docker/api/types#ImagePullOptions
-	opts := types.ImagePullOptions{}
-
-	// send API call to pull the image for the container
-	//
-	// https://godoc.org/github.com/docker/docker/client#Client.ImagePull
-	reader, err := c.Docker.ImagePull(ctx, _image, opts)
+	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/image#ParseWithMirror
+	_mirror, err := image.ParseWithMirror(ctn.Image, c.config.Mirrors)
 	if err != nil {
 		return err
 	}
 
-	defer reader.Close()
+	// hold the image until the container is created
+	c.holdImage(ctn.ID, _image)
+	c.holdImage(ctn.ID, _mirror)
 
-	// copy output from image pull to standard output
-	_, err = io.Copy(os.Stdout, reader)
-	if err != nil {
-		return err
+	// check if the image should be pulled from a mirror
+	if !strings.EqualFold(_mirror, _image) {
+		logrus.Tracef("pulling image %s from mirror for container %s", ctn.Image, ctn.ID)
+
+		// send API call to pull the image from the mirror
+		err = c.pullImage(ctx, _mirror, _image, platform)
+		if err == nil {
+			// record the image was used for image garbage collection
+			c.touchImage(_image)
+
+			// check if the image is pulled by digest
+			//
+			// The daemon refuses to tag an image with a digest
+			// reference, so the container is created from the
+			// mirrored image instead.
+			if isDigest(_image) {
+				logrus.Tracef("skipping tag for image %s pulled by digest", ctn.Image)
+
+				return nil
+			}
+
+			// send API call to tag the mirrored image with the original reference
+			//
+			// https://godoc.org/github.com/docker/docker/client#Client.ImageTag
+			return c.Docker.ImageTag(ctx, _mirror, _image)
+		}
+
+		// check if we should fall back to the origin registry
+		if !c.config.MirrorFallback {
+			return err
+		}
+
+		logrus.Warnf("unable to pull image %s from mirror, falling back to origin: %v", ctn.Image, err)
 	}
 
-	return nil
+	// record the image was used for image garbage collection
+	c.touchImage(_image)
+
+	// send API call to pull the image from the origin
+	return c.pullImage(ctx, _image, _image, platform)
 }
 
 // InspectImage inspects the pipeline container image.
@@ -90,3 +131,195 @@ func (c *client) InspectImage(ctx context.Context, ctn *pipeline.Container) ([]b
 	// add new line to end of bytes
 	return append(output, []byte(i.ID+"\n")...), nil
 }
+
+// pullImage is a helper function to pull the provided
+// image reference. The output from the image pull is
+// copied to standard output with the reference replaced
+// by the original reference for the image.
+func (c *client) pullImage(ctx context.Context, ref, original string, platform *specs.Platform) error {
+	// capture the features supported by the daemon
+	caps, err := c.Capabilities(ctx)
+	if err != nil {
+		return err
+	}
+
+	// check if the daemon supports the platform for pulling images
+	if platform != nil && !caps.PullPlatform {
+		return fmt.Errorf("unable to pull image %s for platform %s: not supported by docker API version %s",
+			original, image.FormatPlatform(platform), caps.APIVersion)
+	}
+
+	// create options for pulling image
+	//
+	// https://godoc.org/github.com/docker/docker/api/types#ImagePullOptions
+	opts := types.ImagePullOptions{
+		Platform: image.FormatPlatform(platform),
+	}
+
+	// send API call to pull the image for the container
+	//
+	// https://godoc.org/github.com/docker/docker/client#Client.ImagePull
+	reader, err := c.Docker.ImagePull(ctx, ref, opts)
+	if err != nil {
+		return err
+	}
+
+	defer reader.Close()
+
+	// check if the reference is the original reference
+	if strings.EqualFold(ref, original) {
+		// copy output from image pull to standard output
+		_, err = io.Copy(os.Stdout, reader)
+
+		return err
+	}
+
+	// create replacer for displaying the original reference
+	//
+	// https://pkg.go.dev/strings#NewReplacer
+	replacer := strings.NewReplacer(ref, original, repository(ref), repository(original))
+
+	// create new scanner from the image pull output
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		// copy output from image pull to standard output
+		fmt.Fprintln(os.Stdout, replacer.Replace(scanner.Text()))
+	}
+
+	return scanner.Err()
+}
+
+// repository is a helper function to trim
+// the tag and digest from the image reference.
+func repository(ref string) string {
+	// trim the digest from the reference
+	ref = strings.SplitN(ref, "@", 2)[0]
+
+	// trim the tag from the reference
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref = ref[:i]
+	}
+
+	return ref
+}
+
+// isDigest is a helper function to check
+// if the image reference contains a digest.
+func isDigest(ref string) bool {
+	return strings.Contains(ref, "@")
+}
+
+// digestImage is a helper function to capture the reference
+// for creating the container from an image pulled by digest.
+// The image pulled from a mirror isn't tagged with the original
+// reference, so the mirrored reference is used when the image
+// for the original reference does not exist on the host.
+func (c *client) digestImage(ctx context.Context, ctn *pipeline.Container) (string, error) {
+	// parse image from container
+	//
+	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/image#ParseWithError
+	_image, err := image.ParseWithError(ctn.Image)
+	if err != nil {
+		return "", err
+	}
+
+	// parse image from container with the registry mirrors
+	//
+	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/image#ParseWithMirror
+	_mirror, err := image.ParseWithMirror(ctn.Image, c.config.Mirrors)
+	if err != nil {
+		return "", err
+	}
+
+	// check if the image is pulled from a mirror
+	if strings.EqualFold(_mirror, _image) {
+		return _image, nil
+	}
+
+	// check if the image for the original reference exists on the host
+	//
+	// https://godoc.org/github.com/docker/docker/client#Client.ImageInspectWithRaw
+	_, _, err = c.Docker.ImageInspectWithRaw(ctx, _image)
+	if err == nil {
+		return _image, nil
+	}
+
+	// check if the image for the original reference does not exist
+	//
+	// https://godoc.org/github.com/docker/docker/client#IsErrNotFound
+	if !docker.IsErrNotFound(err) {
+		return "", err
+	}
+
+	return _mirror, nil
+}
+
+// pinImage is a helper function to ensure the image pinned
+// to the verified digest exists on the host. It returns the
+// pinned reference for creating the container.
+func (c *client) pinImage(ctx context.Context, ctn *pipeline.Container, digest string, platform *specs.Platform) (string, error) {
+	// parse image from container pinned to the digest
+	//
+	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/image#ParseWithDigest
+	_pinned, err := image.ParseWithDigest(ctn.Image, digest)
+	if err != nil {
+		return "", err
+	}
+
+	// create a copy of the container pinned to the digest
+	_ctn := *ctn
+	_ctn.Image = _pinned
+
+	// capture the reference for the pinned image on the host
+	ref, err := c.digestImage(ctx, &_ctn)
+	if err != nil {
+		return "", err
+	}
+
+	// check if the pinned image exists on the host
+	//
+	// https://godoc.org/github.com/docker/docker/client#Client.ImageInspectWithRaw
+	_, _, err = c.Docker.ImageInspectWithRaw(ctx, ref)
+	if err == nil {
+		return ref, nil
+	}
+
+	// if the pinned image does not exist on the host
+	// we attempt to capture it from the registry mirrors
+	// or the origin registry
+	//
+	// https://godoc.org/github.com/docker/docker/client#IsErrNotFound
+	if docker.IsErrNotFound(err) {
+		logrus.Tracef("pulling verified image %s for container %s", ctn.Image, ctn.ID)
+
+		err = c.createImage(ctx, &_ctn, platform)
+		if err != nil {
+			return "", err
+		}
+
+		return c.digestImage(ctx, &_ctn)
+	}
+
+	return "", err
+}
+
+// platform is a helper function to capture the platform
+// for images in the pipeline build. The worker platform
+// for the build is used when it is a valid platform,
+// otherwise the default platform is used.
+func (c *client) platform(b *pipeline.Build) *specs.Platform {
+	// check if the build was provided
+	if b == nil {
+		return c.config.Platform
+	}
+
+	// parse the worker platform for the build
+	//
+	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/image#ParsePlatform
+	p, err := image.ParsePlatform(b.Worker.Platform)
+	if err != nil || p == nil {
+		return c.config.Platform
+	}
+
+	return p
+}
@@ -6,8 +6,18 @@ package docker
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
 
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	docker "github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
 	"github.com/go-vela/types/pipeline"
 )
 
@@ -62,3 +72,181 @@ func TestDocker_InspectImage(t *testing.T) {
 		}
 	}
 }
+
+func TestDocker_CreateImage(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure   bool
+		opts      []ClientOpt
+		container *pipeline.Container
+	}{
+		{
+			failure:   false,
+			container: _container,
+		},
+		{
+			failure:   false,
+			opts:      []ClientOpt{WithRegistryMirrors([]string{"docker.io=mirror.company.com:5000"})},
+			container: _container,
+		},
+		{
+			failure: false,
+			opts: []ClientOpt{
+				WithRegistryMirrors([]string{"docker.io=notfound.company.com:5000"}),
+				WithRegistryMirrorFallback(true),
+			},
+			container: _container,
+		},
+		{
+			failure:   true,
+			opts:      []ClientOpt{WithRegistryMirrors([]string{"docker.io=notfound.company.com:5000"})},
+			container: _container,
+		},
+		{
+			failure:   true,
+			container: new(pipeline.Container),
+		},
+		{
+			failure: true,
+			container: &pipeline.Container{
+				ID:          "step_github_octocat_1_clone",
+				Directory:   "/vela/src/github.com/octocat/helloworld",
+				Environment: map[string]string{"FOO": "bar"},
+				Image:       "target/vela-git:notfound",
+				Name:        "clone",
+				Number:      2,
+				Pull:        "always",
+			},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := NewMock(test.opts...)
+		if err != nil {
+			t.Errorf("unable to create runtime engine: %v", err)
+		}
+
+		err = _engine.CreateImage(context.Background(), test.container)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("CreateImage should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("CreateImage returned err: %v", err)
+		}
+	}
+}
+
+// digestDocker represents a Docker client refusing to
+// tag images with a digest reference and recording the
+// images pulled and the images for created containers.
+type digestDocker struct {
+	docker.CommonAPIClient
+
+	pulled  []string
+	created []string
+}
+
+// ImagePull records the image pulled.
+func (d *digestDocker) ImagePull(ctx context.Context, ref string, options types.ImagePullOptions) (io.ReadCloser, error) {
+	d.pulled = append(d.pulled, ref)
+
+	return d.CommonAPIClient.ImagePull(ctx, ref, options)
+}
+
+// ImageInspectWithRaw returns a not found error for images that weren't pulled.
+func (d *digestDocker) ImageInspectWithRaw(ctx context.Context, ref string) (types.ImageInspect, []byte, error) {
+	for _, pulled := range d.pulled {
+		if strings.EqualFold(pulled, ref) {
+			return d.CommonAPIClient.ImageInspectWithRaw(ctx, ref)
+		}
+	}
+
+	return types.ImageInspect{}, nil, errdefs.NotFound(fmt.Errorf("no such image: %s", ref))
+}
+
+// ImageTag returns an error for a digest reference.
+func (d *digestDocker) ImageTag(ctx context.Context, source, target string) error {
+	if strings.Contains(target, "@") {
+		return fmt.Errorf("refusing to create a tag with a digest reference")
+	}
+
+	return d.CommonAPIClient.ImageTag(ctx, source, target)
+}
+
+// ContainerCreate records the image for the container.
+//
+// nolint: lll // ignore long line length due to variable names
+func (d *digestDocker) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, name string) (container.ContainerCreateCreatedBody, error) {
+	d.created = append(d.created, config.Image)
+
+	return d.CommonAPIClient.ContainerCreate(ctx, config, hostConfig, networkingConfig, platform, name)
+}
+
+func TestDocker_CreateImage_Digest(t *testing.T) {
+	// setup types
+	digest := "sha256:1bd7a6f6a7c9f3c2e7e0c3d4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4"
+
+	_digest := new(pipeline.Container)
+	*_digest = *_container
+	_digest.Image = "target/vela-git@" + digest
+
+	// setup tests
+	tests := []struct {
+		name      string
+		opts      []ClientOpt
+		container *pipeline.Container
+		want      string
+	}{
+		{
+			name:      "tag from mirror",
+			opts:      []ClientOpt{WithRegistryMirrors([]string{"docker.io=mirror.company.com:5000"})},
+			container: _container,
+			want:      "docker.io/target/vela-git:v0.4.0",
+		},
+		{
+			name:      "digest from mirror",
+			opts:      []ClientOpt{WithRegistryMirrors([]string{"docker.io=mirror.company.com:5000"})},
+			container: _digest,
+			want:      "mirror.company.com:5000/target/vela-git@" + digest,
+		},
+		{
+			name:      "digest from origin",
+			container: _digest,
+			want:      "docker.io/target/vela-git@" + digest,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_engine, err := NewMock(test.opts...)
+			if err != nil {
+				t.Errorf("unable to create runtime engine: %v", err)
+			}
+
+			_docker := &digestDocker{CommonAPIClient: _engine.Docker}
+			_engine.Docker = _docker
+
+			err = _engine.CreateImage(context.Background(), test.container)
+			if err != nil {
+				t.Errorf("CreateImage returned err: %v", err)
+			}
+
+			err = _engine.RunContainer(context.Background(), test.container, _pipeline)
+			if err != nil {
+				t.Errorf("RunContainer returned err: %v", err)
+			}
+
+			if len(_docker.created) != 1 || !strings.EqualFold(_docker.created[0], test.want) {
+				t.Errorf("RunContainer created %v, want %s", _docker.created, test.want)
+			}
+		})
+	}
+}
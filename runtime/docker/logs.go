@@ -0,0 +1,264 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/go-vela/pkg-runtime/internal/logs"
+	"github.com/go-vela/types/pipeline"
+
+	"github.com/sirupsen/logrus"
+)
+
+// frameHeaderSize represents the size in bytes of the
+// header for each frame of multiplexed container output.
+//
+// https://docs.docker.com/engine/api/v1.40/#operation/ContainerAttach
+const frameHeaderSize = 8
+
+// frameStreams represents the streams for the
+// frames of multiplexed container output.
+//
+// https://pkg.go.dev/github.com/docker/docker/pkg/stdcopy#StdType
+var frameStreams = map[byte]string{
+	0: logs.StreamStdout,
+	1: logs.StreamStdout,
+	2: logs.StreamStderr,
+}
+
+// TailRecords captures the log records, with the stream and
+// timestamp for each line, for the pipeline container.
+func (c *client) TailRecords(ctx context.Context, ctn *pipeline.Container) (logs.Reader, error) {
+	logrus.Tracef("tailing records for container %s", ctn.ID)
+
+	// create options for capturing container logs
+	//
+	// https://godoc.org/github.com/docker/docker/api/types#ContainerLogsOptions
+	opts := types.ContainerLogsOptions{
+		Follow:     true,
+		ShowStdout: true,
+		ShowStderr: true,
+		Details:    false,
+		Timestamps: true,
+		Since:      c.resumeSince(ctn),
+	}
+
+	// send API call to capture the container logs
+	//
+	// https://godoc.org/github.com/docker/docker/client#Client.ContainerLogs
+	output, err := c.Docker.ContainerLogs(ctx, ctn.ID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// check if the logs for the container are limited
+	if c.config.LogLimit.Enabled() {
+		// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/logs#NewLimitRecords
+		return logs.NewLimitRecords(newRecordReader(output), c.config.LogLimit, c.logLimitExceeded(ctn)), nil
+	}
+
+	return newRecordReader(output), nil
+}
+
+// limitLogs is a helper function to apply the log
+// limits to the logs for the pipeline container.
+func (c *client) limitLogs(ctn *pipeline.Container, rc io.ReadCloser) io.ReadCloser {
+	// check if the logs for the container are limited
+	if !c.config.LogLimit.Enabled() {
+		return rc
+	}
+
+	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/logs#NewLimitReader
+	return logs.NewLimitReader(rc, c.config.LogLimit, c.logLimitExceeded(ctn))
+}
+
+// logLimitExceeded is a helper function to create the function
+// called when the logs for the pipeline container exceed the
+// log limits. The container is killed when configured.
+func (c *client) logLimitExceeded(ctn *pipeline.Container) func(*logs.LimitError) {
+	return func(limitErr *logs.LimitError) {
+		logrus.Warnf("truncating logs for container %s: %v", ctn.ID, limitErr)
+
+		// check if the container should be killed
+		if !c.config.LogLimit.Kill {
+			return
+		}
+
+		logrus.Warnf("killing container %s for exceeding log limit", ctn.ID)
+
+		// send API call to kill the container
+		//
+		// https://godoc.org/github.com/docker/docker/client#Client.ContainerKill
+		err := c.Docker.ContainerKill(context.Background(), ctn.ID, "SIGKILL")
+		if err != nil {
+			logrus.Errorf("unable to kill container %s: %v", ctn.ID, err)
+		}
+	}
+}
+
+// recordReader represents a logs.Reader capturing log
+// records from the multiplexed output of a container.
+type recordReader struct {
+	output io.ReadCloser
+	header []byte
+	// records captured from a frame not yet returned
+	pending []*logs.Record
+	// lines for each stream without a trailing newline
+	partial map[string]*logs.Record
+	err     error
+}
+
+// newRecordReader creates a recordReader from the
+// multiplexed output of a container.
+func newRecordReader(output io.ReadCloser) *recordReader {
+	return &recordReader{
+		output:  output,
+		header:  make([]byte, frameHeaderSize),
+		partial: make(map[string]*logs.Record),
+	}
+}
+
+// Next returns the next log record from the container.
+func (r *recordReader) Next() (*logs.Record, error) {
+	// read frames until a record is available
+	for len(r.pending) == 0 {
+		// check if the output has been exhausted
+		if r.err != nil {
+			return nil, r.err
+		}
+
+		r.readFrame()
+	}
+
+	record := r.pending[0]
+	r.pending = r.pending[1:]
+
+	return record, nil
+}
+
+// Close closes the multiplexed output of the container.
+func (r *recordReader) Close() error {
+	return r.output.Close()
+}
+
+// readFrame is a helper function to read the next frame
+// from the multiplexed output and capture the records.
+//
+// Each frame has an 8 byte header where the first byte is
+// the stream and the last 4 bytes are the big endian size
+// of the payload following the header.
+//
+// https://docs.docker.com/engine/api/v1.40/#operation/ContainerAttach
+func (r *recordReader) readFrame() {
+	// read the header for the frame
+	_, err := io.ReadFull(r.output, r.header)
+	if err != nil {
+		// check if the output ended between frames
+		if err == io.EOF {
+			r.flush()
+		} else if err == io.ErrUnexpectedEOF {
+			err = fmt.Errorf("unable to read frame header: %w", err)
+		}
+
+		r.err = err
+
+		return
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint32(r.header[4:]))
+
+	// read the payload for the frame
+	_, err = io.ReadFull(r.output, payload)
+	if err != nil {
+		r.err = fmt.Errorf("unable to read frame payload: %w", err)
+
+		return
+	}
+
+	stream, ok := frameStreams[r.header[0]]
+	if !ok {
+		// check if the frame is an error from the daemon
+		//
+		// https://pkg.go.dev/github.com/docker/docker/pkg/stdcopy#Systemerr
+		if r.header[0] == 3 {
+			r.err = fmt.Errorf("error from daemon in stream: %s", payload)
+
+			return
+		}
+
+		r.err = fmt.Errorf("unrecognized stream %d in frame header", r.header[0])
+
+		return
+	}
+
+	r.capture(stream, string(payload))
+}
+
+// capture is a helper function to split the payload
+// for the stream into records for each line.
+//
+// Docker splits long lines into multiple frames with
+// a timestamp for each, so a line without a trailing
+// newline is held until the rest of the line arrives.
+func (r *recordReader) capture(stream, payload string) {
+	// split the timestamp from the payload
+	timestamp, payload := logs.SplitTimestamp(payload)
+
+	// check if the stream has a partial line
+	if partial, ok := r.partial[stream]; ok {
+		delete(r.partial, stream)
+
+		timestamp = partial.Timestamp
+		payload = partial.Line + payload
+	}
+
+	for {
+		i := strings.IndexByte(payload, '\n')
+		if i < 0 {
+			break
+		}
+
+		r.pending = append(r.pending, &logs.Record{
+			Stream:    stream,
+			Timestamp: timestamp,
+			Line:      strings.TrimSuffix(payload[:i], "\r"),
+		})
+
+		payload = payload[i+1:]
+	}
+
+	// check if the payload has a partial line
+	if len(payload) > 0 {
+		r.partial[stream] = &logs.Record{
+			Stream:    stream,
+			Timestamp: timestamp,
+			Line:      payload,
+		}
+	}
+}
+
+// flush is a helper function to capture the
+// partial lines for each stream as records.
+func (r *recordReader) flush() {
+	for _, stream := range []string{logs.StreamStdout, logs.StreamStderr} {
+		partial, ok := r.partial[stream]
+		if !ok {
+			continue
+		}
+
+		delete(r.partial, stream)
+
+		partial.Line = strings.TrimSuffix(partial.Line, "\r")
+
+		r.pending = append(r.pending, partial)
+	}
+}
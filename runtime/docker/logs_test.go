@@ -0,0 +1,319 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	docker "github.com/docker/docker/client"
+
+	"github.com/go-vela/pkg-runtime/internal/logs"
+)
+
+// frame is a helper function to create a
+// frame of multiplexed container output.
+func frame(stream byte, payload string) []byte {
+	header := make([]byte, frameHeaderSize)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+
+	return append(header, payload...)
+}
+
+func TestDocker_TailRecords(t *testing.T) {
+	// setup types
+	_engine, err := NewMock()
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	// run test
+	r, err := _engine.TailRecords(context.Background(), _container)
+	if err != nil {
+		t.Errorf("TailRecords returned err: %v", err)
+	}
+
+	defer r.Close()
+
+	got, err := r.Next()
+	if err != nil {
+		t.Errorf("Next returned err: %v", err)
+	}
+
+	if got == nil || got.Stream != logs.StreamStdout || len(got.Line) == 0 {
+		t.Errorf("Next is %+v, want stdout record", got)
+	}
+}
+
+func TestDocker_recordReader(t *testing.T) {
+	// setup types
+	first := time.Date(2021, 10, 19, 1, 29, 3, 0, time.UTC)
+	second := time.Date(2021, 10, 19, 1, 29, 4, 0, time.UTC)
+
+	// setup tests
+	tests := []struct {
+		name    string
+		frames  [][]byte
+		failure bool
+		want    []*logs.Record
+	}{
+		{
+			name: "streams",
+			frames: [][]byte{
+				frame(1, "2021-10-19T01:29:03Z hello\n"),
+				frame(2, "2021-10-19T01:29:04Z world\r\n"),
+			},
+			want: []*logs.Record{
+				{Stream: logs.StreamStdout, Timestamp: first, Line: "hello"},
+				{Stream: logs.StreamStderr, Timestamp: second, Line: "world"},
+			},
+		},
+		{
+			name: "split line",
+			frames: [][]byte{
+				frame(1, "2021-10-19T01:29:03Z hel"),
+				frame(2, "2021-10-19T01:29:04Z world\n"),
+				frame(1, "2021-10-19T01:29:04Z lo\n"),
+			},
+			want: []*logs.Record{
+				{Stream: logs.StreamStderr, Timestamp: second, Line: "world"},
+				{Stream: logs.StreamStdout, Timestamp: first, Line: "hello"},
+			},
+		},
+		{
+			name: "multiple lines",
+			frames: [][]byte{
+				frame(1, "2021-10-19T01:29:03Z hello\nworld\n\n"),
+			},
+			want: []*logs.Record{
+				{Stream: logs.StreamStdout, Timestamp: first, Line: "hello"},
+				{Stream: logs.StreamStdout, Timestamp: first, Line: "world"},
+				{Stream: logs.StreamStdout, Timestamp: first, Line: ""},
+			},
+		},
+		{
+			name: "partial line",
+			frames: [][]byte{
+				frame(2, "2021-10-19T01:29:04Z world"),
+				frame(1, "2021-10-19T01:29:03Z hello"),
+			},
+			want: []*logs.Record{
+				{Stream: logs.StreamStdout, Timestamp: first, Line: "hello"},
+				{Stream: logs.StreamStderr, Timestamp: second, Line: "world"},
+			},
+		},
+		{
+			name: "no timestamps",
+			frames: [][]byte{
+				frame(1, "hello\n"),
+			},
+			want: []*logs.Record{
+				{Stream: logs.StreamStdout, Line: "hello"},
+			},
+		},
+		{
+			name: "empty frame",
+			frames: [][]byte{
+				frame(1, ""),
+				frame(1, "2021-10-19T01:29:03Z hello\n"),
+			},
+			want: []*logs.Record{
+				{Stream: logs.StreamStdout, Timestamp: first, Line: "hello"},
+			},
+		},
+		{
+			name: "daemon error",
+			frames: [][]byte{
+				frame(1, "2021-10-19T01:29:03Z hello\n"),
+				frame(3, "foo"),
+			},
+			failure: true,
+			want: []*logs.Record{
+				{Stream: logs.StreamStdout, Timestamp: first, Line: "hello"},
+			},
+		},
+		{
+			name: "unknown stream",
+			frames: [][]byte{
+				frame(9, "foo"),
+			},
+			failure: true,
+			want:    []*logs.Record{},
+		},
+		{
+			name: "truncated header",
+			frames: [][]byte{
+				frame(1, "2021-10-19T01:29:03Z hello\n"),
+				{1, 0, 0},
+			},
+			failure: true,
+			want: []*logs.Record{
+				{Stream: logs.StreamStdout, Timestamp: first, Line: "hello"},
+			},
+		},
+		{
+			name: "truncated payload",
+			frames: [][]byte{
+				frame(1, "2021-10-19T01:29:03Z hello\n")[:frameHeaderSize+4],
+			},
+			failure: true,
+			want:    []*logs.Record{},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		r := newRecordReader(ioutil.NopCloser(bytes.NewReader(bytes.Join(test.frames, nil))))
+
+		got := []*logs.Record{}
+
+		var err error
+
+		for {
+			var record *logs.Record
+
+			record, err = r.Next()
+			if err != nil {
+				break
+			}
+
+			got = append(got, record)
+		}
+
+		if test.failure && err == io.EOF {
+			t.Errorf("Next for %s should have returned err", test.name)
+		}
+
+		if !test.failure && err != io.EOF {
+			t.Errorf("Next for %s returned err: %v", test.name, err)
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Next for %s is %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+// killDocker represents a Docker client
+// recording the containers killed.
+type killDocker struct {
+	docker.CommonAPIClient
+
+	killed []string
+}
+
+// ContainerKill records the container killed.
+func (d *killDocker) ContainerKill(ctx context.Context, ctn, signal string) error {
+	d.killed = append(d.killed, ctn)
+
+	return d.CommonAPIClient.ContainerKill(ctx, ctn, signal)
+}
+
+func TestDocker_TailContainer_LogLimit(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name   string
+		kill   bool
+		want   string
+		killed []string
+	}{
+		{
+			name:   "truncate",
+			kill:   false,
+			want:   "hello to stdout\n--- log limit of 15 bytes exceeded: output truncated ---\n",
+			killed: nil,
+		},
+		{
+			name:   "kill",
+			kill:   true,
+			want:   "hello to stdout\n--- log limit of 15 bytes exceeded: output truncated ---\n",
+			killed: []string{_container.ID},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := NewMock(
+			WithLogLimits("15", 0),
+			WithLogLimitKill(test.kill),
+		)
+		if err != nil {
+			t.Errorf("unable to create runtime engine: %v", err)
+		}
+
+		_docker := &killDocker{CommonAPIClient: _engine.Docker}
+		_engine.Docker = _docker
+
+		rc, err := _engine.TailContainer(context.Background(), _container)
+		if err != nil {
+			t.Errorf("TailContainer for %s returned err: %v", test.name, err)
+		}
+
+		got, err := ioutil.ReadAll(rc)
+
+		var limitErr *logs.LimitError
+		if !errors.As(err, &limitErr) {
+			t.Errorf("TailContainer for %s returned err %v, want LimitError", test.name, err)
+		}
+
+		if !strings.EqualFold(string(got), test.want) {
+			t.Errorf("TailContainer for %s is %q, want %q", test.name, got, test.want)
+		}
+
+		if !reflect.DeepEqual(_docker.killed, test.killed) {
+			t.Errorf("TailContainer for %s killed %v, want %v", test.name, _docker.killed, test.killed)
+		}
+	}
+}
+
+func TestDocker_TailRecords_LogLimit(t *testing.T) {
+	// setup types
+	_engine, err := NewMock(WithLogLimits("", 1))
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	// run test
+	r, err := _engine.TailRecords(context.Background(), _container)
+	if err != nil {
+		t.Errorf("TailRecords returned err: %v", err)
+	}
+
+	defer r.Close()
+
+	got := []string{}
+
+	for {
+		record, err := r.Next()
+		if err != nil {
+			var limitErr *logs.LimitError
+			if !errors.As(err, &limitErr) {
+				t.Errorf("Next returned err %v, want LimitError", err)
+			}
+
+			break
+		}
+
+		got = append(got, record.Line)
+	}
+
+	want := []string{
+		"hello to stdout from github.com/go-vela/mock/docker",
+		"--- log limit of 1 lines exceeded: output truncated ---",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TailRecords is %v, want %v", got, want)
+	}
+}
@@ -8,15 +8,40 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/network"
 
+	vnet "github.com/go-vela/pkg-runtime/internal/network"
 	"github.com/go-vela/types/pipeline"
 
 	"github.com/sirupsen/logrus"
 )
 
+// networkConfig represents the settings
+// for the network for a build.
+type networkConfig struct {
+	// driver for the network
+	Driver string
+	// pools the subnets for the network are allocated from
+	Subnets []*vnet.Pool
+	// indicates the network is restricted from external access
+	Internal bool
+	// indicates IPv6 is enabled for the network
+	IPv6 bool
+	// DNS servers for containers on the network
+	DNS []string
+	// DNS search domains for containers on the network
+	DNSSearch []string
+	// extra hosts, in the form <host>:<ip>, for containers on the network
+	ExtraHosts []string
+	// indicates egress from the network is restricted
+	Egress bool
+	// proxy connected to the network when egress is restricted
+	Proxy *egressProxy
+}
+
 // CreateNetwork creates the pipeline network.
 func (c *client) CreateNetwork(ctx context.Context, b *pipeline.Build) error {
 	logrus.Tracef("creating network for pipeline %s", b.ID)
@@ -25,7 +50,29 @@ func (c *client) CreateNetwork(ctx context.Context, b *pipeline.Build) error {
 	//
 	// https://godoc.org/github.com/docker/docker/api/types#NetworkCreate
 	opts := types.NetworkCreate{
-		Driver: "bridge",
+		Driver:     c.config.Network.Driver,
+		Internal:   c.config.Network.Internal || c.config.Network.Egress,
+		EnableIPv6: c.config.Network.IPv6,
+		Labels:     c.labels(b),
+	}
+
+	// check if any subnet pools were provided
+	if len(c.config.Network.Subnets) > 0 {
+		// allocate the subnets for the network from the pools
+		subnets, err := c.allocateSubnets(ctx, b)
+		if err != nil {
+			return err
+		}
+
+		// https://godoc.org/github.com/docker/docker/api/types/network#IPAM
+		opts.IPAM = &network.IPAM{Driver: "default"}
+
+		for _, subnet := range subnets {
+			// https://godoc.org/github.com/docker/docker/api/types/network#IPAMConfig
+			opts.IPAM.Config = append(opts.IPAM.Config, network.IPAMConfig{
+				Subnet: subnet.String(),
+			})
+		}
 	}
 
 	// send API call to create the network
@@ -33,9 +80,18 @@ func (c *client) CreateNetwork(ctx context.Context, b *pipeline.Build) error {
 	// https://godoc.org/github.com/docker/docker/client#Client.NetworkCreate
 	_, err := c.Docker.NetworkCreate(ctx, b.ID, opts)
 	if err != nil {
+		// release the subnets for the network
+		c.releaseSubnets(b)
+
 		return err
 	}
 
+	// check if a proxy is provided for restricted egress
+	if c.config.Network.Egress && c.config.Network.Proxy != nil {
+		// connect the proxy to the network
+		return c.connectProxy(ctx, b)
+	}
+
 	return nil
 }
 
@@ -70,13 +126,53 @@ func (c *client) InspectNetwork(ctx context.Context, b *pipeline.Build) ([]byte,
 	}
 
 	// add new line to end of bytes
-	return append(output, append(network, "\n"...)...), nil
+	output = append(output, append(network, "\n"...)...)
+
+	// check if any DNS settings or extra hosts were provided
+	if len(c.config.Network.DNS) == 0 &&
+		len(c.config.Network.DNSSearch) == 0 &&
+		len(c.config.Network.ExtraHosts) == 0 {
+		return output, nil
+	}
+
+	// create output for the settings for containers on the network
+	output = append(output, []byte(
+		fmt.Sprintf("# dns and hosts for containers on network %s\n", b.ID),
+	)...)
+
+	// convert the settings for containers to bytes with pretty print
+	//
+	// The settings use the same names as the host
+	// config from inspecting a Docker container.
+	//
+	// https://godoc.org/github.com/docker/docker/api/types/container#HostConfig
+	hosts, err := json.MarshalIndent(struct {
+		DNS        []string `json:"Dns,omitempty"`
+		DNSSearch  []string `json:"DnsSearch,omitempty"`
+		ExtraHosts []string `json:"ExtraHosts,omitempty"`
+	}{
+		DNS:        c.config.Network.DNS,
+		DNSSearch:  c.config.Network.DNSSearch,
+		ExtraHosts: c.config.Network.ExtraHosts,
+	}, "", " ")
+	if err != nil {
+		return output, err
+	}
+
+	// add new line to end of bytes
+	return append(output, append(hosts, "\n"...)...), nil
 }
 
 // RemoveNetwork deletes the pipeline network.
 func (c *client) RemoveNetwork(ctx context.Context, b *pipeline.Build) error {
 	logrus.Tracef("removing network for pipeline %s", b.ID)
 
+	// check if a proxy is provided for restricted egress
+	if c.config.Network.Egress && c.config.Network.Proxy != nil {
+		// disconnect the proxy from the network
+		c.disconnectProxy(ctx, b.ID)
+	}
+
 	// send API call to remove the network
 	//
 	// https://godoc.org/github.com/docker/docker/client#Client.NetworkRemove
@@ -85,9 +181,111 @@ func (c *client) RemoveNetwork(ctx context.Context, b *pipeline.Build) error {
 		return err
 	}
 
+	// release the subnets for the network
+	c.releaseSubnets(b)
+
 	return nil
 }
 
+// allocateSubnets is a helper function to allocate a subnet
+// for each address family from the pools for the network.
+//
+// The subnets used by any network on the daemon, or allocated
+// to the network for another build, are not allocated.
+func (c *client) allocateSubnets(ctx context.Context, b *pipeline.Build) ([]*net.IPNet, error) {
+	// send API call to list the networks
+	//
+	// https://godoc.org/github.com/docker/docker/client#Client.NetworkList
+	networks, err := c.Docker.NetworkList(ctx, types.NetworkListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	used := []*net.IPNet{}
+
+	// capture the subnets used by networks on the daemon
+	for _, n := range networks {
+		used = append(used, networkSubnets(n)...)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// capture the subnets allocated to networks for other builds
+	for _, subnets := range c.subnets {
+		used = append(used, subnets...)
+	}
+
+	subnets := []*net.IPNet{}
+
+	// allocate a subnet for each address family
+	for _, ipv6 := range []bool{false, true} {
+		pools := []*vnet.Pool{}
+
+		for _, pool := range c.config.Network.Subnets {
+			if pool.IPv6() == ipv6 {
+				pools = append(pools, pool)
+			}
+		}
+
+		// check if any pools were provided for the address family
+		if len(pools) == 0 {
+			continue
+		}
+
+		subnet, err := nextSubnet(pools, used)
+		if err != nil {
+			return nil, err
+		}
+
+		subnets = append(subnets, subnet)
+	}
+
+	// record the subnets allocated to the network for the build
+	c.subnets[b.ID] = subnets
+
+	return subnets, nil
+}
+
+// networkSubnets is a helper function to capture
+// the subnets from the IPAM config for the network.
+func networkSubnets(n types.NetworkResource) []*net.IPNet {
+	subnets := []*net.IPNet{}
+
+	for _, config := range n.IPAM.Config {
+		_, subnet, err := net.ParseCIDR(config.Subnet)
+		if err != nil {
+			continue
+		}
+
+		subnets = append(subnets, subnet)
+	}
+
+	return subnets
+}
+
+// releaseSubnets is a helper function to release
+// the subnets allocated to the network for the build.
+func (c *client) releaseSubnets(b *pipeline.Build) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.subnets, b.ID)
+}
+
+// nextSubnet is a helper function to capture the first
+// subnet from the pools that isn't already used.
+func nextSubnet(pools []*vnet.Pool, used []*net.IPNet) (*net.IPNet, error) {
+	for _, pool := range pools {
+		subnet, ok := pool.Next(used)
+		if ok {
+			return subnet, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no subnet available from network pools %v", pools)
+}
+
 // netConfig is a helper function to generate
 // the network config for a container.
 func netConfig(id, alias string) *network.NetworkingConfig {
@@ -6,8 +6,14 @@ package docker
 
 import (
 	"context"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	docker "github.com/docker/docker/client"
+
 	"github.com/go-vela/types/pipeline"
 )
 
@@ -130,3 +136,206 @@ func TestDocker_RemoveNetwork(t *testing.T) {
 		}
 	}
 }
+
+// networkDocker represents a Docker client with existing
+// networks recording the options for creating networks.
+type networkDocker struct {
+	docker.CommonAPIClient
+
+	networks []types.NetworkResource
+	created  map[string]types.NetworkCreate
+}
+
+// NetworkList returns the existing networks.
+func (d *networkDocker) NetworkList(ctx context.Context, opts types.NetworkListOptions) ([]types.NetworkResource, error) {
+	return d.networks, nil
+}
+
+// NetworkCreate records the options for the network.
+func (d *networkDocker) NetworkCreate(ctx context.Context, name string, opts types.NetworkCreate) (types.NetworkCreateResponse, error) {
+	d.created[name] = opts
+
+	return d.CommonAPIClient.NetworkCreate(ctx, name, opts)
+}
+
+func TestDocker_CreateNetwork_Settings(t *testing.T) {
+	// setup types
+	_engine, err := NewMock(
+		WithNetworkDriver("overlay"),
+		WithNetworkSubnets([]string{"10.200.0.0/16", "fd00:1234::/48"}),
+		WithNetworkInternal(true),
+		WithNetworkIPv6(true),
+	)
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	_docker := &networkDocker{
+		CommonAPIClient: _engine.Docker,
+		networks: []types.NetworkResource{
+			{
+				Name: "existing",
+				IPAM: network.IPAM{Config: []network.IPAMConfig{{Subnet: "10.200.0.0/24"}}},
+			},
+		},
+		created: make(map[string]types.NetworkCreate),
+	}
+
+	_engine.Docker = _docker
+
+	_one := &pipeline.Build{ID: "github_octocat_1"}
+	_two := &pipeline.Build{ID: "github_octocat_2"}
+	_three := &pipeline.Build{ID: "github_octocat_3"}
+
+	// setup tests
+	tests := []struct {
+		pipeline *pipeline.Build
+		remove   *pipeline.Build
+		want     []string
+	}{
+		{
+			pipeline: _one,
+			want:     []string{"10.200.1.0/24", "fd00:1234::/64"},
+		},
+		{
+			pipeline: _two,
+			want:     []string{"10.200.2.0/24", "fd00:1234:0:1::/64"},
+		},
+		{
+			// the subnets released by the first build are allocated
+			pipeline: _three,
+			remove:   _one,
+			want:     []string{"10.200.1.0/24", "fd00:1234::/64"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		if test.remove != nil {
+			err = _engine.RemoveNetwork(context.Background(), test.remove)
+			if err != nil {
+				t.Errorf("RemoveNetwork for %s returned err: %v", test.remove.ID, err)
+			}
+		}
+
+		err = _engine.CreateNetwork(context.Background(), test.pipeline)
+		if err != nil {
+			t.Errorf("CreateNetwork for %s returned err: %v", test.pipeline.ID, err)
+		}
+
+		opts := _docker.created[test.pipeline.ID]
+
+		if opts.Driver != "overlay" || !opts.Internal || !opts.EnableIPv6 {
+			t.Errorf("CreateNetwork for %s is %+v", test.pipeline.ID, opts)
+		}
+
+		if opts.IPAM == nil {
+			t.Errorf("CreateNetwork for %s has no IPAM", test.pipeline.ID)
+
+			continue
+		}
+
+		got := []string{}
+		for _, config := range opts.IPAM.Config {
+			got = append(got, config.Subnet)
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("CreateNetwork for %s subnets is %v, want %v", test.pipeline.ID, got, test.want)
+		}
+	}
+}
+
+func TestDocker_CreateNetwork_NoSubnet(t *testing.T) {
+	// setup types
+	_engine, err := NewMock(
+		WithNetworkSubnets([]string{"10.200.0.0/24"}),
+	)
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	_engine.Docker = &networkDocker{
+		CommonAPIClient: _engine.Docker,
+		networks: []types.NetworkResource{
+			{
+				Name: "existing",
+				IPAM: network.IPAM{Config: []network.IPAMConfig{{Subnet: "10.200.0.0/16"}}},
+			},
+		},
+		created: make(map[string]types.NetworkCreate),
+	}
+
+	// run test
+	err = _engine.CreateNetwork(context.Background(), _pipeline)
+	if err == nil {
+		t.Errorf("CreateNetwork should have returned err")
+	}
+
+	if _, ok := _engine.subnets[_pipeline.ID]; ok {
+		t.Errorf("CreateNetwork allocated subnets %v", _engine.subnets[_pipeline.ID])
+	}
+}
+
+func TestDocker_InspectNetwork_Settings(t *testing.T) {
+	// setup types
+	_engine, err := NewMock(
+		WithNetworkDNS([]string{"10.0.0.2"}, []string{"company.com"}),
+		WithNetworkExtraHosts([]string{"registry.company.com:10.0.0.5"}),
+	)
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	// run test
+	got, err := _engine.InspectNetwork(context.Background(), _pipeline)
+	if err != nil {
+		t.Errorf("InspectNetwork returned err: %v", err)
+	}
+
+	for _, want := range []string{
+		"# dns and hosts for containers on network github_octocat_1",
+		`"Dns": [`,
+		`"10.0.0.2"`,
+		`"company.com"`,
+		`"registry.company.com:10.0.0.5"`,
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("InspectNetwork is %s, want %s", got, want)
+		}
+	}
+}
+
+func TestDocker_RunContainer_NetworkSettings(t *testing.T) {
+	// setup types
+	_engine, err := NewMock(
+		WithNetworkDNS([]string{"10.0.0.2"}, []string{"company.com"}),
+		WithNetworkExtraHosts([]string{"registry.company.com:10.0.0.5"}),
+	)
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	_docker := &versionDocker{CommonAPIClient: _engine.Docker, version: MaxVersion}
+	_engine.Docker = _docker
+
+	// run test
+	err = _engine.RunContainer(context.Background(), _container, _pipeline)
+	if err != nil {
+		t.Errorf("RunContainer returned err: %v", err)
+	}
+
+	if len(_docker.hosts) != 1 {
+		t.Errorf("RunContainer created %d containers, want 1", len(_docker.hosts))
+
+		return
+	}
+
+	hostConf := _docker.hosts[0]
+
+	if !reflect.DeepEqual(hostConf.DNS, []string{"10.0.0.2"}) ||
+		!reflect.DeepEqual(hostConf.DNSSearch, []string{"company.com"}) ||
+		!reflect.DeepEqual(hostConf.ExtraHosts, []string{"registry.company.com:10.0.0.5"}) {
+		t.Errorf("RunContainer host config is %+v", hostConf)
+	}
+}
@@ -5,6 +5,17 @@
 package docker
 
 import (
+	"fmt"
+	"os"
+	"time"
+
+	docker "github.com/docker/docker/client"
+	"github.com/docker/go-units"
+
+	"github.com/go-vela/pkg-runtime/internal/image"
+	"github.com/go-vela/pkg-runtime/internal/network"
+	"github.com/go-vela/pkg-runtime/internal/signature"
+
 	"github.com/sirupsen/logrus"
 )
 
@@ -34,3 +45,554 @@ func WithHostVolumes(volumes []string) ClientOpt {
 		return nil
 	}
 }
+
+// WithImagePolicy sets the Docker allowed and denied images in the runtime client.
+func WithImagePolicy(allowed, denied []string) ClientOpt {
+	logrus.Trace("configuring image policy in docker runtime client")
+
+	return func(c *client) error {
+		// set the runtime allowed and denied images in the docker client
+		c.config.Policy.AllowedImages = allowed
+		c.config.Policy.DeniedImages = denied
+
+		return nil
+	}
+}
+
+// WithRegistryPolicy sets the Docker allowed and denied registries in the runtime client.
+func WithRegistryPolicy(allowed, denied []string) ClientOpt {
+	logrus.Trace("configuring registry policy in docker runtime client")
+
+	return func(c *client) error {
+		// set the runtime allowed and denied registries in the docker client
+		c.config.Policy.AllowedRegistries = allowed
+		c.config.Policy.DeniedRegistries = denied
+
+		return nil
+	}
+}
+
+// WithRegistryMirrors sets the Docker registry mirrors in the runtime client.
+func WithRegistryMirrors(mirrors []string) ClientOpt {
+	logrus.Trace("configuring registry mirrors in docker runtime client")
+
+	return func(c *client) error {
+		// parse the registry mirrors provided
+		//
+		// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/image#ParseMirrors
+		_mirrors, err := image.ParseMirrors(mirrors)
+		if err != nil {
+			return err
+		}
+
+		// set the runtime registry mirrors in the docker client
+		c.config.Mirrors = _mirrors
+
+		return nil
+	}
+}
+
+// WithRegistryMirrorFallback sets the Docker registry mirror fallback in the runtime client.
+func WithRegistryMirrorFallback(fallback bool) ClientOpt {
+	logrus.Trace("configuring registry mirror fallback in docker runtime client")
+
+	return func(c *client) error {
+		// set the runtime registry mirror fallback in the docker client
+		c.config.MirrorFallback = fallback
+
+		return nil
+	}
+}
+
+// WithImageVerification sets the Docker image signature verification in the runtime client.
+func WithImageVerification(keys, images []string) ClientOpt {
+	logrus.Trace("configuring image verification in docker runtime client")
+
+	return func(c *client) error {
+		// create the image signature verifier
+		//
+		// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/signature#New
+		verifier, err := signature.New(keys, images)
+		if err != nil {
+			return err
+		}
+
+		// set the runtime image signature verifier in the docker client
+		c.config.Verifier = verifier
+
+		return nil
+	}
+}
+
+// WithImageGCMaxAge sets the Docker duration an image can go unused
+// before it is removed by image garbage collection in the runtime client.
+func WithImageGCMaxAge(age time.Duration) ClientOpt {
+	logrus.Trace("configuring image gc max age in docker runtime client")
+
+	return func(c *client) error {
+		// check if the max age provided is negative
+		if age < 0 {
+			return fmt.Errorf("invalid image gc max age provided: %s", age)
+		}
+
+		// set the runtime image gc max age in the docker client
+		c.config.GC.MaxAge = age
+
+		return nil
+	}
+}
+
+// WithImageGCInterval sets the Docker minimum duration between
+// removing unused images after builds in the runtime client.
+func WithImageGCInterval(interval time.Duration) ClientOpt {
+	logrus.Trace("configuring image gc interval in docker runtime client")
+
+	return func(c *client) error {
+		// check if the interval provided is empty
+		if interval == 0 {
+			return nil
+		}
+
+		// check if the interval provided is negative
+		if interval < 0 {
+			return fmt.Errorf("invalid image gc interval provided: %s", interval)
+		}
+
+		// set the runtime image gc interval in the docker client
+		c.config.GC.Interval = interval
+
+		return nil
+	}
+}
+
+// WithImageGCThresholds sets the Docker high and low water marks for the
+// disk usage of images used by image garbage collection in the runtime client.
+func WithImageGCThresholds(high, low string) ClientOpt {
+	logrus.Trace("configuring image gc thresholds in docker runtime client")
+
+	return func(c *client) error {
+		// check if the high water mark provided is empty
+		if len(high) == 0 {
+			return nil
+		}
+
+		// parse the high water mark provided
+		//
+		// https://pkg.go.dev/github.com/docker/go-units#RAMInBytes
+		_high, err := units.RAMInBytes(high)
+		if err != nil {
+			return fmt.Errorf("invalid image gc high water mark provided: %w", err)
+		}
+
+		// set the runtime image gc high water mark in the docker client
+		c.config.GC.HighWater = _high
+
+		// check if the low water mark provided is empty
+		if len(low) == 0 {
+			return nil
+		}
+
+		// parse the low water mark provided
+		//
+		// https://pkg.go.dev/github.com/docker/go-units#RAMInBytes
+		_low, err := units.RAMInBytes(low)
+		if err != nil {
+			return fmt.Errorf("invalid image gc low water mark provided: %w", err)
+		}
+
+		// check if the low water mark is above the high water mark
+		if _low > _high {
+			return fmt.Errorf("image gc low water mark %s exceeds high water mark %s", low, high)
+		}
+
+		// set the runtime image gc low water mark in the docker client
+		c.config.GC.LowWater = _low
+
+		return nil
+	}
+}
+
+// WithImageGCProtectedImages sets the Docker image patterns never
+// removed by image garbage collection in the runtime client.
+func WithImageGCProtectedImages(images []string) ClientOpt {
+	logrus.Trace("configuring image gc protected images in docker runtime client")
+
+	return func(c *client) error {
+		// set the runtime image gc protected images in the docker client
+		c.config.GC.Protected = images
+
+		return nil
+	}
+}
+
+// WithPlatform sets the Docker default platform, in the form
+// <os>/<arch>[/<variant>], for images in the runtime client.
+func WithPlatform(platform string) ClientOpt {
+	logrus.Trace("configuring platform in docker runtime client")
+
+	return func(c *client) error {
+		// parse the platform provided
+		//
+		// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/image#ParsePlatform
+		p, err := image.ParsePlatform(platform)
+		if err != nil {
+			return err
+		}
+
+		// set the runtime platform in the docker client
+		c.config.Platform = p
+
+		return nil
+	}
+}
+
+// WithLogLimits sets the Docker limits on the bytes (i.e. 10MB)
+// and lines of the logs for each container in the runtime client.
+func WithLogLimits(size string, lines int64) ClientOpt {
+	logrus.Trace("configuring log limits in docker runtime client")
+
+	return func(c *client) error {
+		// check if the lines limit provided is negative
+		if lines < 0 {
+			return fmt.Errorf("invalid log limit on lines provided: %d", lines)
+		}
+
+		// set the runtime log limit on lines in the docker client
+		c.config.LogLimit.Lines = lines
+
+		// check if the bytes limit provided is empty
+		if len(size) == 0 {
+			return nil
+		}
+
+		// parse the bytes limit provided
+		//
+		// https://pkg.go.dev/github.com/docker/go-units#RAMInBytes
+		_bytes, err := units.RAMInBytes(size)
+		if err != nil {
+			return fmt.Errorf("invalid log limit on bytes provided: %w", err)
+		}
+
+		// set the runtime log limit on bytes in the docker client
+		c.config.LogLimit.Bytes = _bytes
+
+		return nil
+	}
+}
+
+// WithLogLimitTail sets the Docker number of lines retained from
+// the end of the logs for a container that exceeds the log
+// limits in the runtime client.
+func WithLogLimitTail(lines int) ClientOpt {
+	logrus.Trace("configuring log limit tail in docker runtime client")
+
+	return func(c *client) error {
+		// check if the tail provided is negative
+		if lines < 0 {
+			return fmt.Errorf("invalid log limit tail provided: %d", lines)
+		}
+
+		// set the runtime log limit tail in the docker client
+		c.config.LogLimit.Tail = lines
+
+		return nil
+	}
+}
+
+// WithLogLimitKill sets the Docker option to kill a container
+// that exceeds the log limits in the runtime client.
+func WithLogLimitKill(kill bool) ClientOpt {
+	logrus.Trace("configuring log limit kill in docker runtime client")
+
+	return func(c *client) error {
+		// set the runtime log limit kill in the docker client
+		c.config.LogLimit.Kill = kill
+
+		return nil
+	}
+}
+
+// WithSecretFiles sets the Docker list of secret names to deliver
+// as files in a volume for the container, rather than in the environment,
+// in the runtime client. The volume is stored on the disk for the host
+// until the container is removed.
+func WithSecretFiles(names []string) ClientOpt {
+	logrus.Trace("configuring secret files in docker runtime client")
+
+	return func(c *client) error {
+		// set the runtime secret files in the docker client
+		c.config.SecretFiles = names
+
+		return nil
+	}
+}
+
+// WithWorkerID sets the Docker worker ID labeled on
+// resources for builds in the runtime client.
+//
+// The hostname is used when no worker ID is provided.
+func WithWorkerID(id string) ClientOpt {
+	logrus.Trace("configuring worker ID in docker runtime client")
+
+	return func(c *client) error {
+		// check if the worker ID provided is empty
+		if len(id) == 0 {
+			return nil
+		}
+
+		// set the runtime worker ID in the docker client
+		c.config.WorkerID = id
+
+		return nil
+	}
+}
+
+// WithHost sets the Docker host for the daemon in the runtime client.
+//
+// The host may be a unix://, tcp://, npipe:// or ssh:// host. For an
+// ssh:// host, the ssh command connects to the daemon with the docker
+// CLI on the remote host. The DOCKER_HOST environment variable is
+// used when no host is provided.
+func WithHost(host string) ClientOpt {
+	logrus.Trace("configuring host in docker runtime client")
+
+	return func(c *client) error {
+		// check if the host provided is empty
+		if len(host) == 0 {
+			return nil
+		}
+
+		// check if the host provided is ssh://
+		if isSSH(host) {
+			_, err := sshArgs(host)
+			if err != nil {
+				return err
+			}
+		} else {
+			// https://godoc.org/github.com/docker/docker/client#ParseHostURL
+			_, err := docker.ParseHostURL(host)
+			if err != nil {
+				return fmt.Errorf("invalid host provided: %w", err)
+			}
+		}
+
+		// set the runtime host in the docker client
+		c.config.Host = host
+
+		return nil
+	}
+}
+
+// WithTLS sets the Docker paths to the certificate authority, and the
+// certificate and key for the client, for connecting to the daemon
+// with TLS in the runtime client.
+//
+// The DOCKER_CERT_PATH environment variable is used
+// when no certificates are provided.
+func WithTLS(ca, cert, key string) ClientOpt {
+	logrus.Trace("configuring tls in docker runtime client")
+
+	return func(c *client) error {
+		// check if the certificates provided are empty
+		if len(ca) == 0 && len(cert) == 0 && len(key) == 0 {
+			return nil
+		}
+
+		// check if the certificate and key are provided together
+		if (len(cert) == 0) != (len(key) == 0) {
+			return fmt.Errorf("tls certificate and key must be provided together")
+		}
+
+		// iterate through each path for the certificates provided
+		for _, path := range []string{ca, cert, key} {
+			// check if the path is empty
+			if len(path) == 0 {
+				continue
+			}
+
+			// check if the path exists
+			_, err := os.Stat(path)
+			if err != nil {
+				return fmt.Errorf("invalid tls certificate provided: %w", err)
+			}
+		}
+
+		// set the runtime tls certificates in the docker client
+		c.config.TLS = &tlsConfig{
+			CA:   ca,
+			Cert: cert,
+			Key:  key,
+		}
+
+		return nil
+	}
+}
+
+// WithTimeout sets the Docker timeout for
+// requests to the daemon in the runtime client.
+//
+// The timeout applies to connecting to the daemon and
+// waiting for the headers for each response, so streams
+// from the daemon, like following logs or waiting for
+// containers, aren't limited by it. Daemons older than
+// API v1.30 only respond to waiting for a container once
+// it exits, so the timeout should be longer than any
+// container runs with them.
+func WithTimeout(timeout time.Duration) ClientOpt {
+	logrus.Trace("configuring timeout in docker runtime client")
+
+	return func(c *client) error {
+		// check if the timeout provided is negative
+		if timeout < 0 {
+			return fmt.Errorf("invalid timeout provided: %s", timeout)
+		}
+
+		// set the runtime timeout in the docker client
+		c.config.Timeout = timeout
+
+		return nil
+	}
+}
+
+// WithNetworkDriver sets the Docker driver for
+// the network for builds in the runtime client.
+func WithNetworkDriver(driver string) ClientOpt {
+	logrus.Trace("configuring network driver in docker runtime client")
+
+	return func(c *client) error {
+		// check if the network driver provided is empty
+		if len(driver) == 0 {
+			return nil
+		}
+
+		// set the runtime network driver in the docker client
+		c.config.Network.Driver = driver
+
+		return nil
+	}
+}
+
+// WithNetworkSubnets sets the Docker subnet pools, in the
+// form <cidr>[=<size>], for the network for builds in the
+// runtime client. A subnet from the pools is allocated to
+// the network for each build.
+func WithNetworkSubnets(pools []string) ClientOpt {
+	logrus.Trace("configuring network subnets in docker runtime client")
+
+	return func(c *client) error {
+		// parse the subnet pools provided
+		//
+		// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/network#ParsePools
+		_pools, err := network.ParsePools(pools)
+		if err != nil {
+			return err
+		}
+
+		// set the runtime network subnets in the docker client
+		c.config.Network.Subnets = _pools
+
+		return nil
+	}
+}
+
+// WithNetworkInternal sets the Docker internal mode,
+// restricting external access, for the network for
+// builds in the runtime client.
+func WithNetworkInternal(internal bool) ClientOpt {
+	logrus.Trace("configuring network internal mode in docker runtime client")
+
+	return func(c *client) error {
+		// set the runtime network internal mode in the docker client
+		c.config.Network.Internal = internal
+
+		return nil
+	}
+}
+
+// WithNetworkIPv6 sets the Docker IPv6 mode for
+// the network for builds in the runtime client.
+func WithNetworkIPv6(ipv6 bool) ClientOpt {
+	logrus.Trace("configuring network ipv6 in docker runtime client")
+
+	return func(c *client) error {
+		// set the runtime network ipv6 in the docker client
+		c.config.Network.IPv6 = ipv6
+
+		return nil
+	}
+}
+
+// WithNetworkDNS sets the Docker DNS servers and search
+// domains for containers on the network for builds in
+// the runtime client.
+func WithNetworkDNS(servers, search []string) ClientOpt {
+	logrus.Trace("configuring network dns in docker runtime client")
+
+	return func(c *client) error {
+		// validate the dns servers provided
+		//
+		// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/network#ValidateDNS
+		err := network.ValidateDNS(servers)
+		if err != nil {
+			return err
+		}
+
+		// set the runtime network dns in the docker client
+		c.config.Network.DNS = servers
+		c.config.Network.DNSSearch = search
+
+		return nil
+	}
+}
+
+// WithNetworkExtraHosts sets the Docker extra hosts, in the
+// form <host>:<ip>, for containers on the network for
+// builds in the runtime client.
+func WithNetworkExtraHosts(hosts []string) ClientOpt {
+	logrus.Trace("configuring network extra hosts in docker runtime client")
+
+	return func(c *client) error {
+		// validate the extra hosts provided
+		//
+		// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/network#ValidateExtraHosts
+		err := network.ValidateExtraHosts(hosts)
+		if err != nil {
+			return err
+		}
+
+		// set the runtime network extra hosts in the docker client
+		c.config.Network.ExtraHosts = hosts
+
+		return nil
+	}
+}
+
+// WithEgressPolicy sets the Docker egress policy in the runtime
+// client. When enabled, the network for builds is restricted
+// from external access, and the container running the proxy,
+// in the form <container>:<port>, is connected to the network
+// for containers to reach the destinations it allows.
+func WithEgressPolicy(enabled bool, proxy string) ClientOpt {
+	logrus.Trace("configuring egress policy in docker runtime client")
+
+	return func(c *client) error {
+		// set the runtime egress policy in the docker client
+		c.config.Network.Egress = enabled
+
+		// check if the egress proxy provided is empty
+		if len(proxy) == 0 {
+			return nil
+		}
+
+		// parse the egress proxy provided
+		_proxy, err := parseProxy(proxy)
+		if err != nil {
+			return err
+		}
+
+		// set the runtime egress proxy in the docker client
+		c.config.Network.Proxy = _proxy
+
+		return nil
+	}
+}
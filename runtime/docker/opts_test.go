@@ -5,8 +5,14 @@
 package docker
 
 import (
+	"os"
 	"reflect"
 	"testing"
+	"time"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/go-vela/pkg-runtime/internal/logs"
 )
 
 func TestDocker_ClientOpt_WithPrivilegedImages(t *testing.T) {
@@ -72,3 +78,1128 @@ func TestDocker_ClientOpt_WithHostVolumes(t *testing.T) {
 		}
 	}
 }
+
+func TestDocker_ClientOpt_WithImagePolicy(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		allowed []string
+		denied  []string
+	}{
+		{
+			allowed: []string{"target/vela-*"},
+			denied:  []string{"alpine"},
+		},
+		{
+			allowed: []string{},
+			denied:  []string{},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_service, err := New(
+			WithImagePolicy(test.allowed, test.denied),
+		)
+
+		if err != nil {
+			t.Errorf("WithImagePolicy returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_service.config.Policy.AllowedImages, test.allowed) {
+			t.Errorf("WithImagePolicy allowed is %v, want %v", _service.config.Policy.AllowedImages, test.allowed)
+		}
+
+		if !reflect.DeepEqual(_service.config.Policy.DeniedImages, test.denied) {
+			t.Errorf("WithImagePolicy denied is %v, want %v", _service.config.Policy.DeniedImages, test.denied)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithRegistryPolicy(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		allowed []string
+		denied  []string
+	}{
+		{
+			allowed: []string{"docker.company.com"},
+			denied:  []string{"docker.io"},
+		},
+		{
+			allowed: []string{},
+			denied:  []string{},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_service, err := New(
+			WithRegistryPolicy(test.allowed, test.denied),
+		)
+
+		if err != nil {
+			t.Errorf("WithRegistryPolicy returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_service.config.Policy.AllowedRegistries, test.allowed) {
+			t.Errorf("WithRegistryPolicy allowed is %v, want %v", _service.config.Policy.AllowedRegistries, test.allowed)
+		}
+
+		if !reflect.DeepEqual(_service.config.Policy.DeniedRegistries, test.denied) {
+			t.Errorf("WithRegistryPolicy denied is %v, want %v", _service.config.Policy.DeniedRegistries, test.denied)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithRegistryMirrors(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		mirrors []string
+		want    map[string]string
+	}{
+		{
+			failure: false,
+			mirrors: []string{"docker.io=mirror.company.com:5000"},
+			want:    map[string]string{"docker.io": "mirror.company.com:5000"},
+		},
+		{
+			failure: false,
+			mirrors: []string{},
+			want:    map[string]string{},
+		},
+		{
+			failure: true,
+			mirrors: []string{"docker.io"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_service, err := New(
+			WithRegistryMirrors(test.mirrors),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithRegistryMirrors should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithRegistryMirrors returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_service.config.Mirrors, test.want) {
+			t.Errorf("WithRegistryMirrors is %v, want %v", _service.config.Mirrors, test.want)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithRegistryMirrorFallback(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		fallback bool
+		want     bool
+	}{
+		{
+			fallback: true,
+			want:     true,
+		},
+		{
+			fallback: false,
+			want:     false,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_service, err := New(
+			WithRegistryMirrorFallback(test.fallback),
+		)
+
+		if err != nil {
+			t.Errorf("WithRegistryMirrorFallback returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_service.config.MirrorFallback, test.want) {
+			t.Errorf("WithRegistryMirrorFallback is %v, want %v", _service.config.MirrorFallback, test.want)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithImageVerification(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		keys    []string
+		images  []string
+		want    bool
+	}{
+		{
+			failure: false,
+			keys:    []string{},
+			images:  []string{},
+			want:    false,
+		},
+		{
+			failure: true,
+			keys:    []string{},
+			images:  []string{"target/vela-*"},
+		},
+		{
+			failure: true,
+			keys:    []string{"testdata/notfound.pub"},
+			images:  []string{"target/vela-*"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_service, err := New(
+			WithImageVerification(test.keys, test.images),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithImageVerification should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithImageVerification returned err: %v", err)
+		}
+
+		if (_service.config.Verifier != nil) != test.want {
+			t.Errorf("WithImageVerification is %v, want enabled %v", _service.config.Verifier, test.want)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithImageGCMaxAge(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		age     time.Duration
+		want    time.Duration
+	}{
+		{
+			failure: false,
+			age:     24 * time.Hour,
+			want:    24 * time.Hour,
+		},
+		{
+			failure: false,
+			age:     0,
+			want:    0,
+		},
+		{
+			failure: true,
+			age:     -1 * time.Hour,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_service, err := New(
+			WithImageGCMaxAge(test.age),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithImageGCMaxAge should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithImageGCMaxAge returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_service.config.GC.MaxAge, test.want) {
+			t.Errorf("WithImageGCMaxAge is %v, want %v", _service.config.GC.MaxAge, test.want)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithImageGCInterval(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure  bool
+		interval time.Duration
+		want     time.Duration
+	}{
+		{
+			failure:  false,
+			interval: time.Hour,
+			want:     time.Hour,
+		},
+		{
+			failure:  false,
+			interval: 0,
+			want:     defaultGCInterval,
+		},
+		{
+			failure:  true,
+			interval: -1 * time.Hour,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_service, err := New(
+			WithImageGCInterval(test.interval),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithImageGCInterval should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithImageGCInterval returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_service.config.GC.Interval, test.want) {
+			t.Errorf("WithImageGCInterval is %v, want %v", _service.config.GC.Interval, test.want)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithImageGCThresholds(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		high    string
+		low     string
+		want    *gcConfig
+	}{
+		{
+			failure: false,
+			high:    "20GB",
+			low:     "15GB",
+			want:    &gcConfig{Interval: defaultGCInterval, HighWater: 20 << 30, LowWater: 15 << 30},
+		},
+		{
+			failure: false,
+			high:    "20GB",
+			low:     "",
+			want:    &gcConfig{Interval: defaultGCInterval, HighWater: 20 << 30},
+		},
+		{
+			failure: false,
+			high:    "",
+			low:     "",
+			want:    &gcConfig{Interval: defaultGCInterval},
+		},
+		{
+			failure: true,
+			high:    "foo",
+			low:     "",
+		},
+		{
+			failure: true,
+			high:    "20GB",
+			low:     "foo",
+		},
+		{
+			failure: true,
+			high:    "15GB",
+			low:     "20GB",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_service, err := New(
+			WithImageGCThresholds(test.high, test.low),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithImageGCThresholds should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithImageGCThresholds returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_service.config.GC, test.want) {
+			t.Errorf("WithImageGCThresholds is %v, want %v", _service.config.GC, test.want)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithImageGCProtectedImages(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		images []string
+		want   []string
+	}{
+		{
+			images: []string{"target/vela-git"},
+			want:   []string{"target/vela-git"},
+		},
+		{
+			images: []string{},
+			want:   []string{},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_service, err := New(
+			WithImageGCProtectedImages(test.images),
+		)
+
+		if err != nil {
+			t.Errorf("WithImageGCProtectedImages returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_service.config.GC.Protected, test.want) {
+			t.Errorf("WithImageGCProtectedImages is %v, want %v", _service.config.GC.Protected, test.want)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithPlatform(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure  bool
+		platform string
+		want     *specs.Platform
+	}{
+		{
+			failure:  false,
+			platform: "linux/arm64",
+			want:     &specs.Platform{OS: "linux", Architecture: "arm64"},
+		},
+		{
+			failure:  false,
+			platform: "",
+			want:     nil,
+		},
+		{
+			failure:  true,
+			platform: "foo/bar",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_service, err := New(
+			WithPlatform(test.platform),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithPlatform should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithPlatform returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_service.config.Platform, test.want) {
+			t.Errorf("WithPlatform is %v, want %v", _service.config.Platform, test.want)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithLogLimits(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		size    string
+		lines   int64
+		want    *logs.Limit
+	}{
+		{
+			failure: false,
+			size:    "10MB",
+			lines:   1000,
+			want:    &logs.Limit{Bytes: 10 << 20, Lines: 1000},
+		},
+		{
+			failure: false,
+			size:    "",
+			lines:   0,
+			want:    &logs.Limit{},
+		},
+		{
+			failure: true,
+			size:    "foo",
+		},
+		{
+			failure: true,
+			lines:   -1,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithLogLimits(test.size, test.lines),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithLogLimits should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithLogLimits returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_engine.config.LogLimit, test.want) {
+			t.Errorf("WithLogLimits is %v, want %v", _engine.config.LogLimit, test.want)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithLogLimitTail(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		tail    int
+		want    int
+	}{
+		{
+			failure: false,
+			tail:    100,
+			want:    100,
+		},
+		{
+			failure: true,
+			tail:    -1,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithLogLimitTail(test.tail),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithLogLimitTail should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithLogLimitTail returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_engine.config.LogLimit.Tail, test.want) {
+			t.Errorf("WithLogLimitTail is %v, want %v", _engine.config.LogLimit.Tail, test.want)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithLogLimitKill(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		kill bool
+		want bool
+	}{
+		{
+			kill: true,
+			want: true,
+		},
+		{
+			kill: false,
+			want: false,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithLogLimitKill(test.kill),
+		)
+
+		if err != nil {
+			t.Errorf("WithLogLimitKill returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_engine.config.LogLimit.Kill, test.want) {
+			t.Errorf("WithLogLimitKill is %v, want %v", _engine.config.LogLimit.Kill, test.want)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithSecretFiles(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		files []string
+		want  []string
+	}{
+		{
+			files: []string{"docker_password", "npm_token"},
+			want:  []string{"docker_password", "npm_token"},
+		},
+		{
+			files: nil,
+			want:  nil,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithSecretFiles(test.files),
+		)
+
+		if err != nil {
+			t.Errorf("WithSecretFiles returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_engine.config.SecretFiles, test.want) {
+			t.Errorf("WithSecretFiles is %v, want %v", _engine.config.SecretFiles, test.want)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithWorkerID(t *testing.T) {
+	// setup types
+	hostname, _ := os.Hostname()
+
+	// setup tests
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{
+			id:   "worker_1",
+			want: "worker_1",
+		},
+		{
+			id:   "",
+			want: hostname,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithWorkerID(test.id),
+		)
+
+		if err != nil {
+			t.Errorf("WithWorkerID returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_engine.config.WorkerID, test.want) {
+			t.Errorf("WithWorkerID is %v, want %v", _engine.config.WorkerID, test.want)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithHost(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		host    string
+		want    string
+	}{
+		{
+			failure: false,
+			host:    "tcp://127.0.0.1:2376",
+			want:    "tcp://127.0.0.1:2376",
+		},
+		{
+			failure: false,
+			host:    "unix:///run/user/1000/docker.sock",
+			want:    "unix:///run/user/1000/docker.sock",
+		},
+		{
+			failure: false,
+			host:    "ssh://vela@docker.example.com:2222",
+			want:    "http://docker.example.com",
+		},
+		{
+			failure: false,
+			host:    "",
+			want:    "unix:///var/run/docker.sock",
+		},
+		{
+			failure: true,
+			host:    "127.0.0.1:2376",
+		},
+		{
+			failure: true,
+			host:    "ssh://docker.example.com/var/run/docker.sock",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_service, err := New(
+			WithHost(test.host),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithHost for %s should have returned err", test.host)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithHost for %s returned err: %v", test.host, err)
+		}
+
+		if !reflect.DeepEqual(_service.config.Host, test.host) {
+			t.Errorf("WithHost is %v, want %v", _service.config.Host, test.host)
+		}
+
+		got := _service.Docker.DaemonHost()
+
+		if got != test.want {
+			t.Errorf("WithHost for %s daemon host is %v, want %v", test.host, got, test.want)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithTLS(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		host    string
+		ca      string
+		cert    string
+		key     string
+		want    *tlsConfig
+	}{
+		{
+			failure: false,
+			host:    "tcp://127.0.0.1:2376",
+			ca:      "testdata/tls/ca.pem",
+			cert:    "testdata/tls/cert.pem",
+			key:     "testdata/tls/key.pem",
+			want:    &tlsConfig{CA: "testdata/tls/ca.pem", Cert: "testdata/tls/cert.pem", Key: "testdata/tls/key.pem"},
+		},
+		{
+			failure: false,
+			host:    "tcp://127.0.0.1:2376",
+			ca:      "testdata/tls/ca.pem",
+			want:    &tlsConfig{CA: "testdata/tls/ca.pem"},
+		},
+		{
+			failure: false,
+			want:    nil,
+		},
+		{
+			failure: true,
+			host:    "tcp://127.0.0.1:2376",
+			ca:      "testdata/tls/ca.pem",
+			cert:    "testdata/tls/cert.pem",
+		},
+		{
+			failure: true,
+			host:    "tcp://127.0.0.1:2376",
+			ca:      "testdata/tls/notfound.pem",
+		},
+		{
+			failure: true,
+			host:    "tcp://127.0.0.1:2376",
+			ca:      "testdata/tls/key.pem",
+		},
+		{
+			failure: true,
+			host:    "ssh://docker.example.com",
+			ca:      "testdata/tls/ca.pem",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_service, err := New(
+			WithHost(test.host),
+			WithTLS(test.ca, test.cert, test.key),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithTLS should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithTLS returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_service.config.TLS, test.want) {
+			t.Errorf("WithTLS is %v, want %v", _service.config.TLS, test.want)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithTimeout(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		timeout time.Duration
+		want    time.Duration
+	}{
+		{
+			failure: false,
+			timeout: 30 * time.Second,
+			want:    30 * time.Second,
+		},
+		{
+			failure: false,
+			timeout: 0,
+			want:    0,
+		},
+		{
+			failure: true,
+			timeout: -1 * time.Second,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_service, err := New(
+			WithTimeout(test.timeout),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithTimeout should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithTimeout returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_service.config.Timeout, test.want) {
+			t.Errorf("WithTimeout is %v, want %v", _service.config.Timeout, test.want)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithNetworkDriver(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		driver string
+		want   string
+	}{
+		{
+			driver: "macvlan",
+			want:   "macvlan",
+		},
+		{
+			driver: "",
+			want:   "bridge",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithNetworkDriver(test.driver),
+		)
+
+		if err != nil {
+			t.Errorf("WithNetworkDriver returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_engine.config.Network.Driver, test.want) {
+			t.Errorf("WithNetworkDriver is %v, want %v", _engine.config.Network.Driver, test.want)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithNetworkSubnets(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		pools   []string
+		want    int
+	}{
+		{
+			failure: false,
+			pools:   []string{"10.200.0.0/16=24", "fd00:1234::/48"},
+			want:    2,
+		},
+		{
+			failure: false,
+			pools:   []string{},
+			want:    0,
+		},
+		{
+			failure: true,
+			pools:   []string{"10.200.0.0"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithNetworkSubnets(test.pools),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithNetworkSubnets should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithNetworkSubnets returned err: %v", err)
+		}
+
+		if len(_engine.config.Network.Subnets) != test.want {
+			t.Errorf("WithNetworkSubnets is %v, want %d pools", _engine.config.Network.Subnets, test.want)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithNetworkInternal(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		internal bool
+		want     bool
+	}{
+		{
+			internal: true,
+			want:     true,
+		},
+		{
+			internal: false,
+			want:     false,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithNetworkInternal(test.internal),
+		)
+
+		if err != nil {
+			t.Errorf("WithNetworkInternal returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_engine.config.Network.Internal, test.want) {
+			t.Errorf("WithNetworkInternal is %v, want %v", _engine.config.Network.Internal, test.want)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithNetworkIPv6(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		ipv6 bool
+		want bool
+	}{
+		{
+			ipv6: true,
+			want: true,
+		},
+		{
+			ipv6: false,
+			want: false,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithNetworkIPv6(test.ipv6),
+		)
+
+		if err != nil {
+			t.Errorf("WithNetworkIPv6 returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_engine.config.Network.IPv6, test.want) {
+			t.Errorf("WithNetworkIPv6 is %v, want %v", _engine.config.Network.IPv6, test.want)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithNetworkDNS(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		servers []string
+		search  []string
+	}{
+		{
+			failure: false,
+			servers: []string{"10.0.0.2", "10.0.0.3"},
+			search:  []string{"company.com"},
+		},
+		{
+			failure: false,
+			servers: nil,
+			search:  nil,
+		},
+		{
+			failure: true,
+			servers: []string{"dns.company.com"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithNetworkDNS(test.servers, test.search),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithNetworkDNS should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithNetworkDNS returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_engine.config.Network.DNS, test.servers) {
+			t.Errorf("WithNetworkDNS servers is %v, want %v", _engine.config.Network.DNS, test.servers)
+		}
+
+		if !reflect.DeepEqual(_engine.config.Network.DNSSearch, test.search) {
+			t.Errorf("WithNetworkDNS search is %v, want %v", _engine.config.Network.DNSSearch, test.search)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithNetworkExtraHosts(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		hosts   []string
+	}{
+		{
+			failure: false,
+			hosts:   []string{"registry.company.com:10.0.0.5", "host.docker.internal:host-gateway"},
+		},
+		{
+			failure: false,
+			hosts:   nil,
+		},
+		{
+			failure: true,
+			hosts:   []string{"registry.company.com"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithNetworkExtraHosts(test.hosts),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithNetworkExtraHosts should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithNetworkExtraHosts returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_engine.config.Network.ExtraHosts, test.hosts) {
+			t.Errorf("WithNetworkExtraHosts is %v, want %v", _engine.config.Network.ExtraHosts, test.hosts)
+		}
+	}
+}
+
+func TestDocker_ClientOpt_WithEgressPolicy(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		enabled bool
+		proxy   string
+		want    *egressProxy
+	}{
+		{
+			failure: false,
+			enabled: true,
+			proxy:   "vela-proxy:3128",
+			want:    &egressProxy{Container: "vela-proxy", Port: 3128},
+		},
+		{
+			failure: false,
+			enabled: true,
+			proxy:   "",
+			want:    nil,
+		},
+		{
+			failure: false,
+			enabled: false,
+			proxy:   "",
+			want:    nil,
+		},
+		{
+			failure: true,
+			enabled: true,
+			proxy:   "vela-proxy",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithEgressPolicy(test.enabled, test.proxy),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithEgressPolicy should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithEgressPolicy returned err: %v", err)
+		}
+
+		if _engine.config.Network.Egress != test.enabled {
+			t.Errorf("WithEgressPolicy is %v, want %v", _engine.config.Network.Egress, test.enabled)
+		}
+
+		if !reflect.DeepEqual(_engine.config.Network.Proxy, test.want) {
+			t.Errorf("WithEgressPolicy proxy is %+v, want %+v", _engine.config.Network.Proxy, test.want)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/go-vela/types/pipeline"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// LabelBuild represents the label containing
+	// the ID of the build for a resource.
+	LabelBuild = "vela.build"
+	// LabelWorker represents the label containing
+	// the ID of the worker that created a resource.
+	LabelWorker = "vela.worker"
+	// LabelCreated represents the label containing
+	// the time, in RFC 3339 format, a resource was created.
+	LabelCreated = "vela.created"
+)
+
+// ReapReport represents the result of removing
+// orphaned resources for builds from the host.
+type ReapReport struct {
+	// specifies the list of containers removed from the host
+	Containers []string
+	// specifies the list of networks removed from the host
+	Networks []string
+	// specifies the list of volumes removed from the host
+	Volumes []string
+	// specifies the resources were only listed and not removed
+	DryRun bool
+}
+
+// labels is a helper function to create the labels
+// for a resource created for the pipeline build.
+func (c *client) labels(b *pipeline.Build) map[string]string {
+	return map[string]string{
+		LabelBuild:   b.ID,
+		LabelWorker:  c.config.WorkerID,
+		LabelCreated: time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// Reap removes the containers, networks and volumes for builds,
+// created by the worker, that are older than the duration provided.
+//
+// These resources are orphaned when the worker stops before removing
+// the build, so the duration should exceed the timeout for builds.
+// When dry run is enabled, the resources are only listed.
+func (c *client) Reap(ctx context.Context, olderThan time.Duration, dryRun bool) (*ReapReport, error) {
+	logrus.Tracef("reaping resources older than %s", olderThan)
+
+	report := &ReapReport{DryRun: dryRun}
+
+	// check if the duration provided is negative
+	if olderThan < 0 {
+		return nil, fmt.Errorf("invalid duration provided for reaping: %s", olderThan)
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+
+	// create filters for the resources created by the worker
+	//
+	// https://godoc.org/github.com/docker/docker/api/types/filters#Args
+	args := filters.NewArgs(
+		filters.Arg("label", fmt.Sprintf("%s=%s", LabelWorker, c.config.WorkerID)),
+		filters.Arg("label", LabelBuild),
+	)
+
+	// send API call to list the containers
+	//
+	// https://godoc.org/github.com/docker/docker/client#Client.ContainerList
+	containers, err := c.Docker.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: args})
+	if err != nil {
+		return nil, err
+	}
+
+	// the containers are removed first to
+	// release the networks and volumes
+	for _, container := range containers {
+		if !stale(container.Labels, cutoff) {
+			continue
+		}
+
+		if !dryRun {
+			logrus.Debugf("removing orphaned container %s", container.ID)
+
+			// send API call to remove the container
+			//
+			// https://godoc.org/github.com/docker/docker/client#Client.ContainerRemove
+			err := c.Docker.ContainerRemove(ctx, container.ID, types.ContainerRemoveOptions{
+				Force:         true,
+				RemoveVolumes: true,
+			})
+			if err != nil {
+				logrus.Errorf("unable to remove orphaned container %s: %v", container.ID, err)
+
+				continue
+			}
+		}
+
+		report.Containers = append(report.Containers, containerName(container))
+	}
+
+	// send API call to list the networks
+	//
+	// https://godoc.org/github.com/docker/docker/client#Client.NetworkList
+	networks, err := c.Docker.NetworkList(ctx, types.NetworkListOptions{Filters: args})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, network := range networks {
+		if !stale(network.Labels, cutoff) {
+			continue
+		}
+
+		if !dryRun {
+			logrus.Debugf("removing orphaned network %s", network.Name)
+
+			// check if a proxy is provided for restricted egress
+			if c.config.Network.Egress && c.config.Network.Proxy != nil {
+				// disconnect the proxy from the network
+				c.disconnectProxy(ctx, network.ID)
+			}
+
+			// send API call to remove the network
+			//
+			// https://godoc.org/github.com/docker/docker/client#Client.NetworkRemove
+			err := c.Docker.NetworkRemove(ctx, network.ID)
+			if err != nil {
+				logrus.Errorf("unable to remove orphaned network %s: %v", network.Name, err)
+
+				continue
+			}
+		}
+
+		report.Networks = append(report.Networks, network.Name)
+	}
+
+	// send API call to list the volumes
+	//
+	// https://godoc.org/github.com/docker/docker/client#Client.VolumeList
+	volumes, err := c.Docker.VolumeList(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, volume := range volumes.Volumes {
+		if !stale(volume.Labels, cutoff) {
+			continue
+		}
+
+		if !dryRun {
+			logrus.Debugf("removing orphaned volume %s", volume.Name)
+
+			// send API call to remove the volume
+			//
+			// https://godoc.org/github.com/docker/docker/client#Client.VolumeRemove
+			err := c.Docker.VolumeRemove(ctx, volume.Name, true)
+			if err != nil {
+				logrus.Errorf("unable to remove orphaned volume %s: %v", volume.Name, err)
+
+				continue
+			}
+		}
+
+		report.Volumes = append(report.Volumes, volume.Name)
+	}
+
+	return report, nil
+}
+
+// stale is a helper function to check if the resource
+// was created before the cutoff from the labels.
+//
+// Resources with a missing or invalid creation
+// time are never considered stale.
+func stale(labels map[string]string, cutoff time.Time) bool {
+	created, err := time.Parse(time.RFC3339, labels[LabelCreated])
+	if err != nil {
+		return false
+	}
+
+	return created.Before(cutoff)
+}
+
+// containerName is a helper function to capture
+// the name of the container for the report.
+func containerName(container types.Container) string {
+	// Docker prefixes the name of the container with a slash
+	if len(container.Names) > 0 && len(container.Names[0]) > 1 {
+		return container.Names[0][1:]
+	}
+
+	return container.ID
+}
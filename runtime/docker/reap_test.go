@@ -0,0 +1,208 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/volume"
+	docker "github.com/docker/docker/client"
+)
+
+// reapDocker represents a Docker client with a fixed
+// set of resources for testing removal.
+type reapDocker struct {
+	docker.CommonAPIClient
+
+	containers []types.Container
+	networks   []types.NetworkResource
+	volumes    []*types.Volume
+	filters    filters.Args
+	removed    []string
+}
+
+// ContainerList returns the fixed set of containers.
+func (d *reapDocker) ContainerList(ctx context.Context, opts types.ContainerListOptions) ([]types.Container, error) {
+	d.filters = opts.Filters
+
+	return d.containers, nil
+}
+
+// ContainerRemove records the container removed.
+func (d *reapDocker) ContainerRemove(ctx context.Context, ctn string, opts types.ContainerRemoveOptions) error {
+	d.removed = append(d.removed, "container/"+ctn)
+
+	return nil
+}
+
+// NetworkList returns the fixed set of networks.
+func (d *reapDocker) NetworkList(ctx context.Context, opts types.NetworkListOptions) ([]types.NetworkResource, error) {
+	return d.networks, nil
+}
+
+// NetworkRemove records the network removed.
+func (d *reapDocker) NetworkRemove(ctx context.Context, id string) error {
+	d.removed = append(d.removed, "network/"+id)
+
+	return nil
+}
+
+// VolumeList returns the fixed set of volumes.
+func (d *reapDocker) VolumeList(ctx context.Context, args filters.Args) (volume.VolumeListOKBody, error) {
+	return volume.VolumeListOKBody{Volumes: d.volumes}, nil
+}
+
+// VolumeRemove records the volume removed.
+func (d *reapDocker) VolumeRemove(ctx context.Context, id string, force bool) error {
+	d.removed = append(d.removed, "volume/"+id)
+
+	return nil
+}
+
+func TestDocker_Reap(t *testing.T) {
+	// setup types
+	old := map[string]string{
+		LabelBuild:   "github_octocat_1",
+		LabelWorker:  "worker_1",
+		LabelCreated: time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339),
+	}
+
+	recent := map[string]string{
+		LabelBuild:   "github_octocat_2",
+		LabelWorker:  "worker_1",
+		LabelCreated: time.Now().Add(-1 * time.Hour).UTC().Format(time.RFC3339),
+	}
+
+	invalid := map[string]string{
+		LabelBuild:  "github_octocat_3",
+		LabelWorker: "worker_1",
+	}
+
+	newDocker := func(engine *client) *reapDocker {
+		return &reapDocker{
+			CommonAPIClient: engine.Docker,
+			containers: []types.Container{
+				{ID: "1", Names: []string{"/step_github_octocat_1_clone"}, Labels: old},
+				{ID: "2", Names: []string{"/step_github_octocat_2_clone"}, Labels: recent},
+				{ID: "3", Names: []string{"/step_github_octocat_3_clone"}, Labels: invalid},
+			},
+			networks: []types.NetworkResource{
+				{ID: "n1", Name: "github_octocat_1", Labels: old},
+				{ID: "n2", Name: "github_octocat_2", Labels: recent},
+			},
+			volumes: []*types.Volume{
+				{Name: "github_octocat_1", Labels: old},
+				{Name: "github_octocat_2", Labels: recent},
+			},
+		}
+	}
+
+	// setup tests
+	tests := []struct {
+		name      string
+		dryRun    bool
+		olderThan time.Duration
+		want      *ReapReport
+		removed   []string
+	}{
+		{
+			name:      "reap",
+			olderThan: 24 * time.Hour,
+			want: &ReapReport{
+				Containers: []string{"step_github_octocat_1_clone"},
+				Networks:   []string{"github_octocat_1"},
+				Volumes:    []string{"github_octocat_1"},
+			},
+			removed: []string{"container/1", "network/n1", "volume/github_octocat_1"},
+		},
+		{
+			name:      "dry run",
+			dryRun:    true,
+			olderThan: 24 * time.Hour,
+			want: &ReapReport{
+				Containers: []string{"step_github_octocat_1_clone"},
+				Networks:   []string{"github_octocat_1"},
+				Volumes:    []string{"github_octocat_1"},
+				DryRun:     true,
+			},
+			removed: nil,
+		},
+		{
+			name:      "nothing older",
+			olderThan: 72 * time.Hour,
+			want:      &ReapReport{},
+			removed:   nil,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := NewMock(WithWorkerID("worker_1"))
+		if err != nil {
+			t.Errorf("unable to create runtime engine: %v", err)
+		}
+
+		_docker := newDocker(_engine)
+		_engine.Docker = _docker
+
+		got, err := _engine.Reap(context.Background(), test.olderThan, test.dryRun)
+		if err != nil {
+			t.Errorf("Reap for %s returned err: %v", test.name, err)
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Reap for %s is %+v, want %+v", test.name, got, test.want)
+		}
+
+		if !reflect.DeepEqual(_docker.removed, test.removed) {
+			t.Errorf("Reap for %s removed %v, want %v", test.name, _docker.removed, test.removed)
+		}
+
+		if !_docker.filters.ExactMatch("label", "vela.worker=worker_1") {
+			t.Errorf("Reap for %s filters are %v, want worker label", test.name, _docker.filters)
+		}
+	}
+}
+
+func TestDocker_Reap_Failure(t *testing.T) {
+	// setup Docker
+	_engine, err := NewMock()
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	_, err = _engine.Reap(context.Background(), -1*time.Hour, false)
+	if err == nil {
+		t.Errorf("Reap should have returned err")
+	}
+}
+
+func TestDocker_Labels(t *testing.T) {
+	// setup Docker
+	_engine, err := NewMock(WithWorkerID("worker_1"))
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	got := _engine.labels(_pipeline)
+
+	if got[LabelBuild] != _pipeline.ID {
+		t.Errorf("labels build is %s, want %s", got[LabelBuild], _pipeline.ID)
+	}
+
+	if got[LabelWorker] != "worker_1" {
+		t.Errorf("labels worker is %s, want worker_1", got[LabelWorker])
+	}
+
+	created, err := time.Parse(time.RFC3339, got[LabelCreated])
+	if err != nil || time.Since(created) > time.Minute {
+		t.Errorf("labels created is %s, want now", got[LabelCreated])
+	}
+}
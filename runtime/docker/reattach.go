@@ -0,0 +1,104 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/go-vela/pkg-runtime/internal/image"
+	"github.com/go-vela/types/pipeline"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ReattachBuild rediscovers the resources for a pipeline build
+// still running on the host after the worker was restarted.
+//
+// The containers for the build are found by the build label,
+// and the images for them are recorded as used. The subnets
+// for the network for the build are recovered, so they aren't
+// allocated to another build until RemoveNetwork releases them.
+// Tailing the logs for each container found resumes after the
+// time provided, so lines already captured are not repeated.
+func (c *client) ReattachBuild(ctx context.Context, b *pipeline.Build, since time.Time) error {
+	logrus.Tracef("reattaching to build %s", b.ID)
+
+	// send API call to list the containers for the build
+	//
+	// https://godoc.org/github.com/docker/docker/client#Client.ContainerList
+	containers, err := c.Docker.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", fmt.Sprintf("%s=%s", LabelBuild, b.ID))),
+	})
+	if err != nil {
+		return err
+	}
+
+	// send API call to inspect the network for the build
+	//
+	// https://godoc.org/github.com/docker/docker/client#Client.NetworkInspect
+	n, err := c.Docker.NetworkInspect(ctx, b.ID, types.NetworkInspectOptions{})
+	if err != nil && len(containers) == 0 {
+		return fmt.Errorf("unable to find resources for build %s: %w", b.ID, err)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// check if the network was found with subnets allocated from the pools
+	if err == nil && len(c.config.Network.Subnets) > 0 {
+		subnets := networkSubnets(n)
+
+		if len(subnets) > 0 {
+			logrus.Debugf("recovering subnets %v for network %s", subnets, b.ID)
+
+			// record the subnets allocated to the network for the build
+			c.subnets[b.ID] = subnets
+		}
+	}
+
+	for _, container := range containers {
+		name := containerName(container)
+
+		logrus.Debugf("reattaching to container %s for build %s", name, b.ID)
+
+		// record the image was used for image garbage collection
+		c.used[image.Parse(container.Image)] = time.Now()
+
+		// record the time to resume tailing the logs from
+		if !since.IsZero() {
+			c.resume[name] = since
+		}
+	}
+
+	return nil
+}
+
+// resumeSince is a helper function to capture the time, in the
+// format for the Docker API, to resume tailing the logs for
+// the pipeline container from after reattaching to the build.
+//
+// The time is only used once, so tailing the logs
+// again captures all of the logs for the container.
+func (c *client) resumeSince(ctn *pipeline.Container) string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	since, ok := c.resume[ctn.ID]
+	if !ok {
+		return ""
+	}
+
+	delete(c.resume, ctn.ID)
+
+	// the logs since the time provided are inclusive,
+	// so resume after the line captured at the time
+	return since.Add(time.Nanosecond).Format(time.RFC3339Nano)
+}
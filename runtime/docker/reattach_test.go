@@ -0,0 +1,192 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	docker "github.com/docker/docker/client"
+
+	"github.com/go-vela/pkg-runtime/internal/image"
+	"github.com/go-vela/types/pipeline"
+)
+
+// reattachDocker represents a Docker client with a fixed set
+// of containers recording the options for capturing logs.
+type reattachDocker struct {
+	docker.CommonAPIClient
+
+	containers []types.Container
+	network    *types.NetworkResource
+	since      []string
+}
+
+// NetworkInspect returns the network for the build when one is provided.
+func (d *reattachDocker) NetworkInspect(ctx context.Context, id string, opts types.NetworkInspectOptions) (types.NetworkResource, error) {
+	if d.network == nil {
+		return d.CommonAPIClient.NetworkInspect(ctx, id, opts)
+	}
+
+	return *d.network, nil
+}
+
+// ContainerList returns the fixed set of containers.
+func (d *reattachDocker) ContainerList(ctx context.Context, opts types.ContainerListOptions) ([]types.Container, error) {
+	return d.containers, nil
+}
+
+// ContainerLogs records the time to capture the logs since.
+func (d *reattachDocker) ContainerLogs(ctx context.Context, ctn string, opts types.ContainerLogsOptions) (io.ReadCloser, error) {
+	d.since = append(d.since, opts.Since)
+
+	return ioutil.NopCloser(strings.NewReader("")), nil
+}
+
+func TestDocker_ReattachBuild(t *testing.T) {
+	// setup types
+	since := time.Date(2021, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	_engine, err := NewMock()
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	_docker := &reattachDocker{
+		CommonAPIClient: _engine.Docker,
+		containers: []types.Container{
+			{
+				ID:     "1",
+				Names:  []string{"/" + _container.ID},
+				Image:  "target/vela-git:v0.4.0",
+				Labels: map[string]string{LabelBuild: _pipeline.ID},
+			},
+		},
+	}
+	_engine.Docker = _docker
+
+	err = _engine.ReattachBuild(context.Background(), _pipeline, since)
+	if err != nil {
+		t.Errorf("ReattachBuild returned err: %v", err)
+	}
+
+	if _, ok := _engine.used[image.Parse("target/vela-git:v0.4.0")]; !ok {
+		t.Errorf("ReattachBuild did not record image was used")
+	}
+
+	// tail the logs for the container twice
+	for i := 0; i < 2; i++ {
+		rc, err := _engine.TailContainer(context.Background(), _container)
+		if err != nil {
+			t.Errorf("TailContainer returned err: %v", err)
+		}
+
+		_, _ = ioutil.ReadAll(rc)
+		rc.Close()
+	}
+
+	want := []string{"2021-01-01T12:00:00.000000001Z", ""}
+
+	if len(_docker.since) != 2 || _docker.since[0] != want[0] || _docker.since[1] != want[1] {
+		t.Errorf("TailContainer since is %q, want %q", _docker.since, want)
+	}
+}
+
+func TestDocker_ReattachBuild_NotFound(t *testing.T) {
+	// setup types
+	_notfound := &pipeline.Build{ID: "github_octocat_notfound"}
+
+	_engine, err := NewMock()
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	_engine.Docker = &reattachDocker{CommonAPIClient: _engine.Docker}
+
+	err = _engine.ReattachBuild(context.Background(), _notfound, time.Time{})
+	if err == nil {
+		t.Errorf("ReattachBuild should have returned err")
+	}
+}
+
+func TestDocker_ReattachBuild_Remove(t *testing.T) {
+	// setup types
+	_engine, err := NewMock(
+		WithNetworkSubnets([]string{"10.200.0.0/16"}),
+	)
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	_docker := &networkDocker{
+		CommonAPIClient: &reattachDocker{
+			CommonAPIClient: _engine.Docker,
+			network: &types.NetworkResource{
+				Name: _pipeline.ID,
+				IPAM: network.IPAM{Config: []network.IPAMConfig{{Subnet: "10.200.1.0/24"}}},
+			},
+		},
+		created: make(map[string]types.NetworkCreate),
+	}
+	_engine.Docker = _docker
+
+	_two := &pipeline.Build{ID: "github_octocat_2"}
+
+	err = _engine.ReattachBuild(context.Background(), _pipeline, time.Time{})
+	if err != nil {
+		t.Errorf("ReattachBuild returned err: %v", err)
+	}
+
+	got := []string{}
+	for _, subnet := range _engine.subnets[_pipeline.ID] {
+		got = append(got, subnet.String())
+	}
+
+	if !reflect.DeepEqual(got, []string{"10.200.1.0/24"}) {
+		t.Errorf("ReattachBuild subnets is %v, want 10.200.1.0/24", got)
+	}
+
+	// the subnets recovered for the build aren't allocated to another build
+	err = _engine.CreateNetwork(context.Background(), _two)
+	if err != nil {
+		t.Errorf("CreateNetwork returned err: %v", err)
+	}
+
+	if subnet := _docker.created[_two.ID].IPAM.Config[0].Subnet; subnet == "10.200.1.0/24" {
+		t.Errorf("CreateNetwork allocated subnet %s recovered for build %s", subnet, _pipeline.ID)
+	}
+
+	err = _engine.RemoveContainer(context.Background(), _container)
+	if err != nil {
+		t.Errorf("RemoveContainer returned err: %v", err)
+	}
+
+	err = _engine.RemoveVolume(context.Background(), _pipeline)
+	if err != nil {
+		t.Errorf("RemoveVolume returned err: %v", err)
+	}
+
+	err = _engine.RemoveNetwork(context.Background(), _pipeline)
+	if err != nil {
+		t.Errorf("RemoveNetwork returned err: %v", err)
+	}
+
+	err = _engine.RemoveBuild(context.Background(), _pipeline)
+	if err != nil {
+		t.Errorf("RemoveBuild returned err: %v", err)
+	}
+
+	// the subnets recovered for the build are released with the network
+	if _, ok := _engine.subnets[_pipeline.ID]; ok {
+		t.Errorf("RemoveNetwork did not release subnets %v", _engine.subnets[_pipeline.ID])
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/volume"
+	docker "github.com/docker/docker/client"
+
+	"github.com/go-vela/pkg-runtime/internal/secret"
+	"github.com/go-vela/types/pipeline"
+
+	"github.com/sirupsen/logrus"
+)
+
+// secretVolume is a helper function to capture the name
+// of the volume containing the files for the secrets
+// delivered to the pipeline container.
+func secretVolume(ctn *pipeline.Container) string {
+	return fmt.Sprintf("%s_secrets", ctn.ID)
+}
+
+// secretFiles is a helper function to capture the secrets
+// for the pipeline container delivered as files. A copy of
+// the container, without the secrets in the environment,
+// is returned when any secrets are delivered as files.
+func (c *client) secretFiles(ctn *pipeline.Container) (*pipeline.Container, map[string]string) {
+	// check if the secrets are delivered as files
+	if len(c.config.SecretFiles) == 0 || len(ctn.Secrets) == 0 {
+		return ctn, nil
+	}
+
+	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/secret#Files
+	env, files := secret.Files(ctn, c.config.SecretFiles)
+	if len(files) == 0 {
+		return ctn, nil
+	}
+
+	// create a copy of the container to avoid
+	// modifying the environment for the executor
+	_ctn := *ctn
+	_ctn.Environment = env
+
+	return &_ctn, files
+}
+
+// createSecrets is a helper function to create the volume
+// containing the files for the secrets delivered to the
+// pipeline container and add the mount for it.
+//
+// The volume is labeled for the build, so any volume left
+// behind by a worker that stopped is removed by the reaper.
+//
+// The files are stored on the disk for the host until the
+// volume is removed with the container. A volume backed by
+// a tmpfs can't be used, since the daemon unmounts it after
+// copying the files, which discards them before the container
+// is started, and copying the files after the container is
+// started races with the commands reading them.
+func (c *client) createSecrets(ctx context.Context, ctn *pipeline.Container, b *pipeline.Build, hostConf *container.HostConfig) error {
+	logrus.Tracef("creating secret volume for container %s", ctn.ID)
+
+	// create options for creating volume
+	//
+	// https://godoc.org/github.com/docker/docker/api/types/volume#VolumeCreateBody
+	opts := volume.VolumeCreateBody{
+		Name:   secretVolume(ctn),
+		Driver: "local",
+		Labels: c.labels(b),
+	}
+
+	// send API call to create the volume
+	//
+	// https://godoc.org/github.com/docker/docker/client#Client.VolumeCreate
+	_, err := c.Docker.VolumeCreate(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	// add the mount for the volume to the host config
+	//
+	// https://godoc.org/github.com/docker/docker/api/types/mount#Mount
+	hostConf.Mounts = append(hostConf.Mounts, mount.Mount{
+		Type:   mount.TypeVolume,
+		Source: secretVolume(ctn),
+		Target: secret.Mount,
+	})
+
+	return nil
+}
+
+// removeSecrets is a helper function to remove the volume
+// containing the files for the secrets delivered to the
+// pipeline container, if one was created for it.
+func (c *client) removeSecrets(ctx context.Context, ctn *pipeline.Container) error {
+	logrus.Tracef("removing secret volume for container %s", ctn.ID)
+
+	// send API call to remove the volume
+	//
+	// https://godoc.org/github.com/docker/docker/client#Client.VolumeRemove
+	err := c.Docker.VolumeRemove(ctx, secretVolume(ctn), true)
+	if err != nil && !docker.IsErrNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+// copySecrets is a helper function to copy the files
+// for the secrets into the volume for the pipeline
+// container before the container is started.
+//
+// The daemon mounts the volumes for a container that
+// isn't running to copy files to it, so the files are
+// written to the volume rather than the container.
+func (c *client) copySecrets(ctx context.Context, ctn *pipeline.Container, files map[string]string) error {
+	logrus.Tracef("copying %d secret files to container %s", len(files), ctn.ID)
+
+	buffer := new(bytes.Buffer)
+
+	// create a tar archive containing the files for the secrets
+	//
+	// https://pkg.go.dev/archive/tar#Writer
+	archive := tar.NewWriter(buffer)
+
+	for name, value := range files {
+		header := &tar.Header{
+			// the archive is extracted relative to the mount for the secrets
+			Name: strings.TrimPrefix(secret.Path(name), secret.Mount+"/"),
+			Mode: 0444,
+			Size: int64(len(value)),
+		}
+
+		err := archive.WriteHeader(header)
+		if err != nil {
+			return err
+		}
+
+		_, err = archive.Write([]byte(value))
+		if err != nil {
+			return err
+		}
+	}
+
+	err := archive.Close()
+	if err != nil {
+		return err
+	}
+
+	// send API call to copy the files to the container
+	//
+	// https://godoc.org/github.com/docker/docker/client#Client.CopyToContainer
+	return c.Docker.CopyToContainer(ctx, ctn.ID, secret.Mount, buffer, types.CopyToContainerOptions{})
+}
@@ -0,0 +1,294 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	docker "github.com/docker/docker/client"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/go-vela/types/pipeline"
+)
+
+// secretDocker represents a Docker client modeling the
+// filesystem for the containers, to capture the files
+// readable in the container once it is started.
+type secretDocker struct {
+	docker.CommonAPIClient
+
+	env     []string
+	mounts  []mount.Mount
+	tmpfs   map[string]string
+	labels  map[string]string
+	volumes map[string]map[string]string
+	layer   map[string]string
+	started bool
+	removed []string
+	order   []string
+}
+
+// VolumeCreate records the volume created for the container.
+func (d *secretDocker) VolumeCreate(ctx context.Context, opts volume.VolumeCreateBody) (types.Volume, error) {
+	d.labels = opts.Labels
+	d.volumes[opts.Name] = make(map[string]string)
+	d.order = append(d.order, "volume")
+
+	return d.CommonAPIClient.VolumeCreate(ctx, opts)
+}
+
+// VolumeRemove records the volume removed for the container.
+func (d *secretDocker) VolumeRemove(ctx context.Context, id string, force bool) error {
+	d.removed = append(d.removed, id)
+	delete(d.volumes, id)
+
+	return nil
+}
+
+// ContainerCreate records the environment and mounts for the container.
+//
+// nolint: lll // ignore long line length due to variable names
+func (d *secretDocker) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, name string) (container.ContainerCreateCreatedBody, error) {
+	d.env = config.Env
+	d.mounts = hostConfig.Mounts
+	d.tmpfs = hostConfig.Tmpfs
+	d.order = append(d.order, "create")
+
+	return d.CommonAPIClient.ContainerCreate(ctx, config, hostConfig, networkingConfig, platform, name)
+}
+
+// volume returns the files for the secret volume containing the path in the
+// container, using the most specific mount like the daemon does.
+func (d *secretDocker) volume(path string) map[string]string {
+	var target mount.Mount
+
+	for _, m := range d.mounts {
+		if m.Type == mount.TypeVolume && strings.HasPrefix(path, m.Target+"/") &&
+			len(m.Target) > len(target.Target) {
+			target = m
+		}
+	}
+
+	return d.volumes[target.Source]
+}
+
+// tmpfsHides returns if the path in the container is hidden by a tmpfs mount.
+func (d *secretDocker) tmpfsHides(path string) bool {
+	for target := range d.tmpfs {
+		if strings.HasPrefix(path, target+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CopyToContainer writes the files copied to the container to the
+// volumes mounted for it, or to the writable layer for the container.
+//
+// The daemon only mounts the volumes, and not any tmpfs, for the
+// copy to a container that isn't started.
+func (d *secretDocker) CopyToContainer(ctx context.Context, ctn, path string, content io.Reader, opts types.CopyToContainerOptions) error {
+	d.order = append(d.order, "copy")
+
+	archive := tar.NewReader(content)
+
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		data, err := ioutil.ReadAll(archive)
+		if err != nil {
+			return err
+		}
+
+		target := strings.TrimSuffix(path, "/") + "/" + header.Name
+
+		if files := d.volume(target); files != nil {
+			files[target] = string(data)
+
+			continue
+		}
+
+		d.layer[target] = string(data)
+	}
+
+	return nil
+}
+
+// ContainerStart records the container was started.
+func (d *secretDocker) ContainerStart(ctx context.Context, ctn string, opts types.ContainerStartOptions) error {
+	d.started = true
+	d.order = append(d.order, "start")
+
+	return d.CommonAPIClient.ContainerStart(ctx, ctn, opts)
+}
+
+// read returns the files readable under the path in the started container.
+func (d *secretDocker) read(path string) map[string]string {
+	files := make(map[string]string)
+
+	if !d.started {
+		return files
+	}
+
+	for _, m := range d.mounts {
+		if m.Type != mount.TypeVolume || !strings.HasPrefix(m.Target+"/", path+"/") {
+			continue
+		}
+
+		for name, data := range d.volumes[m.Source] {
+			files[name] = data
+		}
+	}
+
+	for name, data := range d.layer {
+		// check if the file is hidden by a mount in the running container
+		if d.tmpfsHides(name) || d.volume(name) != nil {
+			continue
+		}
+
+		if strings.HasPrefix(name, path+"/") {
+			files[name] = data
+		}
+	}
+
+	return files
+}
+
+func TestDocker_RunContainer_SecretFiles(t *testing.T) {
+	// setup types
+	_secrets := *_container
+	_secrets.Environment = map[string]string{"FOO": "bar", "TOKEN": "sup3rs3cr3t"}
+	_secrets.Secrets = pipeline.StepSecretSlice{{Source: "token", Target: "token"}}
+
+	// setup tests
+	tests := []struct {
+		name      string
+		files     []string
+		container *pipeline.Container
+		env       []string
+		readable  map[string]string
+		order     []string
+	}{
+		{
+			name:      "secret files",
+			files:     []string{"token"},
+			container: &_secrets,
+			env:       []string{"FOO=bar", "TOKEN_FILE=/vela/secrets/TOKEN"},
+			readable:  map[string]string{"/vela/secrets/TOKEN": "sup3rs3cr3t"},
+			order:     []string{"volume", "create", "copy", "start"},
+		},
+		{
+			name:      "secret files without secrets",
+			files:     []string{"token"},
+			container: _container,
+			env:       []string{"FOO=bar"},
+			readable:  map[string]string{},
+			order:     []string{"create", "start"},
+		},
+		{
+			name:      "secret files for other secrets",
+			files:     []string{"npm_token"},
+			container: &_secrets,
+			env:       []string{"FOO=bar", "TOKEN=sup3rs3cr3t"},
+			readable:  map[string]string{},
+			order:     []string{"create", "start"},
+		},
+		{
+			name:      "secret environment",
+			files:     nil,
+			container: &_secrets,
+			env:       []string{"FOO=bar", "TOKEN=sup3rs3cr3t"},
+			readable:  map[string]string{},
+			order:     []string{"create", "start"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := NewMock(WithSecretFiles(test.files))
+		if err != nil {
+			t.Errorf("unable to create runtime engine: %v", err)
+		}
+
+		_docker := &secretDocker{
+			CommonAPIClient: _engine.Docker,
+			volumes:         make(map[string]map[string]string),
+			layer:           make(map[string]string),
+		}
+		_engine.Docker = _docker
+
+		err = _engine.RunContainer(context.Background(), test.container, _pipeline)
+		if err != nil {
+			t.Errorf("RunContainer for %s returned err: %v", test.name, err)
+		}
+
+		sort.Strings(_docker.env)
+
+		if !reflect.DeepEqual(_docker.env, test.env) {
+			t.Errorf("RunContainer for %s env is %v, want %v", test.name, _docker.env, test.env)
+		}
+
+		// check the files are readable in the running container
+		got := _docker.read("/vela/secrets")
+
+		if !reflect.DeepEqual(got, test.readable) {
+			t.Errorf("RunContainer for %s readable is %v, want %v", test.name, got, test.readable)
+		}
+
+		// check the secrets aren't written to the writable layer of the container
+		if len(_docker.layer) > 0 {
+			t.Errorf("RunContainer for %s wrote %v to the container layer", test.name, _docker.layer)
+		}
+
+		if !reflect.DeepEqual(_docker.order, test.order) {
+			t.Errorf("RunContainer for %s order is %v, want %v", test.name, _docker.order, test.order)
+		}
+
+		// check the volume is labeled for the reaper
+		if len(test.readable) > 0 && _docker.labels[LabelBuild] != _pipeline.ID {
+			t.Errorf("RunContainer for %s volume labels are %v, want build %s", test.name, _docker.labels, _pipeline.ID)
+		}
+
+		err = _engine.RemoveContainer(context.Background(), test.container)
+		if err != nil {
+			t.Errorf("RemoveContainer for %s returned err: %v", test.name, err)
+		}
+
+		// check the volume for the secrets is removed with the container
+		if len(_docker.volumes) > 0 {
+			t.Errorf("RemoveContainer for %s left volumes %v", test.name, _docker.volumes)
+		}
+
+		// check the volume for the secrets isn't removed without secret files
+		if len(test.files) == 0 && len(_docker.removed) > 0 {
+			t.Errorf("RemoveContainer for %s removed volumes %v", test.name, _docker.removed)
+		}
+	}
+
+	// the environment for the executor is unmodified
+	if _secrets.Environment["TOKEN"] != "sup3rs3cr3t" {
+		t.Errorf("RunContainer modified environment: %v", _secrets.Environment)
+	}
+}
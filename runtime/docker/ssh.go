@@ -0,0 +1,229 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// sshHost represents the host provided to the Docker client
+// in place of the ssh:// host for the daemon, because the
+// connection to the daemon is made with the ssh dialer.
+const sshHost = "http://docker.example.com"
+
+// sshCommand represents the command run to
+// connect to the daemon for an ssh:// host.
+//
+// This is a variable so it can be replaced for testing.
+var sshCommand = "ssh"
+
+// isSSH is a helper function to check
+// if the host for the daemon is ssh://.
+func isSSH(host string) bool {
+	return strings.HasPrefix(host, "ssh://")
+}
+
+// sshArgs is a helper function to create the arguments for
+// the ssh command connecting to the daemon for the host.
+//
+// The connection to the daemon is made through the stdin and
+// stdout for the docker CLI on the remote host, matching the
+// connection made by the docker CLI for an ssh:// host.
+//
+// https://docs.docker.com/engine/security/protect-access/#use-ssh-to-protect-the-docker-daemon-socket
+func sshArgs(host string) ([]string, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ssh host provided: %w", err)
+	}
+
+	// check if the host provided is for ssh
+	if u.Scheme != "ssh" {
+		return nil, fmt.Errorf("invalid ssh host provided: %s", host)
+	}
+
+	// check if the hostname is provided
+	if len(u.Hostname()) == 0 {
+		return nil, fmt.Errorf("no hostname provided for ssh host %s", host)
+	}
+
+	// check if a path is provided
+	if len(u.Path) > 0 && u.Path != "/" {
+		return nil, fmt.Errorf("path not supported for ssh host %s", host)
+	}
+
+	args := []string{}
+
+	// check if a user is provided
+	if u.User != nil {
+		args = append(args, "-l", u.User.Username())
+	}
+
+	// check if a port is provided
+	if len(u.Port()) > 0 {
+		args = append(args, "-p", u.Port())
+	}
+
+	return append(args, "--", u.Hostname(), "docker", "system", "dial-stdio"), nil
+}
+
+// sshDialer is a helper function to create the function
+// used by the Docker client to connect to the daemon
+// for the ssh:// host.
+func sshDialer(host string) (func(context.Context, string, string) (net.Conn, error), error) {
+	args, err := sshArgs(host)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		logrus.Tracef("connecting to docker daemon with %s %s", sshCommand, strings.Join(args, " "))
+
+		return dialCommand(sshCommand, args...)
+	}, nil
+}
+
+// dialCommand is a helper function to start the command and
+// create a connection from the stdin and stdout for it.
+//
+// The command isn't tied to the context for the dial,
+// because the connection outlives the dial.
+func dialCommand(name string, args ...string) (net.Conn, error) {
+	// nolint: gosec // ignore running a command from a variable
+	cmd := exec.Command(name, args...)
+
+	conn := &commandConn{cmd: cmd}
+	cmd.Stderr = &conn.stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	conn.stdin = stdin
+	conn.stdout = stdout
+
+	err = cmd.Start()
+	if err != nil {
+		return nil, fmt.Errorf("unable to run %s: %w", name, err)
+	}
+
+	return conn, nil
+}
+
+// commandConn represents a connection made from
+// the stdin and stdout for a running command.
+type commandConn struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+	stderr bytes.Buffer
+
+	waitOnce sync.Once
+}
+
+// Read reads from the stdout for the command.
+func (c *commandConn) Read(p []byte) (int, error) {
+	n, err := c.stdout.Read(p)
+
+	// check if the command closed the stdout
+	if err == io.EOF {
+		c.wait()
+
+		// check if the command exited with output on stderr
+		if c.stderr.Len() > 0 {
+			return n, fmt.Errorf("connection closed: %s", strings.TrimSpace(c.stderr.String()))
+		}
+	}
+
+	return n, err
+}
+
+// Write writes to the stdin for the command.
+func (c *commandConn) Write(p []byte) (int, error) {
+	return c.stdin.Write(p)
+}
+
+// CloseWrite closes the stdin for the command.
+func (c *commandConn) CloseWrite() error {
+	return c.stdin.Close()
+}
+
+// Close closes the stdin for the command and stops the command.
+func (c *commandConn) Close() error {
+	_ = c.stdin.Close()
+
+	// stop the command when it is still running
+	if c.cmd.Process != nil {
+		_ = c.cmd.Process.Kill()
+	}
+
+	c.wait()
+
+	return nil
+}
+
+// wait is a helper function to wait for the command to exit,
+// which also waits for the output on stderr to be captured.
+func (c *commandConn) wait() {
+	c.waitOnce.Do(func() {
+		_ = c.cmd.Wait()
+	})
+}
+
+// LocalAddr returns the address for the command.
+func (c *commandConn) LocalAddr() net.Addr {
+	return commandAddr{}
+}
+
+// RemoteAddr returns the address for the command.
+func (c *commandConn) RemoteAddr() net.Addr {
+	return commandAddr{}
+}
+
+// SetDeadline is a no-op for the command.
+func (c *commandConn) SetDeadline(t time.Time) error {
+	return nil
+}
+
+// SetReadDeadline is a no-op for the command.
+func (c *commandConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+// SetWriteDeadline is a no-op for the command.
+func (c *commandConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+// commandAddr represents the address
+// for a connection to a command.
+type commandAddr struct{}
+
+// Network returns the network for the command.
+func (commandAddr) Network() string {
+	return "command"
+}
+
+// String returns the address for the command.
+func (commandAddr) String() string {
+	return "command"
+}
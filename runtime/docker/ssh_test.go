@@ -0,0 +1,133 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"context"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDocker_sshArgs(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		host    string
+		want    []string
+	}{
+		{
+			failure: false,
+			host:    "ssh://docker.example.com",
+			want:    []string{"--", "docker.example.com", "docker", "system", "dial-stdio"},
+		},
+		{
+			failure: false,
+			host:    "ssh://vela@docker.example.com:2222",
+			want:    []string{"-l", "vela", "-p", "2222", "--", "docker.example.com", "docker", "system", "dial-stdio"},
+		},
+		{
+			failure: true,
+			host:    "ssh://",
+		},
+		{
+			failure: true,
+			host:    "ssh://docker.example.com/var/run/docker.sock",
+		},
+		{
+			failure: true,
+			host:    "tcp://docker.example.com:2376",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got, err := sshArgs(test.host)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("sshArgs for %s should have returned err", test.host)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("sshArgs for %s returned err: %v", test.host, err)
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("sshArgs for %s is %v, want %v", test.host, got, test.want)
+		}
+	}
+}
+
+func TestDocker_sshDialer(t *testing.T) {
+	// setup types
+	defer func(command string) { sshCommand = command }(sshCommand)
+
+	// replace ssh with a command echoing the connection
+	sshCommand = "cat"
+
+	dialer, err := sshDialer("ssh://docker.example.com")
+	if err != nil {
+		t.Errorf("sshDialer returned err: %v", err)
+	}
+
+	// cat reads the arguments from ssh as files, so
+	// the dialer is only checked with the connection
+	conn, err := dialer(context.Background(), "tcp", "docker.example.com:80")
+	if err != nil {
+		t.Errorf("dialer returned err: %v", err)
+	}
+
+	_ = conn.Close()
+
+	// run test
+	conn, err = dialCommand("cat")
+	if err != nil {
+		t.Errorf("dialCommand returned err: %v", err)
+	}
+
+	_, err = conn.Write([]byte("GET /_ping HTTP/1.1\n"))
+	if err != nil {
+		t.Errorf("Write returned err: %v", err)
+	}
+
+	err = conn.(*commandConn).CloseWrite()
+	if err != nil {
+		t.Errorf("CloseWrite returned err: %v", err)
+	}
+
+	got, err := ioutil.ReadAll(conn)
+	if err != nil {
+		t.Errorf("Read returned err: %v", err)
+	}
+
+	if string(got) != "GET /_ping HTTP/1.1\n" {
+		t.Errorf("Read is %q, want %q", got, "GET /_ping HTTP/1.1\n")
+	}
+
+	err = conn.Close()
+	if err != nil {
+		t.Errorf("Close returned err: %v", err)
+	}
+}
+
+func TestDocker_dialCommand_Stderr(t *testing.T) {
+	// run test
+	conn, err := dialCommand("sh", "-c", "echo 'ssh: connect to host docker.example.com port 22: Connection refused' >&2")
+	if err != nil {
+		t.Errorf("dialCommand returned err: %v", err)
+	}
+
+	defer conn.Close()
+
+	_, err = ioutil.ReadAll(conn)
+	if err == nil || !strings.Contains(err.Error(), "Connection refused") {
+		t.Errorf("Read returned err %v, want Connection refused", err)
+	}
+}
@@ -31,6 +31,7 @@ func (c *client) CreateVolume(ctx context.Context, b *pipeline.Build) error {
 	opts := volume.VolumeCreateBody{
 		Name:   b.ID,
 		Driver: "local",
+		Labels: c.labels(b),
 	}
 
 	// send API call to create the volume
@@ -148,3 +149,26 @@ func hostConfig(id string, ulimits pipeline.UlimitSlice, volumes []string) *cont
 		Resources: resources,
 	}
 }
+
+// mountBinds is a helper function to convert the mounts
+// for a container to binds, for a daemon that doesn't
+// support mounts for containers.
+//
+// https://docs.docker.com/engine/reference/commandline/run/#mount-volume--v---read-only
+func mountBinds(mounts []mount.Mount) []string {
+	binds := []string{}
+
+	// iterate through all mounts provided
+	for _, m := range mounts {
+		bind := fmt.Sprintf("%s:%s", m.Source, m.Target)
+
+		// check if the mount is read only
+		if m.ReadOnly {
+			bind = fmt.Sprintf("%s:ro", bind)
+		}
+
+		binds = append(binds, bind)
+	}
+
+	return binds
+}
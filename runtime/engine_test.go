@@ -0,0 +1,175 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package runtime
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	dockerclient "github.com/docker/docker/client"
+
+	"github.com/go-vela/pkg-runtime/runtime/docker"
+	"github.com/go-vela/pkg-runtime/runtime/kubernetes"
+	"github.com/go-vela/types/pipeline"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// specDocker represents a Docker client
+// recording the config for containers.
+type specDocker struct {
+	dockerclient.CommonAPIClient
+
+	config *container.Config
+}
+
+// ContainerCreate records the config for the container.
+//
+// nolint: lll // ignore long line length due to variable names
+func (d *specDocker) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, name string) (container.ContainerCreateCreatedBody, error) {
+	d.config = config
+
+	return d.CommonAPIClient.ContainerCreate(ctx, config, hostConfig, networkingConfig, platform, name)
+}
+
+func TestRuntime_Engine_ContainerSpec(t *testing.T) {
+	// setup types
+	_build := &pipeline.Build{
+		ID:      "github-octocat-1",
+		Version: "1",
+	}
+
+	// setup tests
+	tests := []struct {
+		name       string
+		entrypoint []string
+		commands   []string
+	}{
+		{
+			name: "image defaults",
+		},
+		{
+			name:       "entrypoint",
+			entrypoint: []string{"/bin/sh", "-c"},
+		},
+		{
+			name:     "commands",
+			commands: []string{"echo", "hello"},
+		},
+		{
+			name:       "entrypoint and commands",
+			entrypoint: []string{"/bin/sh", "-c"},
+			commands:   []string{"echo $FOO"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_container := &pipeline.Container{
+			ID:         "step_github_octocat_1_echo",
+			Commands:   test.commands,
+			Directory:  "/vela/src/github.com/octocat/helloworld",
+			Entrypoint: test.entrypoint,
+			Image:      "alpine:latest",
+			Name:       "echo",
+			Number:     2,
+			Pull:       "not_present",
+		}
+
+		_docker, err := docker.NewMock()
+		if err != nil {
+			t.Errorf("unable to create docker runtime engine: %v", err)
+		}
+
+		_spec := &specDocker{CommonAPIClient: _docker.Docker}
+		_docker.Docker = _spec
+
+		err = _docker.RunContainer(context.Background(), _container, _build)
+		if err != nil {
+			t.Errorf("RunContainer for %s returned err: %v", test.name, err)
+		}
+
+		_kubernetes, err := kubernetes.NewMock(&v1.Pod{})
+		if err != nil {
+			t.Errorf("unable to create kubernetes runtime engine: %v", err)
+		}
+
+		_pipeline := &pipeline.Build{
+			ID:      _build.ID,
+			Version: _build.Version,
+			Steps:   pipeline.ContainerSlice{_container},
+		}
+
+		err = _kubernetes.SetupBuild(context.Background(), _pipeline)
+		if err != nil {
+			t.Errorf("SetupBuild for %s returned err: %v", test.name, err)
+		}
+
+		err = _kubernetes.SetupContainer(context.Background(), _container)
+		if err != nil {
+			t.Errorf("SetupContainer for %s returned err: %v", test.name, err)
+		}
+
+		err = _kubernetes.AssembleBuild(context.Background(), _pipeline)
+		if err != nil {
+			t.Errorf("AssembleBuild for %s returned err: %v", test.name, err)
+		}
+
+		_pod, err := _kubernetes.Kubernetes.CoreV1().
+			Pods("test").
+			Get(context.Background(), _build.ID, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("AssembleBuild for %s did not create pod: %v", test.name, err)
+		}
+
+		// the entrypoint replaces the ENTRYPOINT for the image
+		if !reflect.DeepEqual([]string(_spec.config.Entrypoint), test.entrypoint) {
+			t.Errorf("RunContainer for %s entrypoint is %v, want %v", test.name, _spec.config.Entrypoint, test.entrypoint)
+		}
+
+		if !reflect.DeepEqual(_pod.Spec.Containers[0].Command, test.entrypoint) {
+			t.Errorf("SetupContainer for %s command is %v, want %v", test.name, _pod.Spec.Containers[0].Command, test.entrypoint)
+		}
+
+		// the commands replace the CMD for the image
+		if !reflect.DeepEqual([]string(_spec.config.Cmd), test.commands) {
+			t.Errorf("RunContainer for %s cmd is %v, want %v", test.name, _spec.config.Cmd, test.commands)
+		}
+
+		if !reflect.DeepEqual(_pod.Spec.Containers[0].Args, test.commands) {
+			t.Errorf("SetupContainer for %s args is %v, want %v", test.name, _pod.Spec.Containers[0].Args, test.commands)
+		}
+
+		err = _kubernetes.RemoveBuild(context.Background(), _pipeline)
+		if err != nil {
+			t.Errorf("RemoveBuild for %s returned err: %v", test.name, err)
+		}
+	}
+}
+
+func TestRuntime_Engine_RecordTailer(t *testing.T) {
+	// setup types
+	_docker, err := docker.NewMock()
+	if err != nil {
+		t.Errorf("unable to create docker runtime engine: %v", err)
+	}
+
+	_kubernetes, err := kubernetes.NewMock(&v1.Pod{})
+	if err != nil {
+		t.Errorf("unable to create kubernetes runtime engine: %v", err)
+	}
+
+	// run tests
+	for name, engine := range map[string]Engine{"docker": _docker, "kubernetes": _kubernetes} {
+		if _, ok := engine.(RecordTailer); !ok {
+			t.Errorf("%s runtime engine is not a RecordTailer", name)
+		}
+	}
+}
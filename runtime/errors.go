@@ -0,0 +1,44 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package runtime
+
+import (
+	"github.com/go-vela/pkg-runtime/internal/image"
+	"github.com/go-vela/pkg-runtime/internal/signature"
+	"github.com/go-vela/pkg-runtime/runtime/kubernetes"
+)
+
+// ImagePolicyError represents the error returned by the runtime
+// when an image is blocked by the configured image policy.
+//
+// Use errors.As to capture the rule that blocked the image:
+//
+// 	var policyErr *runtime.ImagePolicyError
+// 	if errors.As(err, &policyErr) {
+// 		fmt.Println(policyErr.Rule, policyErr.Pattern)
+// 	}
+type ImagePolicyError = image.PolicyError
+
+// ImageSignatureError represents the error returned by the
+// runtime when an image fails signature verification.
+type ImageSignatureError = signature.Error
+
+// PodFailureError represents the error returned by the Kubernetes
+// runtime when the pod for a container fails in a way the container
+// is unable to recover from, like being evicted or unable to pull
+// the image for the container.
+//
+// Use errors.As to capture the reason for the failure:
+//
+// 	var failureErr *runtime.PodFailureError
+// 	if errors.As(err, &failureErr) {
+// 		fmt.Println(failureErr.Reason, failureErr.Message)
+// 	}
+type PodFailureError = kubernetes.PodFailureError
+
+// CapabilityError represents the error returned by the Kubernetes
+// runtime when a container requires a setting the runtime is
+// unable to apply, like ulimits or a user name.
+type CapabilityError = kubernetes.CapabilityError
@@ -5,6 +5,8 @@
 package runtime
 
 import (
+	"time"
+
 	"github.com/go-vela/types/constants"
 
 	"github.com/urfave/cli/v2"
@@ -48,12 +50,67 @@ var Flags = []cli.Flag{
 		Name:     "runtime.config",
 		Usage:    "path to configuration file for the runtime",
 	},
+	&cli.StringFlag{
+		EnvVars:  []string{"VELA_RUNTIME_HOST", "RUNTIME_HOST"},
+		FilePath: "/vela/runtime/host",
+		Name:     "runtime.host",
+		Usage:    "host (unix://, tcp://, npipe:// or ssh://) for the daemon, defaults to DOCKER_HOST, for the runtime (only used by docker)",
+	},
+	&cli.StringFlag{
+		EnvVars:  []string{"VELA_RUNTIME_TLS_CA", "RUNTIME_TLS_CA"},
+		FilePath: "/vela/runtime/tls_ca",
+		Name:     "runtime.tls.ca",
+		Usage:    "path to the certificate authority for the daemon for the runtime (only used by docker)",
+	},
+	&cli.StringFlag{
+		EnvVars:  []string{"VELA_RUNTIME_TLS_CERT", "RUNTIME_TLS_CERT"},
+		FilePath: "/vela/runtime/tls_cert",
+		Name:     "runtime.tls.cert",
+		Usage:    "path to the certificate for connecting to the daemon for the runtime (only used by docker)",
+	},
+	&cli.StringFlag{
+		EnvVars:  []string{"VELA_RUNTIME_TLS_KEY", "RUNTIME_TLS_KEY"},
+		FilePath: "/vela/runtime/tls_key",
+		Name:     "runtime.tls.key",
+		Usage:    "path to the key for connecting to the daemon for the runtime (only used by docker)",
+	},
+	&cli.DurationFlag{
+		EnvVars:  []string{"VELA_RUNTIME_TIMEOUT", "RUNTIME_TIMEOUT"},
+		FilePath: "/vela/runtime/timeout",
+		Name:     "runtime.timeout",
+		Usage:    "timeout for connecting to the daemon and for the headers of each response, not limiting streamed logs, for the runtime (only used by docker)",
+	},
 	&cli.StringFlag{
 		EnvVars:  []string{"VELA_RUNTIME_NAMESPACE", "RUNTIME_NAMESPACE"},
 		FilePath: "/vela/runtime/namespace",
 		Name:     "runtime.namespace",
 		Usage:    "namespace to use for the runtime (only used by kubernetes)",
 	},
+	&cli.StringFlag{
+		EnvVars:  []string{"VELA_RUNTIME_POD_TEMPLATE", "RUNTIME_POD_TEMPLATE"},
+		FilePath: "/vela/runtime/pod_template",
+		Name:     "runtime.pod-template",
+		Usage:    "path to pod template file merged into the pod for builds (only used by kubernetes)",
+	},
+	&cli.StringFlag{
+		EnvVars:  []string{"VELA_RUNTIME_WORKSPACE_VOLUME", "RUNTIME_WORKSPACE_VOLUME"},
+		FilePath: "/vela/runtime/workspace_volume",
+		Name:     "runtime.workspace.volume",
+		Usage:    "type of volume (emptyDir, memory, pvc or ephemeral) for the workspace (only used by kubernetes)",
+		Value:    "emptyDir",
+	},
+	&cli.StringFlag{
+		EnvVars:  []string{"VELA_RUNTIME_WORKSPACE_STORAGE_CLASS", "RUNTIME_WORKSPACE_STORAGE_CLASS"},
+		FilePath: "/vela/runtime/workspace_storage_class",
+		Name:     "runtime.workspace.storage-class",
+		Usage:    "storage class for the workspace volume (only used by kubernetes)",
+	},
+	&cli.StringFlag{
+		EnvVars:  []string{"VELA_RUNTIME_WORKSPACE_SIZE", "RUNTIME_WORKSPACE_SIZE"},
+		FilePath: "/vela/runtime/workspace_size",
+		Name:     "runtime.workspace.size",
+		Usage:    "size (i.e. 10Gi) for the workspace volume (only used by kubernetes)",
+	},
 	&cli.StringSliceFlag{
 		EnvVars:  []string{"VELA_RUNTIME_PRIVILEGED_IMAGES", "RUNTIME_PRIVILEGED_IMAGES"},
 		FilePath: "/vela/runtime/privileged_images",
@@ -61,6 +118,188 @@ var Flags = []cli.Flag{
 		Usage:    "list of images allowed to run in privileged mode for the runtime",
 		Value:    cli.NewStringSlice("target/vela-docker"),
 	},
+	&cli.StringSliceFlag{
+		EnvVars:  []string{"VELA_RUNTIME_ALLOWED_IMAGES", "RUNTIME_ALLOWED_IMAGES"},
+		FilePath: "/vela/runtime/allowed_images",
+		Name:     "runtime.allowed-images",
+		Usage:    "list of image patterns allowed to run for the runtime",
+	},
+	&cli.StringSliceFlag{
+		EnvVars:  []string{"VELA_RUNTIME_DENIED_IMAGES", "RUNTIME_DENIED_IMAGES"},
+		FilePath: "/vela/runtime/denied_images",
+		Name:     "runtime.denied-images",
+		Usage:    "list of image patterns denied from running for the runtime",
+	},
+	&cli.StringSliceFlag{
+		EnvVars:  []string{"VELA_RUNTIME_ALLOWED_REGISTRIES", "RUNTIME_ALLOWED_REGISTRIES"},
+		FilePath: "/vela/runtime/allowed_registries",
+		Name:     "runtime.allowed-registries",
+		Usage:    "list of registries allowed to host images for the runtime",
+	},
+	&cli.StringSliceFlag{
+		EnvVars:  []string{"VELA_RUNTIME_DENIED_REGISTRIES", "RUNTIME_DENIED_REGISTRIES"},
+		FilePath: "/vela/runtime/denied_registries",
+		Name:     "runtime.denied-registries",
+		Usage:    "list of registries denied from hosting images for the runtime",
+	},
+	&cli.StringSliceFlag{
+		EnvVars:  []string{"VELA_RUNTIME_REGISTRY_MIRRORS", "RUNTIME_REGISTRY_MIRRORS"},
+		FilePath: "/vela/runtime/registry_mirrors",
+		Name:     "runtime.registry-mirrors",
+		Usage:    "list of registry mirrors, in the form <registry>=<mirror>, to pull images from for the runtime",
+	},
+	&cli.BoolFlag{
+		EnvVars:  []string{"VELA_RUNTIME_REGISTRY_MIRROR_FALLBACK", "RUNTIME_REGISTRY_MIRROR_FALLBACK"},
+		FilePath: "/vela/runtime/registry_mirror_fallback",
+		Name:     "runtime.registry-mirror-fallback",
+		Usage:    "enables pulling from the origin registry when a mirror fails for the runtime (only used by docker)",
+	},
+	&cli.StringSliceFlag{
+		EnvVars:  []string{"VELA_RUNTIME_SIGNATURE_KEYS", "RUNTIME_SIGNATURE_KEYS"},
+		FilePath: "/vela/runtime/signature_keys",
+		Name:     "runtime.signature-keys",
+		Usage:    "list of paths to public keys for verifying image signatures for the runtime",
+	},
+	&cli.StringSliceFlag{
+		EnvVars:  []string{"VELA_RUNTIME_SIGNED_IMAGES", "RUNTIME_SIGNED_IMAGES"},
+		FilePath: "/vela/runtime/signed_images",
+		Name:     "runtime.signed-images",
+		Usage:    "list of image patterns required to have a valid signature for the runtime",
+	},
+	&cli.StringFlag{
+		EnvVars:  []string{"VELA_RUNTIME_PLATFORM", "RUNTIME_PLATFORM"},
+		FilePath: "/vela/runtime/platform",
+		Name:     "runtime.platform",
+		Usage:    "default platform (i.e. linux/arm64) for images for the runtime",
+	},
+	&cli.StringFlag{
+		EnvVars:  []string{"VELA_RUNTIME_LOG_LIMIT_BYTES", "RUNTIME_LOG_LIMIT_BYTES"},
+		FilePath: "/vela/runtime/log_limit_bytes",
+		Name:     "runtime.log-limit.bytes",
+		Usage:    "maximum size (i.e. 10MB) of logs for each container for the runtime",
+	},
+	&cli.Int64Flag{
+		EnvVars:  []string{"VELA_RUNTIME_LOG_LIMIT_LINES", "RUNTIME_LOG_LIMIT_LINES"},
+		FilePath: "/vela/runtime/log_limit_lines",
+		Name:     "runtime.log-limit.lines",
+		Usage:    "maximum lines of logs for each container for the runtime",
+	},
+	&cli.IntFlag{
+		EnvVars:  []string{"VELA_RUNTIME_LOG_LIMIT_TAIL", "RUNTIME_LOG_LIMIT_TAIL"},
+		FilePath: "/vela/runtime/log_limit_tail",
+		Name:     "runtime.log-limit.tail",
+		Usage:    "number of lines retained from the end of logs exceeding the limits for the runtime",
+	},
+	&cli.BoolFlag{
+		EnvVars:  []string{"VELA_RUNTIME_LOG_LIMIT_KILL", "RUNTIME_LOG_LIMIT_KILL"},
+		FilePath: "/vela/runtime/log_limit_kill",
+		Name:     "runtime.log-limit.kill",
+		Usage:    "enables killing a container when the logs exceed the limits for the runtime",
+	},
+	&cli.StringSliceFlag{
+		EnvVars:  []string{"VELA_RUNTIME_SECRET_FILES", "RUNTIME_SECRET_FILES"},
+		FilePath: "/vela/runtime/secret_files",
+		Name:     "runtime.secret-files",
+		Usage:    "list of secrets, by name, delivered as files in /vela/secrets, rather than in the environment, for the runtime",
+	},
+	&cli.StringFlag{
+		EnvVars:  []string{"VELA_RUNTIME_WORKER_ID", "RUNTIME_WORKER_ID"},
+		FilePath: "/vela/runtime/worker_id",
+		Name:     "runtime.worker-id",
+		Usage:    "ID of the worker labeled on resources for builds, defaults to the hostname, for the runtime",
+	},
+	&cli.DurationFlag{
+		EnvVars:  []string{"VELA_RUNTIME_IMAGE_GC_MAX_AGE", "RUNTIME_IMAGE_GC_MAX_AGE"},
+		FilePath: "/vela/runtime/image_gc_max_age",
+		Name:     "runtime.image-gc.max-age",
+		Usage:    "duration an image can go unused before it is removed by the runtime (only used by docker)",
+	},
+	&cli.DurationFlag{
+		EnvVars:  []string{"VELA_RUNTIME_IMAGE_GC_INTERVAL", "RUNTIME_IMAGE_GC_INTERVAL"},
+		FilePath: "/vela/runtime/image_gc_interval",
+		Name:     "runtime.image-gc.interval",
+		Usage:    "minimum duration between removing unused images after builds by the runtime (only used by docker)",
+		Value:    5 * time.Minute,
+	},
+	&cli.StringFlag{
+		EnvVars:  []string{"VELA_RUNTIME_IMAGE_GC_HIGH_WATER", "RUNTIME_IMAGE_GC_HIGH_WATER"},
+		FilePath: "/vela/runtime/image_gc_high_water",
+		Name:     "runtime.image-gc.high-water",
+		Usage:    "disk usage of images (i.e. 20GB) that triggers removing unused images by the runtime (only used by docker)",
+	},
+	&cli.StringFlag{
+		EnvVars:  []string{"VELA_RUNTIME_IMAGE_GC_LOW_WATER", "RUNTIME_IMAGE_GC_LOW_WATER"},
+		FilePath: "/vela/runtime/image_gc_low_water",
+		Name:     "runtime.image-gc.low-water",
+		Usage:    "disk usage of images (i.e. 15GB) to reduce to when removing unused images by the runtime (only used by docker)",
+	},
+	&cli.StringSliceFlag{
+		EnvVars:  []string{"VELA_RUNTIME_IMAGE_GC_PROTECTED_IMAGES", "RUNTIME_IMAGE_GC_PROTECTED_IMAGES"},
+		FilePath: "/vela/runtime/image_gc_protected_images",
+		Name:     "runtime.image-gc.protected-images",
+		Usage:    "list of image patterns never removed by the runtime (only used by docker)",
+	},
+	&cli.StringFlag{
+		EnvVars:  []string{"VELA_RUNTIME_NETWORK_DRIVER", "RUNTIME_NETWORK_DRIVER"},
+		FilePath: "/vela/runtime/network_driver",
+		Name:     "runtime.network.driver",
+		Usage:    "driver for the network for builds for the runtime (only used by docker)",
+		Value:    "bridge",
+	},
+	&cli.StringSliceFlag{
+		EnvVars:  []string{"VELA_RUNTIME_NETWORK_SUBNETS", "RUNTIME_NETWORK_SUBNETS"},
+		FilePath: "/vela/runtime/network_subnets",
+		Name:     "runtime.network.subnets",
+		Usage:    "list of subnet pools, in the form <cidr>[=<size>], the network for each build is allocated a subnet from for the runtime (only used by docker)",
+	},
+	&cli.BoolFlag{
+		EnvVars:  []string{"VELA_RUNTIME_NETWORK_INTERNAL", "RUNTIME_NETWORK_INTERNAL"},
+		FilePath: "/vela/runtime/network_internal",
+		Name:     "runtime.network.internal",
+		Usage:    "enables restricting the network for builds from external access for the runtime (only used by docker)",
+	},
+	&cli.BoolFlag{
+		EnvVars:  []string{"VELA_RUNTIME_NETWORK_IPV6", "RUNTIME_NETWORK_IPV6"},
+		FilePath: "/vela/runtime/network_ipv6",
+		Name:     "runtime.network.ipv6",
+		Usage:    "enables IPv6 for the network for builds for the runtime (only used by docker)",
+	},
+	&cli.StringSliceFlag{
+		EnvVars:  []string{"VELA_RUNTIME_NETWORK_DNS", "RUNTIME_NETWORK_DNS"},
+		FilePath: "/vela/runtime/network_dns",
+		Name:     "runtime.network.dns",
+		Usage:    "list of DNS servers for containers on the network for builds for the runtime (only used by docker)",
+	},
+	&cli.StringSliceFlag{
+		EnvVars:  []string{"VELA_RUNTIME_NETWORK_DNS_SEARCH", "RUNTIME_NETWORK_DNS_SEARCH"},
+		FilePath: "/vela/runtime/network_dns_search",
+		Name:     "runtime.network.dns-search",
+		Usage:    "list of DNS search domains for containers on the network for builds for the runtime (only used by docker)",
+	},
+	&cli.StringSliceFlag{
+		EnvVars:  []string{"VELA_RUNTIME_NETWORK_EXTRA_HOSTS", "RUNTIME_NETWORK_EXTRA_HOSTS"},
+		FilePath: "/vela/runtime/network_extra_hosts",
+		Name:     "runtime.network.extra-hosts",
+		Usage:    "list of extra hosts, in the form <host>:<ip>, for containers on the network for builds for the runtime (only used by docker)",
+	},
+	&cli.BoolFlag{
+		EnvVars:  []string{"VELA_RUNTIME_EGRESS_POLICY", "RUNTIME_EGRESS_POLICY"},
+		FilePath: "/vela/runtime/egress_policy",
+		Name:     "runtime.egress.policy",
+		Usage:    "enables restricting the destinations builds are able to reach for the runtime",
+	},
+	&cli.StringSliceFlag{
+		EnvVars:  []string{"VELA_RUNTIME_EGRESS_ALLOW", "RUNTIME_EGRESS_ALLOW"},
+		FilePath: "/vela/runtime/egress_allow",
+		Name:     "runtime.egress.allow",
+		Usage:    "list of destinations, in the form <cidr>[=<port>[/<protocol>]], builds are allowed to reach for the runtime (only used by kubernetes)",
+	},
+	&cli.StringFlag{
+		EnvVars:  []string{"VELA_RUNTIME_EGRESS_PROXY", "RUNTIME_EGRESS_PROXY"},
+		FilePath: "/vela/runtime/egress_proxy",
+		Name:     "runtime.egress.proxy",
+		Usage:    "container running the proxy, in the form <container>:<port>, builds reach external hosts through for the runtime (only used by docker)",
+	},
 	&cli.StringSliceFlag{
 		EnvVars:  []string{"VELA_RUNTIME_VOLUMES", "RUNTIME_VOLUMES"},
 		FilePath: "/vela/runtime/volumes",
@@ -0,0 +1,29 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package runtime
+
+import (
+	"context"
+
+	"github.com/go-vela/pkg-runtime/runtime/docker"
+)
+
+// ImageGCReport represents the result of
+// removing unused images from the host.
+type ImageGCReport = docker.ImageGCReport
+
+// ImageCollector represents an optional interface for a
+// runtime capable of removing unused images from the host.
+//
+// Use a type assertion on the Engine to check for support:
+//
+// 	if collector, ok := engine.(runtime.ImageCollector); ok {
+// 		report, err := collector.CollectImages(ctx)
+// 	}
+type ImageCollector interface {
+	// CollectImages defines a function that removes
+	// unused images from the host.
+	CollectImages(context.Context) (*ImageGCReport, error)
+}
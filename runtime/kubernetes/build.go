@@ -7,10 +7,13 @@ package kubernetes
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/go-vela/pkg-runtime/internal/image"
 	"github.com/go-vela/types/pipeline"
 
 	"github.com/buildkite/yaml"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sirupsen/logrus"
 
 	v1 "k8s.io/api/core/v1"
@@ -21,17 +24,36 @@ import (
 func (c *client) InspectBuild(ctx context.Context, b *pipeline.Build) ([]byte, error) {
 	logrus.Tracef("inspecting build pod for pipeline %s", b.ID)
 
+	s, err := c.buildState(b)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	output := []byte(fmt.Sprintf("> Inspecting pod for pipeline %s", b.ID))
 
-	// TODO: The environment gets populated in AssembleBuild, after InspectBuild runs.
-	//       But, we should make sure that secrets can't be leaked here anyway.
-	buildOutput, err := yaml.Marshal(c.Pod)
+	// The environment gets populated in AssembleBuild, after InspectBuild runs,
+	// and secrets are referenced from the secret for the build. However, any
+	// values for secrets are still redacted from the pod to avoid leaking them.
+	buildOutput, err := yaml.Marshal(s.redactPod())
 	if err != nil {
 		return []byte{}, fmt.Errorf("unable to serialize pod: %w", err)
 	}
 
 	output = append(output, buildOutput...)
 
+	// check if the build has any secrets
+	if s.secret != nil {
+		output = append(output, fmt.Sprintf("> Inspecting secret %s\n", s.secret.ObjectMeta.Name)...)
+
+		// iterate through each key in the secret without the value
+		for key := range s.secret.Data {
+			output = append(output, fmt.Sprintf("%s: %s\n", key, redacted)...)
+		}
+	}
+
 	// TODO: make other k8s Inspect* funcs no-ops (prefer this method):
 	// 	     InspectVolume, InspectImage, InspectNetwork
 	return output, nil
@@ -41,21 +63,41 @@ func (c *client) InspectBuild(ctx context.Context, b *pipeline.Build) ([]byte, e
 func (c *client) SetupBuild(ctx context.Context, b *pipeline.Build) error {
 	logrus.Tracef("setting up for build %s", b.ID)
 
+	s := c.setupState(b)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	// create the object metadata for the pod
 	//
 	// https://pkg.go.dev/k8s.io/apimachinery/pkg/apis/meta/v1?tab=doc#ObjectMeta
-	c.Pod.ObjectMeta = metav1.ObjectMeta{
+	s.Pod.ObjectMeta = metav1.ObjectMeta{
 		Name:   b.ID,
-		Labels: map[string]string{"pipeline": b.ID},
+		Labels: map[string]string{"pipeline": b.ID, LabelWorker: c.config.WorkerID},
 	}
 
-	// TODO: Vela admin defined worker-specific:
-	//       NodeSelector, Tolerations, Affinity, AutomountServiceAccountToken
-
 	// create the restart policy for the pod
 	//
 	// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#RestartPolicy
-	c.Pod.Spec.RestartPolicy = v1.RestartPolicyNever
+	s.Pod.Spec.RestartPolicy = v1.RestartPolicyNever
+
+	// check if a pod template is provided
+	if c.config.PodTemplate != nil {
+		logrus.Tracef("applying pod template to build %s", b.ID)
+
+		// merge the pod template into the pod
+		c.config.PodTemplate.Apply(s.Pod)
+	}
+
+	// check if the build requires a platform
+	if p := c.platform(b); p != nil {
+		logrus.Tracef("scheduling build %s on platform %s", b.ID, image.FormatPlatform(p))
+
+		// add the node affinity for the platform
+		//
+		// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#Affinity
+		s.Pod.Spec.Affinity = mergeAffinity(s.Pod.Spec.Affinity, platformAffinity(p))
+	}
 
 	return nil
 }
@@ -67,11 +109,18 @@ func (c *client) SetupBuild(ctx context.Context, b *pipeline.Build) error {
 // before running AssembleBuild.
 func (c *client) AssembleBuild(ctx context.Context, b *pipeline.Build) error {
 	logrus.Tracef("assembling build %s", b.ID)
-	var err error
+
+	s, err := c.buildState(b)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
 	// last minute Environment setup
 	for _, _service := range b.Services {
-		err = c.setupContainerEnvironment(_service)
+		err = c.setupContainerEnvironment(s, _service)
 		if err != nil {
 			return err
 		}
@@ -82,7 +131,7 @@ func (c *client) AssembleBuild(ctx context.Context, b *pipeline.Build) error {
 			continue
 		}
 		for _, _step := range _stage.Steps {
-			err = c.setupContainerEnvironment(_step)
+			err = c.setupContainerEnvironment(s, _step)
 			if err != nil {
 				return err
 			}
@@ -93,7 +142,7 @@ func (c *client) AssembleBuild(ctx context.Context, b *pipeline.Build) error {
 		if _step.Name == "init" {
 			continue
 		}
-		err = c.setupContainerEnvironment(_step)
+		err = c.setupContainerEnvironment(s, _step)
 		if err != nil {
 			return err
 		}
@@ -102,37 +151,112 @@ func (c *client) AssembleBuild(ctx context.Context, b *pipeline.Build) error {
 		if _secret.Origin.Empty() {
 			continue
 		}
-		err = c.setupContainerEnvironment(_secret.Origin)
+		err = c.setupContainerEnvironment(s, _secret.Origin)
 		if err != nil {
 			return err
 		}
 	}
 
+	// create the secret for the build before the pod referencing it
+	err = c.createSecret(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	// check if the annotations for the pod are provided
+	if s.Pod.ObjectMeta.Annotations == nil {
+		s.Pod.ObjectMeta.Annotations = make(map[string]string)
+	}
+
+	// add the heartbeat from the worker to the pod
+	s.Pod.ObjectMeta.Annotations[AnnotationHeartbeat] = time.Now().UTC().Format(time.RFC3339)
+
 	// If the api call to create the pod fails, the pod might
 	// partially exist. So, set this first to make sure all
 	// remnants get deleted.
-	c.createdPod = true
+	s.createdPod = true
 
-	logrus.Infof("creating pod %s", c.Pod.ObjectMeta.Name)
+	logrus.Infof("creating pod %s", s.Pod.ObjectMeta.Name)
 	// send API call to create the pod
 	//
 	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#PodInterface
-	_, err = c.Kubernetes.CoreV1().
+	pod, err := c.Kubernetes.CoreV1().
 		Pods(c.config.Namespace).
-		Create(context.Background(), c.Pod, metav1.CreateOptions{})
+		Create(context.Background(), s.Pod, metav1.CreateOptions{})
 	if err != nil {
 		return err
 	}
 
-	return nil
+	// update the heartbeat for the pod until the build is removed
+	heartbeat, stop := context.WithCancel(context.Background())
+	s.stopHeartbeat = stop
+
+	go c.heartbeat(heartbeat, pod.ObjectMeta.Name)
+
+	err = c.ownSecret(ctx, s, pod)
+	if err != nil {
+		return err
+	}
+
+	err = c.ownClaim(ctx, s, b, pod)
+	if err != nil {
+		return err
+	}
+
+	return c.ownPolicy(ctx, s, pod)
 }
 
 // RemoveBuild deletes (kill, remove) the pipeline build metadata.
-// This deletes the kubernetes pod.
+// This deletes the kubernetes pod, the secret for the build, the
+// claim for the workspace and any network policy left behind.
 func (c *client) RemoveBuild(ctx context.Context, b *pipeline.Build) error {
 	logrus.Tracef("removing build %s", b.ID)
 
-	if !c.createdPod {
+	s, err := c.buildState(b)
+	if err != nil {
+		// nothing to do
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// check if the heartbeat for the pod is updating
+	if s.stopHeartbeat != nil {
+		s.stopHeartbeat()
+		s.stopHeartbeat = nil
+	}
+
+	err = c.removePod(s)
+	if err != nil {
+		return err
+	}
+
+	err = c.removeSecret(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	err = c.removeClaim(ctx, s, b)
+	if err != nil {
+		return err
+	}
+
+	err = c.removePolicy(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	// the build no longer needs to be tracked by the client
+	c.removeState(b)
+
+	return nil
+}
+
+// removePod is a helper function to delete
+// the kubernetes pod for the pipeline build.
+func (c *client) removePod(s *buildState) error {
+	if !s.createdPod {
 		// nothing to do
 		return nil
 	}
@@ -155,17 +279,102 @@ func (c *client) RemoveBuild(ctx context.Context, b *pipeline.Build) error {
 		PropagationPolicy: &policy,
 	}
 
-	logrus.Infof("removing pod %s", c.Pod.ObjectMeta.Name)
+	logrus.Infof("removing pod %s", s.Pod.ObjectMeta.Name)
 	// send API call to delete the pod
 	err := c.Kubernetes.CoreV1().
 		Pods(c.config.Namespace).
-		Delete(context.Background(), c.Pod.ObjectMeta.Name, opts)
+		Delete(context.Background(), s.Pod.ObjectMeta.Name, opts)
 	if err != nil {
 		return err
 	}
 
-	c.Pod = &v1.Pod{}
-	c.createdPod = false
+	s.createdPod = false
 
 	return nil
 }
+
+// platform is a helper function to capture the platform
+// for images in the pipeline build. The worker platform
+// for the build is used when it is a valid platform,
+// otherwise the default platform is used.
+func (c *client) platform(b *pipeline.Build) *specs.Platform {
+	// parse the worker platform for the build
+	//
+	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/image#ParsePlatform
+	p, err := image.ParsePlatform(b.Worker.Platform)
+	if err != nil || p == nil {
+		return c.config.Platform
+	}
+
+	return p
+}
+
+// platformAffinity is a helper function to create a node
+// affinity requiring nodes matching the platform.
+//
+// The variant of the platform has no well-known node
+// label, so only the os and architecture are required.
+//
+// https://kubernetes.io/docs/reference/labels-annotations-taints/#kubernetes-io-arch
+func platformAffinity(p *specs.Platform) *v1.Affinity {
+	return &v1.Affinity{
+		NodeAffinity: &v1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+				NodeSelectorTerms: []v1.NodeSelectorTerm{
+					{
+						MatchExpressions: []v1.NodeSelectorRequirement{
+							{
+								Key:      v1.LabelOSStable,
+								Operator: v1.NodeSelectorOpIn,
+								Values:   []string{p.OS},
+							},
+							{
+								Key:      v1.LabelArchStable,
+								Operator: v1.NodeSelectorOpIn,
+								Values:   []string{p.Architecture},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// mergeAffinity is a helper function to require the node
+// affinity for the platform in addition to the affinity
+// from the pod template.
+//
+// The terms for a node selector are ORed, while the
+// expressions within each term are ANDed, so the
+// expressions for the platform are added to every term.
+func mergeAffinity(affinity, platform *v1.Affinity) *v1.Affinity {
+	// check if an affinity is provided
+	if affinity == nil {
+		return platform
+	}
+
+	required := platform.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+
+	// check if a node affinity is provided
+	if affinity.NodeAffinity == nil {
+		affinity.NodeAffinity = &v1.NodeAffinity{}
+	}
+
+	// check if a required node affinity is provided
+	if affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil ||
+		len(affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms) == 0 {
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = required
+
+		return affinity
+	}
+
+	terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+
+	// add the expressions for the platform to every term
+	for i := range terms {
+		terms[i].MatchExpressions = append(terms[i].MatchExpressions, required.NodeSelectorTerms[0].MatchExpressions...)
+	}
+
+	return affinity
+}
@@ -6,6 +6,7 @@ package kubernetes
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
 	"github.com/go-vela/types/pipeline"
@@ -95,6 +96,88 @@ func TestKubernetes_SetupBuild(t *testing.T) {
 	}
 }
 
+func TestKubernetes_SetupBuild_Platform(t *testing.T) {
+	// setup types
+	_arm64 := *_steps
+	_arm64.Worker = pipeline.Worker{Platform: "linux/arm64/v8"}
+
+	_routed := *_steps
+	_routed.Worker = pipeline.Worker{Platform: "docker"}
+
+	// setup tests
+	tests := []struct {
+		name     string
+		platform string
+		pipeline *pipeline.Build
+		want     []string
+	}{
+		{
+			name:     "no platform",
+			pipeline: _steps,
+			want:     nil,
+		},
+		{
+			name:     "default platform",
+			platform: "linux/amd64",
+			pipeline: _steps,
+			want:     []string{"linux", "amd64"},
+		},
+		{
+			name:     "build platform",
+			platform: "linux/amd64",
+			pipeline: &_arm64,
+			want:     []string{"linux", "arm64"},
+		},
+		{
+			name:     "build routing platform",
+			platform: "linux/amd64",
+			pipeline: &_routed,
+			want:     []string{"linux", "amd64"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := NewMock(&v1.Pod{}, WithPlatform(test.platform))
+		if err != nil {
+			t.Errorf("unable to create runtime engine: %v", err)
+		}
+
+		err = _engine.SetupBuild(context.Background(), test.pipeline)
+		if err != nil {
+			t.Errorf("SetupBuild for %s returned err: %v", test.name, err)
+		}
+
+		affinity := _engine.builds[test.pipeline.ID].Pod.Spec.Affinity
+
+		if test.want == nil {
+			if affinity != nil {
+				t.Errorf("SetupBuild for %s affinity is %v, want nil", test.name, affinity)
+			}
+
+			continue
+		}
+
+		if affinity == nil || affinity.NodeAffinity == nil {
+			t.Errorf("SetupBuild for %s affinity is nil, want %v", test.name, test.want)
+
+			continue
+		}
+
+		got := []string{}
+
+		for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+			for _, expression := range term.MatchExpressions {
+				got = append(got, expression.Values...)
+			}
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("SetupBuild for %s affinity is %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
 func TestKubernetes_AssembleBuild(t *testing.T) {
 	// setup tests
 	tests := []struct {
@@ -134,11 +217,12 @@ func TestKubernetes_AssembleBuild(t *testing.T) {
 	// run tests
 	for _, test := range tests {
 		_engine, err := NewMock(test.k8sPod)
-		_engine.Pod = test.enginePod
 		if err != nil {
 			t.Errorf("unable to create runtime engine: %v", err)
 		}
 
+		_engine.builds = map[string]*buildState{test.pipeline.ID: newBuildState(test.enginePod)}
+
 		err = _engine.AssembleBuild(context.Background(), test.pipeline)
 
 		if test.failure {
@@ -207,7 +291,11 @@ func TestKubernetes_RemoveBuild(t *testing.T) {
 		if err != nil {
 			t.Errorf("unable to create runtime engine: %v", err)
 		}
-		_engine.createdPod = test.createdPod
+
+		_state := newBuildState(test.pod)
+		_state.createdPod = test.createdPod
+
+		_engine.builds = map[string]*buildState{test.pipeline.ID: _state}
 
 		err = _engine.RemoveBuild(context.Background(), test.pipeline)
 
@@ -5,15 +5,16 @@
 package kubernetes
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"io"
-	"io/ioutil"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-vela/pkg-runtime/internal/image"
+	"github.com/go-vela/pkg-runtime/internal/logs"
+	"github.com/go-vela/pkg-runtime/internal/secret"
 	"github.com/go-vela/types/constants"
 	"github.com/go-vela/types/pipeline"
 
@@ -21,14 +22,34 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
+const (
+	// maxWatchRetries represents the maximum number of consecutive
+	// times the watch on the pod can close right after opening it.
+	maxWatchRetries = 5
+
+	// minWatchDuration represents the duration the watch on the pod
+	// must stay open to not count as closing right after opening it.
+	minWatchDuration = 5 * time.Second
+)
+
+// watchRetryDelay represents the initial delay before resuming
+// the watch on the pod after it closed right after opening it.
+var watchRetryDelay = 250 * time.Millisecond
+
 // InspectContainer inspects the pipeline container.
 func (c *client) InspectContainer(ctx context.Context, ctn *pipeline.Container) error {
 	logrus.Tracef("inspecting container %s", ctn.ID)
 
+	s, err := c.containerState(ctn)
+	if err != nil {
+		return err
+	}
+
 	// create options for getting the container
 	opts := metav1.GetOptions{}
 
@@ -37,7 +58,7 @@ func (c *client) InspectContainer(ctx context.Context, ctn *pipeline.Container)
 	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#PodInterface
 	pod, err := c.Kubernetes.CoreV1().Pods(c.config.Namespace).Get(
 		context.Background(),
-		c.Pod.ObjectMeta.Name,
+		s.name(),
 		opts,
 	)
 	if err != nil {
@@ -76,22 +97,69 @@ func (c *client) RemoveContainer(ctx context.Context, ctn *pipeline.Container) e
 // nolint: lll // ignore long line length
 func (c *client) RunContainer(ctx context.Context, ctn *pipeline.Container, b *pipeline.Build) error {
 	logrus.Tracef("running container %s", ctn.ID)
-	// parse image from step
-	_image, err := image.ParseWithError(ctn.Image)
+
+	s, err := c.containerState(ctn)
+	if err != nil {
+		return err
+	}
+
+	// check if the image is permitted by the image policy
+	//
+	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/image#Policy.Validate
+	err = c.config.Policy.Validate(ctn.Image)
+	if err != nil {
+		return err
+	}
+
+	// verify the signature for the image
+	//
+	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/signature#Verifier.Verify
+	digest, err := c.config.Verifier.Verify(ctx, ctn.Image, c.config.Mirrors)
+	if err != nil {
+		return err
+	}
+
+	// parse image from step with the registry mirrors
+	//
+	// The kubelet is responsible for pulling the image, so
+	// there is no fallback to the origin registry when the
+	// image can't be pulled from the mirror.
+	//
+	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/image#ParseWithMirror
+	_image, err := image.ParseWithMirror(ctn.Image, c.config.Mirrors)
+	if err != nil {
+		return err
+	}
+
+	// check if the image signature was verified
+	if len(digest) > 0 {
+		// pin the image to the verified digest
+		//
+		// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/image#ParseWithDigest
+		_image, err = image.ParseWithDigest(_image, digest)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// capture the pod container for the step
+	container, err := s.podContainer(ctn)
 	if err != nil {
 		return err
 	}
 
 	// set the pod container image to the parsed step image
-	// (-1 to convert to 0-based index, -1 for init which isn't a container)
-	c.Pod.Spec.Containers[ctn.Number-2].Image = _image
+	container.Image = _image
 
 	// send API call to patch the pod with the new container image
 	//
 	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#PodInterface
 	_, err = c.Kubernetes.CoreV1().Pods(c.config.Namespace).Patch(
 		context.Background(),
-		c.Pod.ObjectMeta.Name,
+		s.Pod.ObjectMeta.Name,
 		types.StrategicMergePatchType,
 		[]byte(fmt.Sprintf(imagePatch, ctn.ID, _image)),
 		metav1.PatchOptions{},
@@ -107,6 +175,27 @@ func (c *client) RunContainer(ctx context.Context, ctn *pipeline.Container, b *p
 func (c *client) SetupContainer(ctx context.Context, ctn *pipeline.Container) error {
 	logrus.Tracef("setting up for container %s", ctn.ID)
 
+	s, err := c.containerState(ctn)
+	if err != nil {
+		return err
+	}
+
+	// check if the image is permitted by the image policy
+	//
+	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/image#Policy.Validate
+	err = c.config.Policy.Validate(ctn.Image)
+	if err != nil {
+		return err
+	}
+
+	// verify the signature for the image
+	//
+	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/signature#Verifier.Verify
+	_, err = c.config.Verifier.Verify(ctx, ctn.Image, c.config.Mirrors)
+	if err != nil {
+		return err
+	}
+
 	// create the container object for the pod
 	//
 	// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#Container
@@ -123,7 +212,7 @@ func (c *client) SetupContainer(ctx context.Context, ctn *pipeline.Container) er
 		// the containers with the proper image.
 		//
 		// https://hub.docker.com/r/kubernetes/pause
-		Image:      image.Parse("kubernetes/pause:latest"),
+		Image:      c.pauseImage(),
 		Env:        []v1.EnvVar{},
 		Stdin:      false,
 		StdinOnce:  false,
@@ -153,13 +242,29 @@ func (c *client) SetupContainer(ctx context.Context, ctn *pipeline.Container) er
 		container.ImagePullPolicy = v1.PullIfNotPresent
 	}
 
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	// fill in the VolumeMounts including workspaceMount
-	volumeMounts, err := c.setupVolumeMounts(ctx, ctn)
+	volumeMounts, err := c.setupVolumeMounts(ctx, s, ctn)
 	if err != nil {
 		return err
 	}
 	container.VolumeMounts = volumeMounts
 
+	// check if the ulimits are provided
+	//
+	// Kubernetes has no support for setting the ulimits for a container.
+	//
+	// https://github.com/kubernetes/kubernetes/issues/3595
+	if len(ctn.Ulimits) > 0 {
+		return &CapabilityError{
+			Container: ctn.ID,
+			Setting:   "ulimits",
+			Reason:    "ulimits are not supported by the Kubernetes runtime",
+		}
+	}
+
 	// check if the image is allowed to run privileged
 	for _, pattern := range c.config.Images {
 		privileged, err := image.IsPrivilegedImage(ctn.Image, pattern)
@@ -172,51 +277,107 @@ func (c *client) SetupContainer(ctx context.Context, ctn *pipeline.Container) er
 		}
 	}
 
-	// TODO: add SecurityContext options (runAsUser, runAsNonRoot, sysctls)
+	// check if the user is provided
+	if len(ctn.User) > 0 {
+		user, group, err := runAsUser(ctn)
+		if err != nil {
+			return err
+		}
+
+		if container.SecurityContext == nil {
+			container.SecurityContext = new(v1.SecurityContext)
+		}
+
+		// add user and group to container config
+		//
+		// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#SecurityContext
+		container.SecurityContext.RunAsUser = user
+		container.SecurityContext.RunAsGroup = group
+	}
+
+	// TODO: add SecurityContext options (runAsNonRoot, sysctls)
 
 	// Executor.CreateBuild extends the environment AFTER calling Runtime.SetupBuild.
 	// So, configure the environment as late as possible (just before pod creation).
 
 	// check if the entrypoint is provided
+	//
+	// The command for a Kubernetes container replaces the
+	// ENTRYPOINT for the image, and the args replace the CMD,
+	// matching the entrypoint and cmd for a Docker container.
+	//
+	// https://kubernetes.io/docs/tasks/inject-data-application/define-command-argument-container/#notes
 	if len(ctn.Entrypoint) > 0 {
 		// add entrypoint to container config
-		container.Args = ctn.Entrypoint
+		container.Command = ctn.Entrypoint
 	}
 
 	// check if the commands are provided
 	if len(ctn.Commands) > 0 {
 		// add commands to container config
-		container.Args = append(container.Args, ctn.Commands...)
+		container.Args = ctn.Commands
 	}
 
 	// add the container definition to the pod spec
 	//
 	// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#PodSpec
-	c.Pod.Spec.Containers = append(c.Pod.Spec.Containers, container)
+	s.Pod.Spec.Containers = append(s.Pod.Spec.Containers, container)
+
+	// check if the index of pod containers is created
+	if s.containers == nil {
+		s.containers = make(map[string]int)
+	}
+
+	// add the container definition to the index of pod containers
+	s.containers[ctn.ID] = len(s.Pod.Spec.Containers) - 1
 
 	return nil
 }
 
 // setupContainerEnvironment adds env vars to the Pod spec for a container.
 // Call this just before pod creation to capture as many env changes as possible.
-func (c *client) setupContainerEnvironment(ctn *pipeline.Container) error {
+func (c *client) setupContainerEnvironment(s *buildState, ctn *pipeline.Container) error {
 	logrus.Tracef("setting up environment for container %s", ctn.ID)
 
 	// get the matching container spec
-	// (-1 to convert to 0-based index, -1 for injected init container)
-	container := &c.Pod.Spec.Containers[ctn.Number-2]
-	if !strings.EqualFold(container.Name, ctn.ID) {
-		return fmt.Errorf("wrong container! got %s instead of %s", container.Name, ctn.ID)
+	container, err := s.podContainer(ctn)
+	if err != nil {
+		return err
+	}
+
+	env := ctn.Environment
+
+	var files map[string]string
+
+	// check if the secrets are delivered as files
+	if len(c.config.SecretFiles) > 0 {
+		// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/secret#Files
+		env, files = secret.Files(ctn, c.config.SecretFiles)
 	}
 
 	// check if the environment is provided
-	if len(ctn.Environment) > 0 {
+	if len(env) > 0 {
 		// iterate through each element in the container environment
-		for k, v := range ctn.Environment {
+		for k, v := range env {
+			// check if the environment variable is from a secret
+			if secret.Is(ctn, k) {
+				// add the environment referencing the secret for the build
+				container.Env = append(container.Env, s.secretEnv(ctn, k, v))
+
+				continue
+			}
+
 			// add key/value environment to container config
 			container.Env = append(container.Env, v1.EnvVar{Name: k, Value: v})
 		}
 	}
+
+	// check if any secrets are delivered as files
+	if len(files) > 0 {
+		// mount the files for the secrets in the container
+		s.secretVolume(ctn, container, files)
+	}
+
 	return nil
 }
 
@@ -226,157 +387,411 @@ func (c *client) setupContainerEnvironment(ctn *pipeline.Container) error {
 func (c *client) TailContainer(ctx context.Context, ctn *pipeline.Container) (io.ReadCloser, error) {
 	logrus.Tracef("tailing output for container %s", ctn.ID)
 
-	// create object to store container logs
-	var logs io.ReadCloser
+	s, err := c.containerState(ctn)
+	if err != nil {
+		return nil, err
+	}
 
-	// create function for periodically capturing
-	// the logs from the container with backoff
-	logsFunc := func() (bool, error) {
-		// create options for capturing the logs from the container
-		//
-		// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#PodLogOptions
-		opts := &v1.PodLogOptions{
-			Container: ctn.ID,
-			Follow:    true,
-			// steps can exit quickly, and might be gone before
-			// log tailing has started, so we need to request
-			// logs for previously exited containers as well.
-			// Pods get deleted after job completion, and names for
-			// pod+container don't get reused. So, previous
-			// should only retrieve logs for the current build step.
-			Previous:   true,
-			Timestamps: false,
+	// capture the stream of container logs without timestamps
+	stream, err := c.streamLogs(ctx, s, ctn, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// check if the logs for the container are limited
+	if c.config.LogLimit.Enabled() {
+		// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/logs#NewLimitReader
+		return logs.NewLimitReader(stream, c.config.LogLimit, c.logLimitExceeded(s, ctn)), nil
+	}
+
+	return stream, nil
+}
+
+// TailRecords captures the log records, with the stream and
+// timestamp for each line, for the pipeline container.
+//
+// Kubernetes merges stdout and stderr into a single stream
+// of container logs, so every record is for stdout.
+func (c *client) TailRecords(ctx context.Context, ctn *pipeline.Container) (logs.Reader, error) {
+	logrus.Tracef("tailing records for container %s", ctn.ID)
+
+	s, err := c.containerState(ctn)
+	if err != nil {
+		return nil, err
+	}
+
+	// capture the stream of container logs with timestamps
+	stream, err := c.streamLogs(ctx, s, ctn, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/logs#NewReader
+	records := logs.NewReader(stream, logs.StreamStdout)
+
+	// check if the logs for the container are limited
+	if c.config.LogLimit.Enabled() {
+		// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/logs#NewLimitRecords
+		return logs.NewLimitRecords(records, c.config.LogLimit, c.logLimitExceeded(s, ctn)), nil
+	}
+
+	return records, nil
+}
+
+// logLimitExceeded is a helper function to create the function
+// called when the logs for the pipeline container exceed the
+// log limits. The pod is deleted when configured, because
+// Kubernetes can not kill a single container in a pod.
+func (c *client) logLimitExceeded(s *buildState, ctn *pipeline.Container) func(*logs.LimitError) {
+	return func(limitErr *logs.LimitError) {
+		logrus.Warnf("truncating logs for container %s: %v", ctn.ID, limitErr)
+
+		// check if the container should be killed
+		if !c.config.LogLimit.Kill {
+			return
 		}
 
-		// send API call to capture stream of container logs
+		name := s.name()
+
+		logrus.Warnf("deleting pod %s for container %s exceeding log limit", name, ctn.ID)
+
+		// create variables for the delete options
+		//
+		// This is necessary because the delete options
+		// expect all values to be passed by reference.
+		period := int64(0)
+
+		// send API call to delete the pod
 		//
-		// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#PodExpansion
-		// ->
-		// https://pkg.go.dev/k8s.io/client-go/rest?tab=doc#Request.Stream
-		stream, err := c.Kubernetes.CoreV1().
+		// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#PodInterface
+		err := c.Kubernetes.CoreV1().
 			Pods(c.config.Namespace).
-			GetLogs(c.Pod.ObjectMeta.Name, opts).
-			Stream(context.Background())
+			Delete(context.Background(), name, metav1.DeleteOptions{GracePeriodSeconds: &period})
 		if err != nil {
-			logrus.Errorf("%v", err)
-			return false, nil
+			logrus.Errorf("unable to delete pod %s: %v", name, err)
+
+			return
 		}
 
-		// create temporary reader to ensure logs are available
-		reader := bufio.NewReader(stream)
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		// the pod no longer needs to be deleted by RemoveBuild
+		s.createdPod = false
+	}
+}
 
-		// peek at container logs from the stream
+// WaitContainer blocks until the pipeline container completes.
+//
+// If the pod fails in a way the container is unable to recover
+// from, like being evicted or unable to pull the image for the
+// container, it returns a PodFailureError instead of waiting.
+func (c *client) WaitContainer(ctx context.Context, ctn *pipeline.Container) error {
+	logrus.Tracef("waiting for container %s", ctn.ID)
+
+	s, err := c.containerState(ctn)
+	if err != nil {
+		return err
+	}
+
+	// wait for the container to be terminated
+	return c.watchContainer(ctx, s, ctn, func(cst *v1.ContainerStatus) bool {
+		// check if the container has a terminated state reason
 		//
-		// nolint: gomnd // ignore magic number
-		bytes, err := reader.Peek(5)
-		if err != nil {
-			// skip so we resend API call to capture stream
-			return false, nil
-		}
+		// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#ContainerStateTerminated
+		return cst.State.Terminated != nil && len(cst.State.Terminated.Reason) > 0
+	})
+}
 
-		// check if we have container logs from the stream
-		if len(bytes) > 0 {
-			// set the logs to the reader
-			logs = ioutil.NopCloser(reader)
-			return true, nil
+// podContainer is a helper function to capture the
+// container in the pod spec for the pipeline container.
+//
+// The containers are looked up by ID with an index of the
+// pod containers, so the pipeline containers can be added
+// to the pod in any order. The index is rebuilt when it
+// doesn't match the containers in the pod spec.
+func (s *buildState) podContainer(ctn *pipeline.Container) (*v1.Container, error) {
+	i, ok := s.containers[ctn.ID]
+
+	// check if the index matches the containers in the pod spec
+	if !ok || i >= len(s.Pod.Spec.Containers) || s.Pod.Spec.Containers[i].Name != ctn.ID {
+		s.indexContainers()
+
+		i, ok = s.containers[ctn.ID]
+		if !ok {
+			return nil, fmt.Errorf("container %s not found in pod %s", ctn.ID, s.Pod.ObjectMeta.Name)
 		}
+	}
+
+	return &s.Pod.Spec.Containers[i], nil
+}
 
-		// no logs are available
-		return false, nil
+// indexContainers is a helper function to create the
+// index of the containers in the pod spec by name.
+func (s *buildState) indexContainers() {
+	s.containers = make(map[string]int, len(s.Pod.Spec.Containers))
+
+	// iterate through each container in the pod spec
+	for i, container := range s.Pod.Spec.Containers {
+		s.containers[container.Name] = i
 	}
+}
 
-	// create backoff object for capturing the logs
-	// from the container with periodic backoff
-	//
-	// https://pkg.go.dev/k8s.io/apimachinery/pkg/util/wait?tab=doc#Backoff
-	backoff := wait.Backoff{
-		Duration: 1 * time.Second,
-		Factor:   2.0,
-		Jitter:   0.25,
-		Steps:    10,
-		Cap:      2 * time.Minute,
+// runAsUser is a helper function to capture the numeric user
+// and group IDs from the user for the pipeline container.
+//
+// The user may be provided as <user> or <user>:<group>. Kubernetes
+// requires numeric IDs, and the names for users and groups can't
+// be resolved without the image, so only the root name is allowed.
+func runAsUser(ctn *pipeline.Container) (*int64, *int64, error) {
+	parts := strings.SplitN(ctn.User, ":", 2)
+
+	// parse the user ID for the container
+	user, err := parseID(parts[0])
+	if err != nil {
+		return nil, nil, &CapabilityError{
+			Container: ctn.ID,
+			Setting:   "user",
+			Reason:    fmt.Sprintf("user %s is not a numeric user ID", parts[0]),
+		}
 	}
 
-	logrus.Tracef("capturing logs with exponential backoff for container %s", ctn.ID)
-	// perform the function to capture logs with periodic backoff
-	//
-	// https://pkg.go.dev/k8s.io/apimachinery/pkg/util/wait?tab=doc#ExponentialBackoff
-	err := wait.ExponentialBackoff(backoff, logsFunc)
+	// check if the group is provided
+	if len(parts) == 1 {
+		return user, nil, nil
+	}
+
+	// parse the group ID for the container
+	group, err := parseID(parts[1])
 	if err != nil {
-		return nil, err
+		return nil, nil, &CapabilityError{
+			Container: ctn.ID,
+			Setting:   "user",
+			Reason:    fmt.Sprintf("group %s is not a numeric group ID", parts[1]),
+		}
 	}
 
-	return logs, nil
+	return user, group, nil
 }
 
-// WaitContainer blocks until the pipeline container completes.
-func (c *client) WaitContainer(ctx context.Context, ctn *pipeline.Container) error {
-	logrus.Tracef("waiting for container %s", ctn.ID)
+// parseID is a helper function to parse the
+// numeric ID for a user or group.
+func parseID(id string) (*int64, error) {
+	// check if the ID is for the root user or group
+	if id == "root" {
+		id = "0"
+	}
 
-	// create label selector for watching the pod
-	selector := fmt.Sprintf("pipeline=%s", c.Pod.ObjectMeta.Name)
+	// https://pkg.go.dev/strconv#ParseInt
+	value, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, err
+	}
 
-	// create options for watching the container
-	opts := metav1.ListOptions{
-		LabelSelector: selector,
-		Watch:         true,
+	// check if the ID is negative
+	if value < 0 {
+		return nil, fmt.Errorf("invalid ID %s", id)
 	}
 
-	// send API call to capture channel for watching the container
-	//
-	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#PodInterface
-	// ->
-	// https://pkg.go.dev/k8s.io/apimachinery/pkg/watch?tab=doc#Interface
-	watch, err := c.Kubernetes.CoreV1().Pods(c.config.Namespace).Watch(context.Background(), opts)
+	return &value, nil
+}
+
+// pauseImage is a helper function to produce the
+// kubernetes/pause image with the registry mirrors.
+func (c *client) pauseImage() string {
+	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/image#ParseWithMirror
+	_image, err := image.ParseWithMirror("kubernetes/pause:latest", c.config.Mirrors)
 	if err != nil {
-		return err
+		return image.Parse("kubernetes/pause:latest")
 	}
 
+	return _image
+}
+
+// watchContainer is a helper function to block until the
+// provided function returns true for the status of the
+// pipeline container, or the pod fails in a way the
+// container is unable to recover from. When the API server
+// closes the watch on the pod, the pod is captured again
+// and the watch is resumed from the resource version of
+// the pod, with backoff when the watch keeps closing
+// right after opening it.
+//
+// nolint: funlen // ignore function length due to comments
+func (c *client) watchContainer(ctx context.Context, s *buildState, ctn *pipeline.Container, done func(*v1.ContainerStatus) bool) error {
+	name := s.name()
+
+	// track the attempts to pull the image for the container
+	pulls := new(imagePulls)
+
+	// number of consecutive times the watch closed right after opening it
+	retries := 0
+
 	for {
-		// capture new result from the channel
+		// send API call to capture the pod
 		//
-		// https://pkg.go.dev/k8s.io/apimachinery/pkg/watch?tab=doc#Interface
-		result := <-watch.ResultChan()
+		// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#PodInterface
+		pod, err := c.Kubernetes.CoreV1().
+			Pods(c.config.Namespace).
+			Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
 
-		// convert the object from the result to a pod
-		pod, ok := result.Object.(*v1.Pod)
-		if !ok {
-			return fmt.Errorf("unable to watch pod %s", c.Pod.ObjectMeta.Name)
+		// check if the container is in the expected state
+		found, err := checkContainer(pod, ctn, pulls, done)
+		if err != nil || found {
+			return err
 		}
 
-		// check if the pod is in a pending state
+		// create options for watching the pod
 		//
-		// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#PodStatus
-		if pod.Status.Phase == v1.PodPending {
-			// skip pod if it's in a pending state
-			continue
+		// https://pkg.go.dev/k8s.io/apimachinery/pkg/apis/meta/v1?tab=doc#ListOptions
+		opts := metav1.ListOptions{
+			FieldSelector:   fields.OneTermEqualSelector("metadata.name", name).String(),
+			ResourceVersion: pod.ObjectMeta.ResourceVersion,
+			Watch:           true,
 		}
 
-		// iterate through each container in the pod
-		for _, cst := range pod.Status.ContainerStatuses {
-			// check if the container has a matching ID
-			//
-			// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#ContainerStatus
-			if !strings.EqualFold(cst.Name, ctn.ID) {
-				// skip container if it's not a matching ID
-				continue
+		// send API call to capture channel for watching the pod
+		//
+		// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#PodInterface
+		w, err := c.Kubernetes.CoreV1().Pods(c.config.Namespace).Watch(ctx, opts)
+		if err != nil {
+			return err
+		}
+
+		opened := time.Now()
+
+		found, err = watchEvents(ctx, w, ctn, pulls, done)
+
+		w.Stop()
+
+		if err != nil || found {
+			return err
+		}
+
+		// check if the watch stayed open
+		if time.Since(opened) >= minWatchDuration {
+			retries = 0
+		} else {
+			retries++
+
+			// check if we have exhausted the attempts to resume the watch
+			if retries > maxWatchRetries {
+				return fmt.Errorf("unable to watch pod %s for container %s: watch closed %d times", name, ctn.ID, retries)
 			}
 
-			// check if the container is in a terminated state
-			//
-			// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#ContainerState
-			if cst.State.Terminated == nil {
-				// skip container if it's not in a terminated state
-				break
+			// wait before resuming the watch with backoff
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(watchRetryDelay * time.Duration(1<<(retries-1))):
+			}
+		}
+
+		logrus.Tracef("resuming watch on pod %s for container %s", name, ctn.ID)
+	}
+}
+
+// watchEvents is a helper function to capture the events from
+// the watch until the provided function returns true for the
+// status of the pipeline container. It returns false when the
+// watch is closed or expires before that happens.
+//
+// nolint: lll // ignore long line length due to variable names
+func watchEvents(ctx context.Context, w watch.Interface, ctn *pipeline.Container, pulls *imagePulls, done func(*v1.ContainerStatus) bool) (bool, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case event, ok := <-w.ResultChan():
+			// check if the watch was closed by the API server
+			if !ok {
+				return false, nil
+			}
+
+			switch event.Type {
+			case watch.Deleted:
+				return false, fmt.Errorf("pod for container %s was deleted", ctn.ID)
+			case watch.Error:
+				// the resource version has likely expired
+				return false, nil
+			}
+
+			// convert the object from the event to a pod
+			pod, ok := event.Object.(*v1.Pod)
+			if !ok {
+				continue
 			}
 
-			// check if the container has a terminated state reason
-			//
-			// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#ContainerStateTerminated
-			if len(cst.State.Terminated.Reason) > 0 {
-				// break watching the container as it's complete
-				return nil
+			// check if the container is in the expected state
+			found, err := checkContainer(pod, ctn, pulls, done)
+			if err != nil || found {
+				return found, err
 			}
 		}
 	}
 }
+
+// checkContainer is a helper function to check if the
+// provided function returns true for the status of the
+// pipeline container, or if the pod has failed.
+func checkContainer(pod *v1.Pod, ctn *pipeline.Container, pulls *imagePulls, done func(*v1.ContainerStatus) bool) (bool, error) {
+	// check if the container is in the expected state
+	// for the image of the pipeline container
+	if cst := containerStatus(pod, ctn); cst != nil && statusImage(pod, cst) && done(cst) {
+		return true, nil
+	}
+
+	return false, podFailure(pod, ctn, pulls)
+}
+
+// containerStatus is a helper function to capture
+// the status for the pipeline container in the pod.
+func containerStatus(pod *v1.Pod, ctn *pipeline.Container) *v1.ContainerStatus {
+	// iterate through each container in the pod
+	for i, cst := range pod.Status.ContainerStatuses {
+		// check if the container has a matching ID
+		//
+		// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#ContainerStatus
+		if strings.EqualFold(cst.Name, ctn.ID) {
+			return &pod.Status.ContainerStatuses[i]
+		}
+	}
+
+	return nil
+}
+
+// statusImage is a helper function to check if the status
+// for the pipeline container is for the image in the pod
+// spec, and not for the placeholder image the container
+// runs until the image is patched for the step.
+func statusImage(pod *v1.Pod, cst *v1.ContainerStatus) bool {
+	// iterate through each container in the pod spec
+	for _, container := range pod.Spec.Containers {
+		if !strings.EqualFold(container.Name, cst.Name) {
+			continue
+		}
+
+		// check if the status is for the image
+		//
+		// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/image#Parse
+		if strings.EqualFold(image.Parse(cst.Image), image.Parse(container.Image)) {
+			return true
+		}
+
+		// check if the image is pinned to the digest for the status
+		//
+		// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#ContainerStatus
+		if i := strings.Index(container.Image, "@"); i > 0 {
+			return strings.HasSuffix(cst.ImageID, container.Image[i:])
+		}
+
+		return false
+	}
+
+	// the pod spec doesn't contain the container, so
+	// the status can't be checked against the image
+	return true
+}
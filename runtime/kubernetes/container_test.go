@@ -6,13 +6,21 @@ package kubernetes
 
 import (
 	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 
+	"github.com/go-vela/pkg-runtime/internal/image"
+	"github.com/go-vela/pkg-runtime/internal/logs"
+	"github.com/go-vela/pkg-runtime/internal/signature"
 	"github.com/go-vela/types/pipeline"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes/fake"
 	testcore "k8s.io/client-go/testing"
@@ -35,7 +43,7 @@ func TestKubernetes_InspectContainer(t *testing.T) {
 			container: _container,
 		},
 		{
-			failure:   false,
+			failure:   true,
 			container: new(pipeline.Container),
 		},
 	}
@@ -145,9 +153,37 @@ func TestKubernetes_RunContainer(t *testing.T) {
 	}
 }
 
+func TestKubernetes_RunContainer_Mirror(t *testing.T) {
+	// setup types
+	_engine, err := NewMock(_pod.DeepCopy(),
+		WithRegistryMirrors([]string{"docker.io=mirror.company.com:5000"}),
+	)
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	want := "mirror.company.com:5000/target/vela-git:v0.4.0"
+
+	// run test
+	err = _engine.RunContainer(context.Background(), _container, _steps)
+	if err != nil {
+		t.Errorf("RunContainer returned err: %v", err)
+	}
+
+	pod, err := _engine.Kubernetes.CoreV1().Pods("test").Get(context.Background(), _pod.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Errorf("unable to get pod: %v", err)
+	}
+
+	got := pod.Spec.Containers[0].Image
+	if got != want {
+		t.Errorf("RunContainer image is %s, want %s", got, want)
+	}
+}
+
 func TestKubernetes_SetupContainer(t *testing.T) {
 	// setup types
-	_engine, err := NewMock(_pod)
+	_engine, err := NewMock(_pod.DeepCopy())
 	if err != nil {
 		t.Errorf("unable to create runtime engine: %v", err)
 	}
@@ -191,6 +227,18 @@ func TestKubernetes_SetupContainer(t *testing.T) {
 		},
 	}
 
+	// record the build for each container
+	_build := &pipeline.Build{ID: _pod.ObjectMeta.Name, Version: "1"}
+
+	for _, test := range tests {
+		_build.Steps = append(_build.Steps, test.container)
+	}
+
+	err = _engine.SetupBuild(context.Background(), _build)
+	if err != nil {
+		t.Errorf("SetupBuild returned err: %v", err)
+	}
+
 	// run tests
 	for _, test := range tests {
 		err = _engine.SetupContainer(context.Background(), test.container)
@@ -211,6 +259,201 @@ func TestKubernetes_SetupContainer(t *testing.T) {
 	}
 }
 
+func TestKubernetes_SetupContainer_User(t *testing.T) {
+	// setup types
+	id := func(value int64) *int64 { return &value }
+
+	// setup tests
+	tests := []struct {
+		name    string
+		failure bool
+		user    string
+		ulimits pipeline.UlimitSlice
+		want    *v1.SecurityContext
+	}{
+		{
+			name:    "no user",
+			failure: false,
+			want:    nil,
+		},
+		{
+			name:    "user",
+			failure: false,
+			user:    "1000",
+			want:    &v1.SecurityContext{RunAsUser: id(1000)},
+		},
+		{
+			name:    "user and group",
+			failure: false,
+			user:    "1000:2000",
+			want:    &v1.SecurityContext{RunAsUser: id(1000), RunAsGroup: id(2000)},
+		},
+		{
+			name:    "root",
+			failure: false,
+			user:    "root:root",
+			want:    &v1.SecurityContext{RunAsUser: id(0), RunAsGroup: id(0)},
+		},
+		{
+			name:    "user name",
+			failure: true,
+			user:    "octocat",
+		},
+		{
+			name:    "group name",
+			failure: true,
+			user:    "1000:octocat",
+		},
+		{
+			name:    "negative user",
+			failure: true,
+			user:    "-1",
+		},
+		{
+			name:    "ulimits",
+			failure: true,
+			ulimits: pipeline.UlimitSlice{{Name: "nofile", Soft: 1024, Hard: 2048}},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := NewMock(&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "github-octocat-1", Namespace: "test"},
+		})
+		if err != nil {
+			t.Errorf("unable to create runtime engine: %v", err)
+		}
+
+		_user := *_container
+		_user.User = test.user
+		_user.Ulimits = test.ulimits
+
+		err = _engine.SetupBuild(context.Background(), _steps)
+		if err != nil {
+			t.Errorf("SetupBuild for %s returned err: %v", test.name, err)
+		}
+
+		err = _engine.SetupContainer(context.Background(), &_user)
+
+		if test.failure {
+			var capErr *CapabilityError
+			if !errors.As(err, &capErr) {
+				t.Errorf("SetupContainer for %s returned err %v, want CapabilityError", test.name, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("SetupContainer for %s returned err: %v", test.name, err)
+		}
+
+		got := _engine.builds["github-octocat-1"].Pod.Spec.Containers[0].SecurityContext
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("SetupContainer for %s security context is %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestKubernetes_PodContainer(t *testing.T) {
+	// setup types
+	_engine, err := NewMock(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "github-octocat-1", Namespace: "test"},
+	})
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	// setup the containers out of order with a service and secret origin
+	containers := []*pipeline.Container{
+		{
+			ID:          "step-github-octocat-1-echo",
+			Environment: map[string]string{"STEP": "echo"},
+			Image:       "alpine:latest",
+			Number:      3,
+		},
+		{
+			ID:          "service-github-octocat-1-postgres",
+			Environment: map[string]string{"STEP": "postgres"},
+			Image:       "postgres:12-alpine",
+			Number:      1,
+		},
+		{
+			ID:          "secret-github-octocat-1-vault",
+			Environment: map[string]string{"STEP": "vault"},
+			Image:       "target/secret-vault:latest",
+			Number:      1,
+		},
+		_container,
+	}
+
+	err = _engine.SetupBuild(context.Background(), &pipeline.Build{
+		ID:      "github-octocat-1",
+		Version: "1",
+		Steps:   containers,
+	})
+	if err != nil {
+		t.Errorf("SetupBuild returned err: %v", err)
+	}
+
+	for _, ctn := range containers {
+		err = _engine.SetupContainer(context.Background(), ctn)
+		if err != nil {
+			t.Errorf("SetupContainer for %s returned err: %v", ctn.ID, err)
+		}
+	}
+
+	_state := _engine.builds["github-octocat-1"]
+
+	// reorder the containers in the pod spec to invalidate the index
+	_state.Pod.Spec.Containers[0], _state.Pod.Spec.Containers[3] =
+		_state.Pod.Spec.Containers[3], _state.Pod.Spec.Containers[0]
+
+	// run tests
+	for _, ctn := range containers {
+		err = _engine.setupContainerEnvironment(_state, ctn)
+		if err != nil {
+			t.Errorf("setupContainerEnvironment for %s returned err: %v", ctn.ID, err)
+		}
+
+		container, err := _state.podContainer(ctn)
+		if err != nil {
+			t.Errorf("podContainer for %s returned err: %v", ctn.ID, err)
+
+			continue
+		}
+
+		if container.Name != ctn.ID {
+			t.Errorf("podContainer for %s is %s", ctn.ID, container.Name)
+		}
+
+		for _, env := range container.Env {
+			if env.Name == "STEP" && env.Value != ctn.Environment["STEP"] {
+				t.Errorf("setupContainerEnvironment for %s set STEP to %s", ctn.ID, env.Value)
+			}
+		}
+	}
+
+	_unknown := &pipeline.Container{ID: "step-github-octocat-1-unknown", Image: "alpine:latest"}
+
+	err = _engine.setupContainerEnvironment(_state, _unknown)
+	if err == nil {
+		t.Errorf("setupContainerEnvironment for %s should have returned err", _unknown.ID)
+	}
+
+	_, err = _engine.InspectImage(context.Background(), _unknown)
+	if err == nil {
+		t.Errorf("InspectImage for %s should have returned err", _unknown.ID)
+	}
+
+	err = _engine.RunContainer(context.Background(), _unknown, _steps)
+	if err == nil {
+		t.Errorf("RunContainer for %s should have returned err", _unknown.ID)
+	}
+}
+
 // TODO: implement this once they resolve the bug
 //
 // https://github.com/kubernetes/kubernetes/issues/84203
@@ -224,113 +467,608 @@ func TestKubernetes_TailContainer(t *testing.T) {
 	// fixed in k8s.io/client-go v0.19.0; we already have v0.22.2
 }
 
-func TestKubernetes_WaitContainer(t *testing.T) {
+func TestKubernetes_TailRecords(t *testing.T) {
 	// setup types
 	_engine, err := NewMock(_pod)
 	if err != nil {
 		t.Errorf("unable to create runtime engine: %v", err)
 	}
 
-	// create a new fake kubernetes client
-	//
-	// https://pkg.go.dev/k8s.io/client-go/kubernetes/fake?tab=doc#NewSimpleClientset
-	_kubernetes := fake.NewSimpleClientset(_pod)
+	// run test
+	r, err := _engine.TailRecords(context.Background(), _container)
+	if err != nil {
+		t.Errorf("TailRecords returned err: %v", err)
+	}
 
-	// create a new fake watcher
-	//
-	// https://pkg.go.dev/k8s.io/apimachinery/pkg/watch?tab=doc#NewFake
-	_watch := watch.NewFake()
+	defer r.Close()
 
-	// create a new watch reactor with the fake watcher
+	// the fake clientset always returns "fake logs" without timestamps
 	//
-	// https://pkg.go.dev/k8s.io/client-go/testing?tab=doc#DefaultWatchReactor
-	reactor := testcore.DefaultWatchReactor(_watch, nil)
+	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1/fake#FakePods.GetLogs
+	got, err := r.Next()
+	if err != nil {
+		t.Errorf("Next returned err: %v", err)
+	}
 
-	// add watch reactor to beginning of the client chain
-	//
-	// https://pkg.go.dev/k8s.io/client-go/testing?tab=doc#Fake.PrependWatchReactor
-	_kubernetes.PrependWatchReactor("pods", reactor)
+	want := &logs.Record{Stream: logs.StreamStdout, Line: "fake logs"}
 
-	// overwrite the mock kubernetes client
-	_engine.Kubernetes = _kubernetes
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Next is %+v, want %+v", got, want)
+	}
+
+	_, err = r.Next()
+	if err != io.EOF {
+		t.Errorf("Next returned err %v, want %v", err, io.EOF)
+	}
+}
 
+func TestKubernetes_TailContainer_LogLimit(t *testing.T) {
 	// setup tests
 	tests := []struct {
-		failure   bool
-		container *pipeline.Container
-		object    runtime.Object
+		name    string
+		kill    bool
+		want    string
+		deleted bool
 	}{
 		{
-			failure:   false,
-			container: _container,
-			object:    _pod,
+			name:    "truncate",
+			kill:    false,
+			want:    "fake\n--- log limit of 4 bytes exceeded: output truncated ---\n",
+			deleted: false,
 		},
 		{
-			failure:   false,
-			container: _container,
-			object: &v1.Pod{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "github-octocat-1",
-					Namespace: "test",
-					Labels: map[string]string{
-						"pipeline": "github-octocat-1",
-					},
+			name:    "kill",
+			kill:    true,
+			want:    "fake\n--- log limit of 4 bytes exceeded: output truncated ---\n",
+			deleted: true,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := NewMock(_pod.DeepCopy(), WithLogLimits("4", 0), WithLogLimitKill(test.kill))
+		if err != nil {
+			t.Errorf("unable to create runtime engine: %v", err)
+		}
+
+		// the fake clientset always returns "fake logs"
+		rc, err := _engine.TailContainer(context.Background(), _container)
+		if err != nil {
+			t.Errorf("TailContainer for %s returned err: %v", test.name, err)
+		}
+
+		got, err := ioutil.ReadAll(rc)
+
+		var limitErr *logs.LimitError
+		if !errors.As(err, &limitErr) {
+			t.Errorf("TailContainer for %s returned err %v, want LimitError", test.name, err)
+		}
+
+		if string(got) != test.want {
+			t.Errorf("TailContainer for %s is %q, want %q", test.name, got, test.want)
+		}
+
+		_, err = _engine.Kubernetes.CoreV1().Pods(_pod.ObjectMeta.Namespace).
+			Get(context.Background(), _pod.ObjectMeta.Name, metav1.GetOptions{})
+
+		if test.deleted != (err != nil) {
+			t.Errorf("TailContainer for %s deleted pod is %v, want %v", test.name, err != nil, test.deleted)
+		}
+
+		// the pod for the build may already be deleted
+		_engine.builds[_pod.ObjectMeta.Name].createdPod = !test.deleted
+
+		err = _engine.RemoveBuild(context.Background(), _stages)
+		if err != nil {
+			t.Errorf("RemoveBuild for %s returned err: %v", test.name, err)
+		}
+	}
+}
+
+func TestKubernetes_WaitContainer(t *testing.T) {
+	// setup types
+	_running := podWithState(v1.ContainerState{Running: &v1.ContainerStateRunning{}})
+	_running.ObjectMeta.ResourceVersion = "1"
+
+	_terminated := podWithState(v1.ContainerState{
+		Terminated: &v1.ContainerStateTerminated{Reason: "Completed"},
+	})
+
+	_evicted := _running.DeepCopy()
+	_evicted.Status.Phase = v1.PodFailed
+	_evicted.Status.Reason = ReasonEvicted
+	_evicted.Status.Message = "The node was low on resource: memory."
+
+	_deadline := _running.DeepCopy()
+	_deadline.Status.Phase = v1.PodFailed
+	_deadline.Status.Reason = "DeadlineExceeded"
+	_deadline.Status.Message = "Pod was active on the node longer than the specified deadline"
+
+	_failed := _running.DeepCopy()
+	_failed.Status.Phase = v1.PodFailed
+
+	_pullError := podWithState(v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: ReasonErrImagePull}})
+	_pullError.ObjectMeta.ResourceVersion = "1"
+
+	_backOff := podWithState(v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: ReasonImagePullBackOff}})
+	_backOff.ObjectMeta.ResourceVersion = "1"
+
+	// resume the watch without waiting
+	delay := watchRetryDelay
+	watchRetryDelay = time.Millisecond
+
+	defer func() { watchRetryDelay = delay }()
+
+	// setup tests
+	tests := []struct {
+		name    string
+		failure bool
+		reason  string
+		pod     *v1.Pod
+		// events sent to each watch, a nil event closes the watch
+		events [][]watch.Event
+	}{
+		{
+			name:    "terminated",
+			failure: false,
+			pod:     _terminated,
+		},
+		{
+			name:    "watch",
+			failure: false,
+			pod:     _running,
+			events: [][]watch.Event{
+				{
+					{Type: watch.Modified, Object: _running},
+					{Type: watch.Modified, Object: _terminated},
+				},
+			},
+		},
+		{
+			name:    "ignore objects",
+			failure: false,
+			pod:     _running,
+			events: [][]watch.Event{
+				{
+					{Type: watch.Added, Object: new(v1.PodTemplate)},
+					{Type: watch.Modified, Object: _terminated},
+				},
+			},
+		},
+		{
+			name:    "resume after close",
+			failure: false,
+			pod:     _running,
+			events: [][]watch.Event{
+				nil,
+				{
+					{Type: watch.Modified, Object: _terminated},
+				},
+			},
+		},
+		{
+			name:    "resume after error",
+			failure: false,
+			pod:     _running,
+			events: [][]watch.Event{
+				{
+					{Type: watch.Error, Object: &metav1.Status{Reason: metav1.StatusReasonExpired}},
 				},
-				TypeMeta: metav1.TypeMeta{
-					APIVersion: "v1",
-					Kind:       "Pod",
+				{
+					{Type: watch.Modified, Object: _terminated},
 				},
-				Status: v1.PodStatus{
-					Phase: v1.PodRunning,
-					ContainerStatuses: []v1.ContainerStatus{
-						{
-							Name: "step-github-octocat-1-echo",
-							State: v1.ContainerState{
-								Terminated: &v1.ContainerStateTerminated{
-									Reason:   "Completed",
-									ExitCode: 0,
-								},
-							},
-						},
-						{
-							Name: "step-github-octocat-1-clone",
-							State: v1.ContainerState{
-								Terminated: &v1.ContainerStateTerminated{
-									Reason:   "Completed",
-									ExitCode: 0,
-								},
-							},
-						},
-					},
+			},
+		},
+		{
+			name:    "deleted",
+			failure: true,
+			pod:     _running,
+			events: [][]watch.Event{
+				{
+					{Type: watch.Deleted, Object: _running},
 				},
 			},
 		},
 		{
-			failure:   true,
-			container: _container,
-			object:    new(v1.PodTemplate),
+			name:    "evicted",
+			failure: true,
+			reason:  ReasonEvicted,
+			pod:     _running,
+			events: [][]watch.Event{
+				{
+					{Type: watch.Modified, Object: _evicted},
+				},
+			},
+		},
+		{
+			name:    "deadline exceeded",
+			failure: true,
+			reason:  "DeadlineExceeded",
+			pod:     _running,
+			events: [][]watch.Event{
+				{
+					{Type: watch.Modified, Object: _deadline},
+				},
+			},
+		},
+		{
+			name:    "failed without reason",
+			failure: true,
+			reason:  string(v1.PodFailed),
+			pod:     _running,
+			events: [][]watch.Event{
+				{
+					{Type: watch.Modified, Object: _failed},
+				},
+			},
+		},
+		{
+			name:    "image pull backoff",
+			failure: true,
+			reason:  ReasonImagePullBackOff,
+			pod:     _backOff,
+			events: [][]watch.Event{
+				{
+					{Type: watch.Modified, Object: _pullError},
+					{Type: watch.Modified, Object: _backOff},
+					{Type: watch.Modified, Object: _pullError},
+					{Type: watch.Modified, Object: _backOff},
+				},
+			},
+		},
+		{
+			name:    "image pull backoff after resuming watch",
+			failure: true,
+			reason:  ReasonImagePullBackOff,
+			pod:     _backOff,
+			events: [][]watch.Event{
+				{
+					{Type: watch.Modified, Object: _backOff},
+					{Type: watch.Error, Object: &metav1.Status{Reason: metav1.StatusReasonExpired}},
+				},
+				{
+					{Type: watch.Modified, Object: _pullError},
+					{Type: watch.Modified, Object: _backOff},
+					{Type: watch.Modified, Object: _pullError},
+					{Type: watch.Modified, Object: _backOff},
+				},
+			},
+		},
+		{
+			name:    "image pull recovered",
+			failure: false,
+			pod:     _running,
+			events: [][]watch.Event{
+				{
+					{Type: watch.Modified, Object: _pullError},
+					{Type: watch.Modified, Object: _backOff},
+					{Type: watch.Modified, Object: _pullError},
+					{Type: watch.Modified, Object: _running},
+					{Type: watch.Modified, Object: _terminated},
+				},
+			},
+		},
+		{
+			name:    "invalid image name",
+			failure: true,
+			reason:  ReasonInvalidImageName,
+			pod:     podWithState(v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: ReasonInvalidImageName}}),
+		},
+		{
+			name:    "watch keeps closing",
+			failure: true,
+			pod:     _running,
+			events:  make([][]watch.Event, maxWatchRetries+1),
 		},
 	}
 
 	// run tests
 	for _, test := range tests {
-		go func() {
-			// simulate adding a pod to the watcher
-			_watch.Add(test.object)
-		}()
+		_engine, err := NewMock(test.pod)
+		if err != nil {
+			t.Errorf("unable to create runtime engine: %v", err)
+		}
+
+		// create a new fake kubernetes client
+		//
+		// https://pkg.go.dev/k8s.io/client-go/kubernetes/fake?tab=doc#NewSimpleClientset
+		_kubernetes := fake.NewSimpleClientset(test.pod)
+
+		watches := 0
+
+		// add watch reactor to beginning of the client chain
+		//
+		// https://pkg.go.dev/k8s.io/client-go/testing?tab=doc#Fake.PrependWatchReactor
+		_kubernetes.PrependWatchReactor("pods", func(action testcore.Action) (bool, watch.Interface, error) {
+			version := action.(testcore.WatchAction).GetWatchRestrictions().ResourceVersion
+			if version != test.pod.ObjectMeta.ResourceVersion {
+				t.Errorf("WaitContainer for %s watched from version %q, want %q", test.name, version, test.pod.ObjectMeta.ResourceVersion)
+			}
+
+			// create a new fake watcher with all events for the watch
+			//
+			// https://pkg.go.dev/k8s.io/apimachinery/pkg/watch?tab=doc#NewFakeWithChanSize
+			_watch := watch.NewFakeWithChanSize(len(test.events[watches]), false)
+
+			for _, event := range test.events[watches] {
+				_watch.Action(event.Type, event.Object)
+			}
+
+			// simulate the API server closing the watch
+			if test.events[watches] == nil {
+				_watch.Stop()
+			}
 
-		err := _engine.WaitContainer(context.Background(), test.container)
+			watches++
+
+			return true, _watch, nil
+		})
+
+		// overwrite the mock kubernetes client
+		_engine.Kubernetes = _kubernetes
+
+		err = _engine.WaitContainer(context.Background(), _container)
+
+		if watches != len(test.events) {
+			t.Errorf("WaitContainer for %s watched %d times, want %d", test.name, watches, len(test.events))
+		}
 
 		if test.failure {
 			if err == nil {
-				t.Errorf("WaitContainer should have returned err")
+				t.Errorf("WaitContainer for %s should have returned err", test.name)
+			}
+
+			var failureErr *PodFailureError
+
+			if len(test.reason) > 0 && (!errors.As(err, &failureErr) || failureErr.Reason != test.reason) {
+				t.Errorf("WaitContainer for %s returned err %v, want %s", test.name, err, test.reason)
 			}
 
 			continue
 		}
 
 		if err != nil {
-			t.Errorf("WaitContainer returned err: %v", err)
+			t.Errorf("WaitContainer for %s returned err: %v", test.name, err)
+		}
+	}
+}
+
+func TestKubernetes_WaitContainer_Cancel(t *testing.T) {
+	// setup types
+	_running := podWithState(v1.ContainerState{Running: &v1.ContainerStateRunning{}})
+
+	_engine, err := NewMock(_running)
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// run test
+	err = _engine.WaitContainer(ctx, _container)
+	if err != context.DeadlineExceeded {
+		t.Errorf("WaitContainer returned err %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestKubernetes_PodFailureError(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		err  *PodFailureError
+		want string
+	}{
+		{
+			err:  &PodFailureError{Pod: "github-octocat-1", Container: "step-github-octocat-1-clone", Reason: ReasonErrImagePull},
+			want: "pod github-octocat-1 failed for container step-github-octocat-1-clone: ErrImagePull",
+		},
+		{
+			err: &PodFailureError{
+				Pod:       "github-octocat-1",
+				Container: "step-github-octocat-1-clone",
+				Reason:    ReasonEvicted,
+				Message:   "The node was low on resource: memory.",
+			},
+			want: "pod github-octocat-1 failed for container step-github-octocat-1-clone: Evicted: The node was low on resource: memory.",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		if got := test.err.Error(); got != test.want {
+			t.Errorf("Error is %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestKubernetes_ImagePolicy(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		image   string
+		rule    string
+	}{
+		{
+			failure: false,
+			image:   "target/vela-git:v0.4.0",
+		},
+		{
+			failure: true,
+			image:   "target/vela-docker:latest",
+			rule:    image.RuleDeniedImage,
+		},
+		{
+			failure: true,
+			image:   "gcr.io/target/vela-git:v0.4.0",
+			rule:    image.RuleDeniedRegistry,
+		},
+		{
+			failure: true,
+			image:   "alpine:latest",
+			rule:    image.RuleNotAllowed,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := NewMock(_pod.DeepCopy(),
+			WithImagePolicy([]string{"target/vela-*"}, []string{"target/vela-docker"}),
+			WithRegistryPolicy(nil, []string{"gcr.io"}),
+		)
+		if err != nil {
+			t.Errorf("unable to create runtime engine: %v", err)
+		}
+
+		ctn := &pipeline.Container{
+			ID:          "step-github-octocat-1-clone",
+			Directory:   "/vela/src/github.com/octocat/helloworld",
+			Environment: map[string]string{"FOO": "bar"},
+			Image:       test.image,
+			Name:        "clone",
+			Number:      2,
+			Pull:        "always",
+		}
+
+		errs := map[string]error{
+			"SetupContainer": _engine.SetupContainer(context.Background(), ctn),
+			"RunContainer":   _engine.RunContainer(context.Background(), ctn, _steps),
+		}
+
+		for name, err := range errs {
+			if test.failure {
+				var policyErr *image.PolicyError
+				if !errors.As(err, &policyErr) {
+					t.Errorf("%s returned err %v, want PolicyError", name, err)
+
+					continue
+				}
+
+				if policyErr.Rule != test.rule {
+					t.Errorf("%s rule is %s, want %s", name, policyErr.Rule, test.rule)
+				}
+
+				continue
+			}
+
+			if err != nil {
+				t.Errorf("%s returned err: %v", name, err)
+			}
+		}
+	}
+}
+
+func TestKubernetes_ImageVerification(t *testing.T) {
+	// setup types
+	_registry, err := signature.NewMockRegistry()
+	if err != nil {
+		t.Errorf("unable to create mock registry: %v", err)
+	}
+
+	defer _registry.Close()
+
+	key, err := _registry.PublicKey()
+	if err != nil {
+		t.Errorf("unable to encode public key: %v", err)
+	}
+
+	file := filepath.Join(t.TempDir(), "cosign.pub")
+
+	err = ioutil.WriteFile(file, key, 0600)
+	if err != nil {
+		t.Errorf("unable to write public key: %v", err)
+	}
+
+	digest := _registry.Push("vela/signed", "v1")
+
+	err = _registry.Sign("vela/signed", digest, _registry.Key())
+	if err != nil {
+		t.Errorf("unable to sign image: %v", err)
+	}
+
+	_ = _registry.Push("vela/unsigned", "v1")
+
+	// setup tests
+	tests := []struct {
+		failure bool
+		image   string
+		reason  string
+		want    string
+	}{
+		{
+			failure: false,
+			image:   _registry.Host() + "/vela/signed:v1",
+			want:    _registry.Host() + "/vela/signed:v1@" + digest,
+		},
+		{
+			failure: false,
+			image:   "alpine:latest",
+			want:    "docker.io/library/alpine:latest",
+		},
+		{
+			failure: true,
+			image:   _registry.Host() + "/vela/unsigned:v1",
+			reason:  signature.ReasonUnsigned,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := NewMock(_pod.DeepCopy(),
+			WithImageVerification([]string{file}, []string{_registry.Host() + "/vela/*"}),
+		)
+		if err != nil {
+			t.Errorf("unable to create runtime engine: %v", err)
+		}
+
+		_engine.config.Verifier.Client = _registry.Client()
+
+		ctn := &pipeline.Container{
+			ID:          "step-github-octocat-1-clone",
+			Directory:   "/vela/src/github.com/octocat/helloworld",
+			Environment: map[string]string{"FOO": "bar"},
+			Image:       test.image,
+			Name:        "clone",
+			Number:      2,
+			Pull:        "always",
+		}
+
+		errs := map[string]error{
+			"SetupContainer": _engine.SetupContainer(context.Background(), ctn),
+			"RunContainer":   _engine.RunContainer(context.Background(), ctn, _steps),
+		}
+
+		for name, err := range errs {
+			if test.failure {
+				var sigErr *signature.Error
+				if !errors.As(err, &sigErr) {
+					t.Errorf("%s returned err %v, want signature Error", name, err)
+
+					continue
+				}
+
+				if sigErr.Reason != test.reason {
+					t.Errorf("%s reason is %s, want %s", name, sigErr.Reason, test.reason)
+				}
+
+				continue
+			}
+
+			if err != nil {
+				t.Errorf("%s returned err: %v", name, err)
+			}
+		}
+
+		if test.failure {
+			continue
+		}
+
+		pod, err := _engine.Kubernetes.CoreV1().Pods("test").Get(context.Background(), _pod.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Errorf("unable to get pod: %v", err)
+		}
+
+		if pod.Spec.Containers[0].Image != test.want {
+			t.Errorf("RunContainer image is %s, want %s", pod.Spec.Containers[0].Image, test.want)
 		}
 	}
 }
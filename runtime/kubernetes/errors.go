@@ -0,0 +1,148 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package kubernetes
+
+import (
+	"fmt"
+
+	"github.com/go-vela/types/pipeline"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ReasonEvicted represents the reason for a pod
+	// that was evicted from the node running it.
+	ReasonEvicted = "Evicted"
+	// ReasonImagePullBackOff represents the reason for a container
+	// waiting to retry pulling an image after failing to pull it.
+	ReasonImagePullBackOff = "ImagePullBackOff"
+	// ReasonErrImagePull represents the reason for
+	// a container that failed to pull an image.
+	ReasonErrImagePull = "ErrImagePull"
+	// ReasonInvalidImageName represents the reason for a
+	// container with an image name that can't be parsed.
+	ReasonInvalidImageName = "InvalidImageName"
+
+	// maxImagePullBackOffs represents the number of times a
+	// container can back off pulling the image before the
+	// failure to pull the image is unable to recover.
+	maxImagePullBackOffs = 3
+)
+
+// CapabilityError represents the error returned when a
+// pipeline container requires a setting the Kubernetes
+// runtime is unable to apply to the container.
+type CapabilityError struct {
+	// ID of the pipeline container requiring the setting
+	Container string
+	// setting for the container that can't be applied (user, ulimits, etc.)
+	Setting string
+	// reason the setting can't be applied
+	Reason string
+}
+
+// Error implements the error interface for the CapabilityError.
+func (e *CapabilityError) Error() string {
+	return fmt.Sprintf("unable to apply %s for container %s: %s", e.Setting, e.Container, e.Reason)
+}
+
+// PodFailureError represents the error returned when the
+// pod for a pipeline container fails in a way the container
+// is unable to recover from, like being evicted or unable
+// to pull the image for the container.
+type PodFailureError struct {
+	// name of the pod that failed
+	Pod string
+	// ID of the pipeline container in the pod
+	Container string
+	// reason for the failure (Evicted, ImagePullBackOff, etc.)
+	Reason string
+	// message from Kubernetes explaining the failure
+	Message string
+}
+
+// Error implements the error interface for the PodFailureError.
+func (e *PodFailureError) Error() string {
+	// check if a message was captured for the failure
+	if len(e.Message) == 0 {
+		return fmt.Sprintf("pod %s failed for container %s: %s", e.Pod, e.Container, e.Reason)
+	}
+
+	return fmt.Sprintf("pod %s failed for container %s: %s: %s", e.Pod, e.Container, e.Reason, e.Message)
+}
+
+// imagePulls represents the attempts to pull the
+// image for a pipeline container while watching it.
+type imagePulls struct {
+	// number of times the container backed off pulling the image
+	backOffs int
+	// reason the container was last waiting on
+	reason string
+}
+
+// podFailure is a helper function to capture the failure
+// for the pod that the pipeline container is unable to
+// recover from. It returns nil if the pod has not failed.
+//
+// A failure to pull the image is retried by the kubelet,
+// so it is only unable to recover once the container has
+// backed off pulling the image too many times.
+func podFailure(pod *v1.Pod, ctn *pipeline.Container, pulls *imagePulls) error {
+	// check if the pod has failed (evicted, deadline exceeded, etc.)
+	//
+	// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#PodStatus
+	if pod.Status.Phase == v1.PodFailed {
+		reason := pod.Status.Reason
+
+		// check if a reason was captured for the failure
+		if len(reason) == 0 {
+			reason = string(v1.PodFailed)
+		}
+
+		return &PodFailureError{
+			Pod:       pod.ObjectMeta.Name,
+			Container: ctn.ID,
+			Reason:    reason,
+			Message:   pod.Status.Message,
+		}
+	}
+
+	cst := containerStatus(pod, ctn)
+
+	// check if the container is in a waiting state
+	//
+	// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#ContainerState
+	if cst == nil || cst.State.Waiting == nil {
+		pulls.reason = ""
+
+		return nil
+	}
+
+	reason := cst.State.Waiting.Reason
+
+	// check if the container started backing off pulling the image
+	if reason == ReasonImagePullBackOff && pulls.reason != ReasonImagePullBackOff {
+		pulls.backOffs++
+	}
+
+	pulls.reason = reason
+
+	// check if the container is waiting on a failure
+	//
+	// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#ContainerStateWaiting
+	switch {
+	case reason == ReasonInvalidImageName,
+		reason == ReasonImagePullBackOff && pulls.backOffs >= maxImagePullBackOffs:
+		return &PodFailureError{
+			Pod:       pod.ObjectMeta.Name,
+			Container: ctn.ID,
+			Reason:    reason,
+			Message:   cst.State.Waiting.Message,
+		}
+	}
+
+	return nil
+}
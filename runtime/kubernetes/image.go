@@ -40,12 +40,20 @@ func (c *client) CreateImage(ctx context.Context, ctn *pipeline.Container) error
 func (c *client) InspectImage(ctx context.Context, ctn *pipeline.Container) ([]byte, error) {
 	logrus.Tracef("inspecting image for container %s", ctn.ID)
 
+	s, err := c.containerState(ctn)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	// TODO: consider updating this command
 	//
 	// create output for inspecting image
 	output := []byte(
 		// nolint: lll // ignore line length due to string formatting with parameters
-		fmt.Sprintf("$ kubectl get pod -o=jsonpath='{.spec.containers[%d].image}' %s\n", ctn.Number, ctn.ID),
+		fmt.Sprintf("$ kubectl get pod -o=jsonpath='{.spec.containers[?(@.name==\"%s\")].image}' %s\n", ctn.ID, s.Pod.ObjectMeta.Name),
 	)
 
 	// check if the container pull policy is on start
@@ -55,9 +63,14 @@ func (c *client) InspectImage(ctx context.Context, ctn *pipeline.Container) ([]b
 		), nil
 	}
 
+	// capture the pod container for the step
+	container, err := s.podContainer(ctn)
+	if err != nil {
+		return output, err
+	}
+
 	// marshal the image information from the container
-	// (-1 to convert to 0-based index, -1 for init which isn't a container)
-	image, err := json.MarshalIndent(c.Pod.Spec.Containers[ctn.Number-2].Image, "", " ")
+	image, err := json.MarshalIndent(container.Image, "", " ")
 	if err != nil {
 		return output, err
 	}
@@ -5,8 +5,21 @@
 package kubernetes
 
 import (
+	"context"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-vela/pkg-runtime/internal/image"
+	"github.com/go-vela/pkg-runtime/internal/logs"
+	"github.com/go-vela/pkg-runtime/internal/network"
+	"github.com/go-vela/pkg-runtime/internal/signature"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/rest"
@@ -22,18 +35,73 @@ type config struct {
 	Images []string
 	// specifies a list of host volumes to use for the Kubernetes client
 	Volumes []string
+	// specifies the image policy to enforce for the Kubernetes client
+	Policy *image.Policy
+	// specifies a map of registries to mirrors to use for the Kubernetes client
+	Mirrors map[string]string
+	// specifies the image signature verifier to use for the Kubernetes client
+	Verifier *signature.Verifier
+	// specifies the default platform for images to use for the Kubernetes client
+	Platform *specs.Platform
+	// specifies the limits on the logs for containers to use for the Kubernetes client
+	LogLimit *logs.Limit
+	// specifies the secrets, by name, delivered to containers as files to use for the Kubernetes client
+	SecretFiles []string
+	// specifies the pod template merged into the pod for builds to use for the Kubernetes client
+	PodTemplate *podTemplate
+	// specifies the source of the workspace volume for builds to use for the Kubernetes client
+	Workspace *workspace
+	// specifies the ID of the worker labeled on pods to use for the Kubernetes client
+	WorkerID string
+	// specifies the interval for updating the heartbeat on pods to use for the Kubernetes client
+	HeartbeatInterval time.Duration
+	// specifies to restrict egress from pods for builds with a network policy to use for the Kubernetes client
+	EgressPolicy bool
+	// specifies the destinations allowed by the network policy for builds to use for the Kubernetes client
+	EgressRules []*network.EgressRule
+}
+
+const (
+	// WorkspaceEmptyDir represents the workspace volume
+	// stored in a directory on the disk for the node.
+	WorkspaceEmptyDir = "emptyDir"
+	// WorkspaceMemory represents the workspace volume
+	// stored in a tmpfs in the memory for the node.
+	WorkspaceMemory = "memory"
+	// WorkspaceClaim represents the workspace volume stored in
+	// a persistent volume claim created for each build.
+	WorkspaceClaim = "pvc"
+	// WorkspaceEphemeral represents the workspace volume stored
+	// in a generic ephemeral volume created with the pod.
+	WorkspaceEphemeral = "ephemeral"
+)
+
+// workspace represents the configuration
+// for the workspace volume for builds.
+type workspace struct {
+	// type of volume for the workspace (emptyDir, memory, pvc or ephemeral)
+	Type string
+	// storage class for the claim for the workspace (only used by pvc and ephemeral)
+	StorageClass string
+	// size for the workspace (the size limit for memory or the request for pvc and ephemeral)
+	Size *resource.Quantity
 }
 
 type client struct {
 	config *config
 	// https://pkg.go.dev/k8s.io/client-go/kubernetes#Interface
 	Kubernetes kubernetes.Interface
-	// https://pkg.go.dev/k8s.io/api/core/v1#Pod
-	Pod *v1.Pod
-	// commonVolumeMounts includes workspace mount and any global host mounts (VELA_RUNTIME_VOLUMES)
-	commonVolumeMounts []v1.VolumeMount
-	// indicates when the pod has been created in kubernetes
-	createdPod bool
+	// mutex guards the state for the builds running with the Kubernetes client
+	mutex sync.Mutex
+	// builds tracks the state for each build running with the Kubernetes client
+	builds map[string]*buildState
+	// containerBuilds tracks the build for each container running with the Kubernetes client
+	containerBuilds map[string]string
+	// getLogs overrides capturing the stream of container logs
+	//
+	// The fake clientset always returns the same logs, so
+	// this is only used for testing reconnecting to logs.
+	getLogs func(context.Context, *v1.PodLogOptions) (io.ReadCloser, error)
 }
 
 // New returns an Engine implementation that
@@ -46,7 +114,17 @@ func New(opts ...ClientOpt) (*client, error) {
 
 	// create new fields
 	c.config = &config{}
-	c.Pod = &v1.Pod{}
+	c.config.Policy = &image.Policy{}
+	c.config.LogLimit = &logs.Limit{}
+	c.config.Workspace = &workspace{Type: WorkspaceEmptyDir}
+	c.config.HeartbeatInterval = time.Minute
+	c.builds = make(map[string]*buildState)
+	c.containerBuilds = make(map[string]string)
+
+	// use the hostname as the default worker ID
+	//
+	// https://pkg.go.dev/os#Hostname
+	c.config.WorkerID, _ = os.Hostname()
 
 	// apply all provided configuration options
 	for _, opt := range opts {
@@ -105,13 +183,28 @@ func NewMock(_pod *v1.Pod, opts ...ClientOpt) (*client, error) {
 
 	// create new fields
 	c.config = &config{}
-	c.Pod = &v1.Pod{}
+	c.config.Policy = &image.Policy{}
+	c.config.LogLimit = &logs.Limit{}
+	c.config.Workspace = &workspace{Type: WorkspaceEmptyDir}
+	c.config.HeartbeatInterval = time.Minute
+	c.builds = make(map[string]*buildState)
+	c.containerBuilds = make(map[string]string)
+
+	// use the hostname as the default worker ID
+	//
+	// https://pkg.go.dev/os#Hostname
+	c.config.WorkerID, _ = os.Hostname()
 
 	// set the Kubernetes namespace in the runtime client
 	c.config.Namespace = "test"
 
-	// set the Kubernetes pod in the runtime client
-	c.Pod = _pod
+	// set the state for the build with the Kubernetes pod in the runtime client
+	c.builds[_pod.ObjectMeta.Name] = newBuildState(_pod)
+
+	// record the build for each container in the Kubernetes pod in the runtime client
+	for _, container := range _pod.Spec.Containers {
+		c.containerBuilds[container.Name] = _pod.ObjectMeta.Name
+	}
 
 	// apply all provided configuration options
 	for _, opt := range opts {
@@ -124,7 +217,7 @@ func NewMock(_pod *v1.Pod, opts ...ClientOpt) (*client, error) {
 	// set the Kubernetes fake client in the runtime client
 	//
 	// https://pkg.go.dev/k8s.io/client-go/kubernetes/fake?tab=doc#NewSimpleClientset
-	c.Kubernetes = fake.NewSimpleClientset(c.Pod)
+	c.Kubernetes = fake.NewSimpleClientset(_pod)
 
 	return c, nil
 }
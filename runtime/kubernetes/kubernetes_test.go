@@ -90,7 +90,8 @@ var (
 			Phase: v1.PodRunning,
 			ContainerStatuses: []v1.ContainerStatus{
 				{
-					Name: "step-github-octocat-1-clone",
+					Name:  "step-github-octocat-1-clone",
+					Image: "target/vela-git:v0.4.0",
 					State: v1.ContainerState{
 						Terminated: &v1.ContainerStateTerminated{
 							Reason:   "Completed",
@@ -99,7 +100,8 @@ var (
 					},
 				},
 				{
-					Name: "step-github-octocat-1-echo",
+					Name:  "step-github-octocat-1-echo",
+					Image: "alpine:latest",
 					State: v1.ContainerState{
 						Terminated: &v1.ContainerStateTerminated{
 							Reason:   "Completed",
@@ -0,0 +1,289 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package kubernetes
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/go-vela/pkg-runtime/internal/logs"
+	"github.com/go-vela/types/pipeline"
+
+	"github.com/sirupsen/logrus"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// maxLogRetries represents the maximum number of consecutive
+	// attempts to reconnect to the stream of container logs.
+	maxLogRetries = 5
+
+	// logRetryDelay represents the initial delay between
+	// attempts to reconnect to the stream of container logs.
+	logRetryDelay = 500 * time.Millisecond
+)
+
+// streamLogs is a helper function to capture the
+// stream of container logs for the pipeline container.
+//
+// It waits for the container to be running or terminated
+// before following the logs, and reconnects when the stream
+// ends before the container has terminated. The logs are
+// always requested with timestamps, so a reconnected stream
+// resumes after the last line without losing or duplicating
+// any lines. The timestamps are removed when not requested.
+func (c *client) streamLogs(ctx context.Context, s *buildState, ctn *pipeline.Container, timestamps bool) (io.ReadCloser, error) {
+	// wait for the container to be running or terminated
+	err := c.watchContainer(ctx, s, ctn, func(cst *v1.ContainerStatus) bool {
+		return cst.State.Running != nil || cst.State.Terminated != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var sinceTime *metav1.Time
+
+	// capture the time to resume the logs from after reattaching
+	since := s.resumeSince(ctn)
+	if !since.IsZero() {
+		sinceTime = &metav1.Time{Time: since}
+	}
+
+	// capture the name of the pod for the container
+	name := s.name()
+
+	// capture the stream of container logs
+	stream, err := c.openLogs(ctx, name, ctn, sinceTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// create in-memory pipe for capturing logs
+	rc, wc := io.Pipe()
+
+	// follow the stream of container logs
+	go c.followLogs(ctx, name, ctn, stream, wc, timestamps, since)
+
+	return rc, nil
+}
+
+// openLogs is a helper function to send the API call
+// to capture the stream of container logs, starting
+// from the provided time when one is provided.
+func (c *client) openLogs(ctx context.Context, name string, ctn *pipeline.Container, since *metav1.Time) (io.ReadCloser, error) {
+	// create options for capturing the logs from the container
+	//
+	// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#PodLogOptions
+	opts := &v1.PodLogOptions{
+		Container:  ctn.ID,
+		Follow:     true,
+		SinceTime:  since,
+		Timestamps: true,
+	}
+
+	// check if the stream is overridden
+	if c.getLogs != nil {
+		return c.getLogs(ctx, opts)
+	}
+
+	// send API call to capture stream of container logs
+	//
+	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#PodExpansion
+	// ->
+	// https://pkg.go.dev/k8s.io/client-go/rest?tab=doc#Request.Stream
+	return c.Kubernetes.CoreV1().
+		Pods(c.config.Namespace).
+		GetLogs(name, opts).
+		Stream(ctx)
+}
+
+// followLogs is a helper function to copy the stream of
+// container logs to the in-memory pipe, reconnecting
+// until the container has terminated. Lines up to the
+// time provided, when resuming after reattaching to
+// the build, are skipped. A line without a newline is
+// held back until the container has terminated, since
+// reconnecting to the stream replays the whole line.
+//
+// nolint: funlen // ignore function length due to comments
+func (c *client) followLogs(ctx context.Context, name string, ctn *pipeline.Container, stream io.ReadCloser, wc *io.PipeWriter, timestamps bool, since time.Time) {
+	var (
+		// timestamp of the last line copied from the stream
+		last time.Time
+		// number of lines copied with the last timestamp
+		seen int
+		// number of lines to skip with the last timestamp
+		skip int
+		// number of consecutive attempts to reconnect
+		retries int
+		// line without a newline from the end of the stream
+		partial string
+	)
+
+	// copyLine copies the line from the stream to the pipe
+	copyLine := func(line string) error {
+		// split the timestamp from the line
+		timestamp, content := logs.SplitTimestamp(line)
+
+		// check if the line was already copied before reconnecting
+		//
+		// SinceTime only has a precision of seconds, so the
+		// stream may replay lines up to the last timestamp.
+		if !timestamp.IsZero() {
+			switch {
+			case !since.IsZero() && !timestamp.After(since):
+				return nil
+			case timestamp.Before(last):
+				return nil
+			case timestamp.Equal(last) && skip > 0:
+				skip--
+
+				return nil
+			case timestamp.Equal(last):
+				seen++
+			default:
+				last, seen, skip = timestamp, 1, 0
+			}
+		}
+
+		// check if the timestamps were requested
+		if timestamps {
+			content = line
+		}
+
+		_, err := io.WriteString(wc, content)
+
+		return err
+	}
+
+	for {
+		reader := bufio.NewReader(stream)
+
+		partial = ""
+
+		for {
+			line, err := reader.ReadString('\n')
+
+			// check if the stream ended partway through a line
+			if len(line) > 0 && err != nil {
+				partial = line
+			}
+
+			if len(line) > 0 && err == nil {
+				werr := copyLine(line)
+				if werr != nil {
+					// the reader for the pipe was closed
+					stream.Close()
+
+					return
+				}
+
+				retries = 0
+			}
+
+			if err != nil {
+				break
+			}
+		}
+
+		stream.Close()
+
+		// check if the context was cancelled
+		if ctx.Err() != nil {
+			wc.CloseWithError(ctx.Err())
+
+			return
+		}
+
+		// check if the container has terminated
+		if c.containerTerminated(ctx, name, ctn) {
+			logrus.Tracef("finished following logs for container %s", ctn.ID)
+
+			// copy the last line without a newline
+			if len(partial) > 0 {
+				_ = copyLine(partial)
+			}
+
+			wc.Close()
+
+			return
+		}
+
+		retries++
+
+		// check if we have exhausted the attempts to reconnect
+		if retries > maxLogRetries {
+			wc.CloseWithError(fmt.Errorf("unable to reconnect to logs for container %s", ctn.ID))
+
+			return
+		}
+
+		logrus.Debugf("reconnecting to logs for container %s (attempt %d)", ctn.ID, retries)
+
+		// wait before reconnecting to the stream with backoff
+		select {
+		case <-ctx.Done():
+			wc.CloseWithError(ctx.Err())
+
+			return
+		case <-time.After(logRetryDelay * time.Duration(1<<(retries-1))):
+		}
+
+		var since *metav1.Time
+
+		// check if any lines have been copied
+		if !last.IsZero() {
+			since = &metav1.Time{Time: last}
+			skip = seen
+		}
+
+		var err error
+
+		// send API call to capture the stream of container logs
+		stream, err = c.openLogs(ctx, name, ctn, since)
+		if err != nil {
+			logrus.Errorf("unable to reconnect to logs for container %s: %v", ctn.ID, err)
+
+			// retry with an empty stream
+			stream = ioutil.NopCloser(strings.NewReader(""))
+		}
+	}
+}
+
+// containerTerminated is a helper function to check
+// if the pipeline container has terminated or the
+// pod for the container no longer exists.
+func (c *client) containerTerminated(ctx context.Context, name string, ctn *pipeline.Container) bool {
+	// send API call to capture the pod
+	//
+	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#PodInterface
+	pod, err := c.Kubernetes.CoreV1().
+		Pods(c.config.Namespace).
+		Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		// check if the pod was deleted
+		//
+		// https://pkg.go.dev/k8s.io/apimachinery/pkg/api/errors?tab=doc#IsNotFound
+		if errors.IsNotFound(err) {
+			return true
+		}
+
+		logrus.Errorf("unable to capture pod %s: %v", name, err)
+
+		return false
+	}
+
+	cst := containerStatus(pod, ctn)
+
+	return cst != nil && cst.State.Terminated != nil
+}
@@ -0,0 +1,350 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package kubernetes
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	testcore "k8s.io/client-go/testing"
+)
+
+func TestKubernetes_TailContainer_Wait(t *testing.T) {
+	// setup types
+	_waiting := podWithState(v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ContainerCreating"}})
+	_running := podWithState(v1.ContainerState{Running: &v1.ContainerStateRunning{}})
+
+	_engine, err := NewMock(_waiting)
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	// create a new fake watcher
+	//
+	// https://pkg.go.dev/k8s.io/apimachinery/pkg/watch?tab=doc#NewFakeWithChanSize
+	_watch := watch.NewFakeWithChanSize(1, false)
+
+	// create a new fake kubernetes client
+	//
+	// https://pkg.go.dev/k8s.io/client-go/kubernetes/fake?tab=doc#NewSimpleClientset
+	_kubernetes := fake.NewSimpleClientset(_waiting)
+
+	// add watch reactor to beginning of the client chain
+	//
+	// https://pkg.go.dev/k8s.io/client-go/testing?tab=doc#Fake.PrependWatchReactor
+	_kubernetes.PrependWatchReactor("pods", testcore.DefaultWatchReactor(_watch, nil))
+
+	// overwrite the mock kubernetes client
+	_engine.Kubernetes = _kubernetes
+
+	started := make(chan struct{})
+
+	_engine.getLogs = func(ctx context.Context, opts *v1.PodLogOptions) (io.ReadCloser, error) {
+		close(started)
+
+		return ioutil.NopCloser(strings.NewReader("2021-01-01T00:00:00Z hello\n")), nil
+	}
+
+	go func() {
+		// simulate the container starting
+		_watch.Modify(_running)
+	}()
+
+	// run test
+	rc, err := _engine.TailContainer(context.Background(), _container)
+	if err != nil {
+		t.Errorf("TailContainer returned err: %v", err)
+	}
+
+	select {
+	case <-started:
+	default:
+		t.Errorf("TailContainer did not capture logs after the container started")
+	}
+
+	// simulate the container terminating
+	setState(t, _engine, v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "Completed"}})
+
+	got, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Errorf("ReadAll returned err: %v", err)
+	}
+
+	if string(got) != "hello\n" {
+		t.Errorf("TailContainer is %q, want %q", got, "hello\n")
+	}
+}
+
+func TestKubernetes_TailContainer_PauseImage(t *testing.T) {
+	// setup types
+	_running := podWithState(v1.ContainerState{Running: &v1.ContainerStateRunning{}})
+
+	// the status still shows the placeholder image
+	// before the image is patched for the step
+	_paused := _running.DeepCopy()
+	_paused.Status.ContainerStatuses[0].Image = "docker.io/kubernetes/pause:latest"
+
+	_engine, err := NewMock(_paused)
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	_watch := watch.NewFakeWithChanSize(1, false)
+
+	_kubernetes := fake.NewSimpleClientset(_paused)
+	_kubernetes.PrependWatchReactor("pods", testcore.DefaultWatchReactor(_watch, nil))
+
+	_engine.Kubernetes = _kubernetes
+
+	_engine.getLogs = func(ctx context.Context, opts *v1.PodLogOptions) (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader("2021-01-01T00:00:00Z hello\n")), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// run test
+	_, err = _engine.TailContainer(ctx, _container)
+	if err != context.DeadlineExceeded {
+		t.Errorf("TailContainer for placeholder image returned err %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// the watch is stopped when the context is cancelled
+	_watch = watch.NewFakeWithChanSize(1, false)
+	_kubernetes.PrependWatchReactor("pods", testcore.DefaultWatchReactor(_watch, nil))
+
+	go func() {
+		// simulate the container restarting with the step image
+		_watch.Modify(_running)
+	}()
+
+	rc, err := _engine.TailContainer(context.Background(), _container)
+	if err != nil {
+		t.Errorf("TailContainer returned err: %v", err)
+	}
+
+	// simulate the container terminating
+	setState(t, _engine, v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "Completed"}})
+
+	got, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Errorf("ReadAll returned err: %v", err)
+	}
+
+	if string(got) != "hello\n" {
+		t.Errorf("TailContainer is %q, want %q", got, "hello\n")
+	}
+}
+
+func TestKubernetes_TailContainer_Reconnect(t *testing.T) {
+	// setup types
+	_running := podWithState(v1.ContainerState{Running: &v1.ContainerStateRunning{}})
+
+	_engine, err := NewMock(_running)
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	var (
+		mutex sync.Mutex
+		since []*metav1.Time
+	)
+
+	// the second stream replays the lines from the start of the
+	// second as SinceTime only has a precision of seconds
+	streams := []string{
+		"2021-01-01T00:00:00.100000000Z one\n" +
+			"2021-01-01T00:00:00.500000000Z two\n" +
+			"2021-01-01T00:00:00.500000000Z three\n",
+		"2021-01-01T00:00:00.100000000Z one\n" +
+			"2021-01-01T00:00:00.500000000Z two\n" +
+			"2021-01-01T00:00:00.500000000Z three\n" +
+			"2021-01-01T00:00:00.500000000Z four\n" +
+			"2021-01-01T00:00:01.000000000Z five\n",
+	}
+
+	_engine.getLogs = func(ctx context.Context, opts *v1.PodLogOptions) (io.ReadCloser, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		since = append(since, opts.SinceTime)
+
+		// simulate the container terminating before the last stream
+		if len(since) == len(streams) {
+			setState(t, _engine, v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "Completed"}})
+		}
+
+		return ioutil.NopCloser(strings.NewReader(streams[len(since)-1])), nil
+	}
+
+	// run test
+	rc, err := _engine.TailContainer(context.Background(), _container)
+	if err != nil {
+		t.Errorf("TailContainer returned err: %v", err)
+	}
+
+	got, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Errorf("ReadAll returned err: %v", err)
+	}
+
+	want := "one\ntwo\nthree\nfour\nfive\n"
+
+	if string(got) != want {
+		t.Errorf("TailContainer is %q, want %q", got, want)
+	}
+
+	if len(since) != 2 {
+		t.Fatalf("TailContainer captured logs %d times, want 2", len(since))
+	}
+
+	if since[0] != nil {
+		t.Errorf("TailContainer SinceTime is %v, want nil", since[0])
+	}
+
+	last := time.Date(2021, 1, 1, 0, 0, 0, 500000000, time.UTC)
+
+	if since[1] == nil || !since[1].Time.Equal(last) {
+		t.Errorf("TailContainer SinceTime is %v, want %v", since[1], last)
+	}
+}
+
+func TestKubernetes_TailContainer_Reconnect_Partial(t *testing.T) {
+	// setup types
+	_running := podWithState(v1.ContainerState{Running: &v1.ContainerStateRunning{}})
+
+	_engine, err := NewMock(_running)
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	var (
+		mutex sync.Mutex
+		calls int
+	)
+
+	// the first stream disconnects partway through a line
+	// which the second stream replays with the same timestamp
+	streams := []string{
+		"2021-01-01T00:00:00.100000000Z one\n" +
+			"2021-01-01T00:00:00.500000000Z tw",
+		"2021-01-01T00:00:00.100000000Z one\n" +
+			"2021-01-01T00:00:00.500000000Z two\n" +
+			"2021-01-01T00:00:01.000000000Z three",
+	}
+
+	_engine.getLogs = func(ctx context.Context, opts *v1.PodLogOptions) (io.ReadCloser, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		calls++
+
+		// simulate the container terminating before the last stream
+		if calls == len(streams) {
+			setState(t, _engine, v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "Completed"}})
+		}
+
+		return ioutil.NopCloser(strings.NewReader(streams[calls-1])), nil
+	}
+
+	// run test
+	rc, err := _engine.TailContainer(context.Background(), _container)
+	if err != nil {
+		t.Errorf("TailContainer returned err: %v", err)
+	}
+
+	got, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Errorf("ReadAll returned err: %v", err)
+	}
+
+	want := "one\ntwo\nthree"
+
+	if string(got) != want {
+		t.Errorf("TailContainer is %q, want %q", got, want)
+	}
+}
+
+func TestKubernetes_TailContainer_Deleted(t *testing.T) {
+	// setup types
+	_waiting := podWithState(v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ContainerCreating"}})
+
+	_engine, err := NewMock(_waiting)
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	_watch := watch.NewFakeWithChanSize(1, false)
+
+	_kubernetes := fake.NewSimpleClientset(_waiting)
+	_kubernetes.PrependWatchReactor("pods", testcore.DefaultWatchReactor(_watch, nil))
+
+	_engine.Kubernetes = _kubernetes
+
+	go func() {
+		// simulate deleting the pod
+		_watch.Delete(_waiting)
+	}()
+
+	// run test
+	_, err = _engine.TailContainer(context.Background(), _container)
+	if err == nil {
+		t.Errorf("TailContainer should have returned err")
+	}
+}
+
+func TestKubernetes_TailContainer_Cancel(t *testing.T) {
+	// setup types
+	_waiting := podWithState(v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ContainerCreating"}})
+
+	_engine, err := NewMock(_waiting)
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// run test
+	_, err = _engine.TailContainer(ctx, _container)
+	if err != context.DeadlineExceeded {
+		t.Errorf("TailContainer returned err %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+// podWithState is a helper function to create a pod with
+// the provided state for the status of the clone container.
+func podWithState(state v1.ContainerState) *v1.Pod {
+	pod := _pod.DeepCopy()
+
+	pod.Status.ContainerStatuses = []v1.ContainerStatus{
+		{
+			Name:  _container.ID,
+			Image: _container.Image,
+			State: state,
+		},
+	}
+
+	return pod
+}
+
+// setState is a helper function to update the state for
+// the status of the clone container in the fake clientset.
+func setState(t *testing.T, c *client, state v1.ContainerState) {
+	_, err := c.Kubernetes.CoreV1().Pods(c.config.Namespace).
+		UpdateStatus(context.Background(), podWithState(state), metav1.UpdateOptions{})
+	if err != nil {
+		t.Errorf("unable to update pod status: %v", err)
+	}
+}
@@ -20,6 +20,14 @@ import (
 func (c *client) CreateNetwork(ctx context.Context, b *pipeline.Build) error {
 	logrus.Tracef("creating network for pipeline %s", b.ID)
 
+	s, err := c.buildState(b)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	// nolint: lll // ignore long line length due to link
 	// create the network for the pod
 	//
@@ -82,7 +90,13 @@ func (c *client) CreateNetwork(ctx context.Context, b *pipeline.Build) error {
 	// add the network definition to the pod spec
 	//
 	// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#PodSpec
-	c.Pod.Spec.HostAliases = append(c.Pod.Spec.HostAliases, network)
+	s.Pod.Spec.HostAliases = append(s.Pod.Spec.HostAliases, network)
+
+	// check if egress from the pod should be restricted
+	if c.config.EgressPolicy {
+		// create the network policy for the pod
+		return c.createPolicy(ctx, s, b)
+	}
 
 	return nil
 }
@@ -91,6 +105,14 @@ func (c *client) CreateNetwork(ctx context.Context, b *pipeline.Build) error {
 func (c *client) InspectNetwork(ctx context.Context, b *pipeline.Build) ([]byte, error) {
 	logrus.Tracef("inspecting network for pipeline %s", b.ID)
 
+	s, err := c.buildState(b)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	// TODO: consider updating this command
 	//
 	// create output for inspecting volume
@@ -99,26 +121,54 @@ func (c *client) InspectNetwork(ctx context.Context, b *pipeline.Build) ([]byte,
 	)
 
 	// marshal the network information from the pod
-	network, err := json.MarshalIndent(c.Pod.Spec.HostAliases, "", " ")
+	network, err := json.MarshalIndent(s.Pod.Spec.HostAliases, "", " ")
 	if err != nil {
 		return output, err
 	}
 
-	return append(output, append(network, "\n"...)...), nil
+	output = append(output, append(network, "\n"...)...)
+
+	// check if the network policy for the pod was created
+	if !s.createdPolicy {
+		return output, nil
+	}
+
+	// create output for inspecting the network policy
+	output = append(output, []byte(
+		fmt.Sprintf("$ kubectl get networkpolicy -o=jsonpath='{.spec}' %s\n", s.policy.ObjectMeta.Name),
+	)...)
+
+	// marshal the network policy information for the pod
+	policy, err := json.MarshalIndent(s.policy.Spec, "", " ")
+	if err != nil {
+		return output, err
+	}
+
+	return append(output, append(policy, "\n"...)...), nil
 }
 
 // RemoveNetwork deletes the pipeline network.
 //
 // Currently, this is comparable to a no-op because in Kubernetes the
 // network lives and dies with the pod it's attached to. However, Vela
-// uses it to cleanup the network definition for the pod.
+// uses it to cleanup the network definition and the network policy
+// for the pod.
 func (c *client) RemoveNetwork(ctx context.Context, b *pipeline.Build) error {
 	logrus.Tracef("removing network for pipeline %s", b.ID)
 
+	s, err := c.buildState(b)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	// remove the network definition from the pod spec
 	//
 	// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#PodSpec
-	c.Pod.Spec.HostAliases = []v1.HostAlias{}
+	s.Pod.Spec.HostAliases = []v1.HostAlias{}
 
-	return nil
+	// remove the network policy for the pod
+	return c.removePolicy(ctx, s)
 }
@@ -6,8 +6,18 @@ package kubernetes
 
 import (
 	"fmt"
+	"strings"
+
+	"github.com/docker/go-units"
+
+	"github.com/go-vela/pkg-runtime/internal/image"
+	"github.com/go-vela/pkg-runtime/internal/network"
+	"github.com/go-vela/pkg-runtime/internal/signature"
 
 	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // ClientOpt represents a configuration option to initialize the runtime client.
@@ -65,3 +75,315 @@ func WithHostVolumes(volumes []string) ClientOpt {
 		return nil
 	}
 }
+
+// WithImagePolicy sets the Kubernetes allowed and denied images in the runtime client.
+func WithImagePolicy(allowed, denied []string) ClientOpt {
+	logrus.Trace("configuring image policy in kubernetes runtime client")
+
+	return func(c *client) error {
+		// set the runtime allowed and denied images in the kubernetes client
+		c.config.Policy.AllowedImages = allowed
+		c.config.Policy.DeniedImages = denied
+
+		return nil
+	}
+}
+
+// WithRegistryPolicy sets the Kubernetes allowed and denied registries in the runtime client.
+func WithRegistryPolicy(allowed, denied []string) ClientOpt {
+	logrus.Trace("configuring registry policy in kubernetes runtime client")
+
+	return func(c *client) error {
+		// set the runtime allowed and denied registries in the kubernetes client
+		c.config.Policy.AllowedRegistries = allowed
+		c.config.Policy.DeniedRegistries = denied
+
+		return nil
+	}
+}
+
+// WithRegistryMirrors sets the Kubernetes registry mirrors in the runtime client.
+func WithRegistryMirrors(mirrors []string) ClientOpt {
+	logrus.Trace("configuring registry mirrors in kubernetes runtime client")
+
+	return func(c *client) error {
+		// parse the registry mirrors provided
+		//
+		// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/image#ParseMirrors
+		_mirrors, err := image.ParseMirrors(mirrors)
+		if err != nil {
+			return err
+		}
+
+		// set the runtime registry mirrors in the kubernetes client
+		c.config.Mirrors = _mirrors
+
+		return nil
+	}
+}
+
+// WithImageVerification sets the Kubernetes image signature verification in the runtime client.
+func WithImageVerification(keys, images []string) ClientOpt {
+	logrus.Trace("configuring image verification in kubernetes runtime client")
+
+	return func(c *client) error {
+		// create the image signature verifier
+		//
+		// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/signature#New
+		verifier, err := signature.New(keys, images)
+		if err != nil {
+			return err
+		}
+
+		// set the runtime image signature verifier in the kubernetes client
+		c.config.Verifier = verifier
+
+		return nil
+	}
+}
+
+// WithPlatform sets the Kubernetes default platform, in the form
+// <os>/<arch>[/<variant>], for images in the runtime client.
+func WithPlatform(platform string) ClientOpt {
+	logrus.Trace("configuring platform in kubernetes runtime client")
+
+	return func(c *client) error {
+		// parse the platform provided
+		//
+		// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/image#ParsePlatform
+		p, err := image.ParsePlatform(platform)
+		if err != nil {
+			return err
+		}
+
+		// set the runtime platform in the kubernetes client
+		c.config.Platform = p
+
+		return nil
+	}
+}
+
+// WithPodTemplate sets the Kubernetes pod template file in the runtime client.
+//
+// The labels, annotations, node selector, tolerations, affinity,
+// priority class and service account from the pod template are
+// merged into the pod for every build. The pipeline label, and
+// the node affinity for the platform of the build, always take
+// precedence over the pod template.
+func WithPodTemplate(file string) ClientOpt {
+	logrus.Trace("configuring pod template in kubernetes runtime client")
+
+	return func(c *client) error {
+		// check if the pod template file provided is empty
+		if len(file) == 0 {
+			return nil
+		}
+
+		// read and validate the pod template from the file
+		t, err := loadPodTemplate(file)
+		if err != nil {
+			return err
+		}
+
+		// set the runtime pod template in the kubernetes client
+		c.config.PodTemplate = t
+
+		return nil
+	}
+}
+
+// WithWorkspaceVolume sets the Kubernetes workspace volume in the runtime client.
+//
+// The type of volume may be one of:
+//
+//   * emptyDir  - a directory on the disk for the node (default)
+//   * memory    - a tmpfs in the memory for the node, limited to the size
+//   * pvc       - a persistent volume claim created for each build with the storage class and size
+//   * ephemeral - a generic ephemeral volume with the storage class and size
+func WithWorkspaceVolume(kind, storageClass, size string) ClientOpt {
+	logrus.Trace("configuring workspace volume in kubernetes runtime client")
+
+	return func(c *client) error {
+		w := &workspace{
+			Type:         kind,
+			StorageClass: storageClass,
+		}
+
+		// check if the type of volume provided is empty
+		if len(w.Type) == 0 {
+			w.Type = WorkspaceEmptyDir
+		}
+
+		// check if the size provided is empty
+		if len(size) > 0 {
+			// parse the size for the workspace
+			//
+			// https://pkg.go.dev/k8s.io/apimachinery/pkg/api/resource?tab=doc#ParseQuantity
+			quantity, err := resource.ParseQuantity(size)
+			if err != nil {
+				return fmt.Errorf("invalid workspace size provided: %s", size)
+			}
+
+			w.Size = &quantity
+		}
+
+		switch w.Type {
+		case WorkspaceEmptyDir:
+			// check if a storage class or size are provided
+			if len(w.StorageClass) > 0 || w.Size != nil {
+				return fmt.Errorf("workspace storage class and size are not supported for %s", w.Type)
+			}
+		case WorkspaceMemory:
+			// check if a storage class is provided
+			if len(w.StorageClass) > 0 {
+				return fmt.Errorf("workspace storage class is not supported for %s", w.Type)
+			}
+		case WorkspaceClaim, WorkspaceEphemeral:
+			// check if a size is provided
+			if w.Size == nil {
+				return fmt.Errorf("no workspace size provided for %s", w.Type)
+			}
+		default:
+			return fmt.Errorf("invalid workspace volume provided: %s", w.Type)
+		}
+
+		// set the runtime workspace volume in the kubernetes client
+		c.config.Workspace = w
+
+		return nil
+	}
+}
+
+// WithLogLimits sets the Kubernetes limits on the bytes (i.e. 10MB)
+// and lines of the logs for each container in the runtime client.
+func WithLogLimits(size string, lines int64) ClientOpt {
+	logrus.Trace("configuring log limits in kubernetes runtime client")
+
+	return func(c *client) error {
+		// check if the lines limit provided is negative
+		if lines < 0 {
+			return fmt.Errorf("invalid log limit on lines provided: %d", lines)
+		}
+
+		// set the runtime log limit on lines in the kubernetes client
+		c.config.LogLimit.Lines = lines
+
+		// check if the bytes limit provided is empty
+		if len(size) == 0 {
+			return nil
+		}
+
+		// parse the bytes limit provided
+		//
+		// https://pkg.go.dev/github.com/docker/go-units#RAMInBytes
+		_bytes, err := units.RAMInBytes(size)
+		if err != nil {
+			return fmt.Errorf("invalid log limit on bytes provided: %w", err)
+		}
+
+		// set the runtime log limit on bytes in the kubernetes client
+		c.config.LogLimit.Bytes = _bytes
+
+		return nil
+	}
+}
+
+// WithLogLimitTail sets the Kubernetes number of lines retained from
+// the end of the logs for a container that exceeds the log
+// limits in the runtime client.
+func WithLogLimitTail(lines int) ClientOpt {
+	logrus.Trace("configuring log limit tail in kubernetes runtime client")
+
+	return func(c *client) error {
+		// check if the tail provided is negative
+		if lines < 0 {
+			return fmt.Errorf("invalid log limit tail provided: %d", lines)
+		}
+
+		// set the runtime log limit tail in the kubernetes client
+		c.config.LogLimit.Tail = lines
+
+		return nil
+	}
+}
+
+// WithLogLimitKill sets the Kubernetes option to kill a container
+// that exceeds the log limits in the runtime client.
+//
+// Kubernetes can not kill a single container in a pod,
+// so the pod for the build is deleted instead.
+func WithLogLimitKill(kill bool) ClientOpt {
+	logrus.Trace("configuring log limit kill in kubernetes runtime client")
+
+	return func(c *client) error {
+		// set the runtime log limit kill in the kubernetes client
+		c.config.LogLimit.Kill = kill
+
+		return nil
+	}
+}
+
+// WithSecretFiles sets the Kubernetes list of secret names to deliver
+// as files in a secret volume, rather than in the environment,
+// in the runtime client.
+func WithSecretFiles(names []string) ClientOpt {
+	logrus.Trace("configuring secret files in kubernetes runtime client")
+
+	return func(c *client) error {
+		// set the runtime secret files in the kubernetes client
+		c.config.SecretFiles = names
+
+		return nil
+	}
+}
+
+// WithWorkerID sets the Kubernetes worker ID labeled
+// on pods for builds in the runtime client.
+//
+// The hostname is used when no worker ID is provided.
+func WithWorkerID(id string) ClientOpt {
+	logrus.Trace("configuring worker ID in kubernetes runtime client")
+
+	return func(c *client) error {
+		// check if the worker ID provided is empty
+		if len(id) == 0 {
+			return nil
+		}
+
+		// check if the worker ID provided is a valid label value
+		//
+		// https://pkg.go.dev/k8s.io/apimachinery/pkg/util/validation?tab=doc#IsValidLabelValue
+		if errs := validation.IsValidLabelValue(id); len(errs) > 0 {
+			return fmt.Errorf("invalid worker ID provided: %s", strings.Join(errs, "; "))
+		}
+
+		// set the runtime worker ID in the kubernetes client
+		c.config.WorkerID = id
+
+		return nil
+	}
+}
+
+// WithEgressPolicy sets the Kubernetes egress policy in the runtime
+// client. When enabled, a network policy is created for the pod for
+// each build only allowing egress to the rules, in the form
+// <cidr>[=<port>[/<protocol>]], and DNS lookups.
+func WithEgressPolicy(enabled bool, rules []string) ClientOpt {
+	logrus.Trace("configuring egress policy in kubernetes runtime client")
+
+	return func(c *client) error {
+		// parse the egress rules provided
+		//
+		// https://pkg.go.dev/github.com/go-vela/pkg-runtime/internal/network#ParseEgress
+		_rules, err := network.ParseEgress(rules)
+		if err != nil {
+			return err
+		}
+
+		// set the runtime egress policy in the kubernetes client
+		c.config.EgressPolicy = enabled
+		c.config.EgressRules = _rules
+
+		return nil
+	}
+}
@@ -5,8 +5,15 @@
 package kubernetes
 
 import (
+	"os"
 	"reflect"
 	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/go-vela/pkg-runtime/internal/logs"
+
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 func TestKubernetes_ClientOpt_WithConfigFile(t *testing.T) {
@@ -161,3 +168,629 @@ func TestKubernetes_ClientOpt_WithPrivilegedImages(t *testing.T) {
 		}
 	}
 }
+
+func TestKubernetes_ClientOpt_WithImagePolicy(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		allowed []string
+		denied  []string
+	}{
+		{
+			allowed: []string{"target/vela-*"},
+			denied:  []string{"alpine"},
+		},
+		{
+			allowed: []string{},
+			denied:  []string{},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithConfigFile("testdata/config"),
+			WithImagePolicy(test.allowed, test.denied),
+		)
+
+		if err != nil {
+			t.Errorf("WithImagePolicy returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_engine.config.Policy.AllowedImages, test.allowed) {
+			t.Errorf("WithImagePolicy allowed is %v, want %v", _engine.config.Policy.AllowedImages, test.allowed)
+		}
+
+		if !reflect.DeepEqual(_engine.config.Policy.DeniedImages, test.denied) {
+			t.Errorf("WithImagePolicy denied is %v, want %v", _engine.config.Policy.DeniedImages, test.denied)
+		}
+	}
+}
+
+func TestKubernetes_ClientOpt_WithRegistryPolicy(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		allowed []string
+		denied  []string
+	}{
+		{
+			allowed: []string{"docker.company.com"},
+			denied:  []string{"docker.io"},
+		},
+		{
+			allowed: []string{},
+			denied:  []string{},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithConfigFile("testdata/config"),
+			WithRegistryPolicy(test.allowed, test.denied),
+		)
+
+		if err != nil {
+			t.Errorf("WithRegistryPolicy returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_engine.config.Policy.AllowedRegistries, test.allowed) {
+			t.Errorf("WithRegistryPolicy allowed is %v, want %v", _engine.config.Policy.AllowedRegistries, test.allowed)
+		}
+
+		if !reflect.DeepEqual(_engine.config.Policy.DeniedRegistries, test.denied) {
+			t.Errorf("WithRegistryPolicy denied is %v, want %v", _engine.config.Policy.DeniedRegistries, test.denied)
+		}
+	}
+}
+
+func TestKubernetes_ClientOpt_WithRegistryMirrors(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		mirrors []string
+		want    map[string]string
+	}{
+		{
+			failure: false,
+			mirrors: []string{"docker.io=mirror.company.com:5000"},
+			want:    map[string]string{"docker.io": "mirror.company.com:5000"},
+		},
+		{
+			failure: false,
+			mirrors: []string{},
+			want:    map[string]string{},
+		},
+		{
+			failure: true,
+			mirrors: []string{"docker.io"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithConfigFile("testdata/config"),
+			WithRegistryMirrors(test.mirrors),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithRegistryMirrors should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithRegistryMirrors returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_engine.config.Mirrors, test.want) {
+			t.Errorf("WithRegistryMirrors is %v, want %v", _engine.config.Mirrors, test.want)
+		}
+	}
+}
+
+func TestKubernetes_ClientOpt_WithImageVerification(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		keys    []string
+		images  []string
+		want    bool
+	}{
+		{
+			failure: false,
+			keys:    []string{},
+			images:  []string{},
+			want:    false,
+		},
+		{
+			failure: true,
+			keys:    []string{},
+			images:  []string{"target/vela-*"},
+		},
+		{
+			failure: true,
+			keys:    []string{"testdata/notfound.pub"},
+			images:  []string{"target/vela-*"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithConfigFile("testdata/config"),
+			WithImageVerification(test.keys, test.images),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithImageVerification should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithImageVerification returned err: %v", err)
+		}
+
+		if (_engine.config.Verifier != nil) != test.want {
+			t.Errorf("WithImageVerification is %v, want enabled %v", _engine.config.Verifier, test.want)
+		}
+	}
+}
+
+func TestKubernetes_ClientOpt_WithPlatform(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure  bool
+		platform string
+		want     *specs.Platform
+	}{
+		{
+			failure:  false,
+			platform: "linux/arm64",
+			want:     &specs.Platform{OS: "linux", Architecture: "arm64"},
+		},
+		{
+			failure:  false,
+			platform: "",
+			want:     nil,
+		},
+		{
+			failure:  true,
+			platform: "foo/bar",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithConfigFile("testdata/config"),
+			WithPlatform(test.platform),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithPlatform should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithPlatform returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_engine.config.Platform, test.want) {
+			t.Errorf("WithPlatform is %v, want %v", _engine.config.Platform, test.want)
+		}
+	}
+}
+
+func TestKubernetes_ClientOpt_WithPodTemplate(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		file    string
+		want    bool
+	}{
+		{
+			failure: false,
+			file:    "testdata/pod_template/valid.yml",
+			want:    true,
+		},
+		{
+			failure: false,
+			file:    "",
+			want:    false,
+		},
+		{
+			failure: true,
+			file:    "testdata/pod_template/unknown_field.yml",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithConfigFile("testdata/config"),
+			WithPodTemplate(test.file),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithPodTemplate should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithPodTemplate returned err: %v", err)
+		}
+
+		if got := _engine.config.PodTemplate != nil; got != test.want {
+			t.Errorf("WithPodTemplate is %v, want %v", got, test.want)
+		}
+	}
+}
+
+func TestKubernetes_ClientOpt_WithWorkspaceVolume(t *testing.T) {
+	// setup types
+	size := resource.MustParse("10Gi")
+
+	// setup tests
+	tests := []struct {
+		failure      bool
+		kind         string
+		storageClass string
+		size         string
+		want         *workspace
+	}{
+		{
+			failure: false,
+			want:    &workspace{Type: WorkspaceEmptyDir},
+		},
+		{
+			failure: false,
+			kind:    WorkspaceMemory,
+			size:    "10Gi",
+			want:    &workspace{Type: WorkspaceMemory, Size: &size},
+		},
+		{
+			failure:      false,
+			kind:         WorkspaceClaim,
+			storageClass: "fast",
+			size:         "10Gi",
+			want:         &workspace{Type: WorkspaceClaim, StorageClass: "fast", Size: &size},
+		},
+		{
+			failure: false,
+			kind:    WorkspaceEphemeral,
+			size:    "10Gi",
+			want:    &workspace{Type: WorkspaceEphemeral, Size: &size},
+		},
+		{
+			failure: true,
+			kind:    WorkspaceClaim,
+		},
+		{
+			failure: true,
+			kind:    WorkspaceEmptyDir,
+			size:    "10Gi",
+		},
+		{
+			failure:      true,
+			kind:         WorkspaceMemory,
+			storageClass: "fast",
+		},
+		{
+			failure: true,
+			kind:    WorkspaceEphemeral,
+			size:    "foo",
+		},
+		{
+			failure: true,
+			kind:    "hostPath",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithConfigFile("testdata/config"),
+			WithWorkspaceVolume(test.kind, test.storageClass, test.size),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithWorkspaceVolume for %s should have returned err", test.kind)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithWorkspaceVolume for %s returned err: %v", test.kind, err)
+		}
+
+		if !reflect.DeepEqual(_engine.config.Workspace, test.want) {
+			t.Errorf("WithWorkspaceVolume for %s is %+v, want %+v", test.kind, _engine.config.Workspace, test.want)
+		}
+	}
+}
+
+func TestKubernetes_ClientOpt_WithLogLimits(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		size    string
+		lines   int64
+		want    *logs.Limit
+	}{
+		{
+			failure: false,
+			size:    "10MB",
+			lines:   1000,
+			want:    &logs.Limit{Bytes: 10 << 20, Lines: 1000},
+		},
+		{
+			failure: false,
+			size:    "",
+			lines:   0,
+			want:    &logs.Limit{},
+		},
+		{
+			failure: true,
+			size:    "foo",
+		},
+		{
+			failure: true,
+			lines:   -1,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithConfigFile("testdata/config"),
+			WithLogLimits(test.size, test.lines),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithLogLimits should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithLogLimits returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_engine.config.LogLimit, test.want) {
+			t.Errorf("WithLogLimits is %v, want %v", _engine.config.LogLimit, test.want)
+		}
+	}
+}
+
+func TestKubernetes_ClientOpt_WithLogLimitTail(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		tail    int
+		want    int
+	}{
+		{
+			failure: false,
+			tail:    100,
+			want:    100,
+		},
+		{
+			failure: true,
+			tail:    -1,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithConfigFile("testdata/config"),
+			WithLogLimitTail(test.tail),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithLogLimitTail should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithLogLimitTail returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_engine.config.LogLimit.Tail, test.want) {
+			t.Errorf("WithLogLimitTail is %v, want %v", _engine.config.LogLimit.Tail, test.want)
+		}
+	}
+}
+
+func TestKubernetes_ClientOpt_WithLogLimitKill(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		kill bool
+		want bool
+	}{
+		{
+			kill: true,
+			want: true,
+		},
+		{
+			kill: false,
+			want: false,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithConfigFile("testdata/config"),
+			WithLogLimitKill(test.kill),
+		)
+
+		if err != nil {
+			t.Errorf("WithLogLimitKill returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_engine.config.LogLimit.Kill, test.want) {
+			t.Errorf("WithLogLimitKill is %v, want %v", _engine.config.LogLimit.Kill, test.want)
+		}
+	}
+}
+
+func TestKubernetes_ClientOpt_WithSecretFiles(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		files []string
+		want  []string
+	}{
+		{
+			files: []string{"docker_password", "npm_token"},
+			want:  []string{"docker_password", "npm_token"},
+		},
+		{
+			files: nil,
+			want:  nil,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithConfigFile("testdata/config"),
+			WithSecretFiles(test.files),
+		)
+
+		if err != nil {
+			t.Errorf("WithSecretFiles returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_engine.config.SecretFiles, test.want) {
+			t.Errorf("WithSecretFiles is %v, want %v", _engine.config.SecretFiles, test.want)
+		}
+	}
+}
+
+func TestKubernetes_ClientOpt_WithWorkerID(t *testing.T) {
+	// setup types
+	hostname, _ := os.Hostname()
+
+	// setup tests
+	tests := []struct {
+		failure bool
+		id      string
+		want    string
+	}{
+		{
+			failure: false,
+			id:      "worker-1",
+			want:    "worker-1",
+		},
+		{
+			failure: false,
+			id:      "",
+			want:    hostname,
+		},
+		{
+			failure: true,
+			id:      "worker 1",
+			want:    "",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithConfigFile("testdata/config"),
+			WithWorkerID(test.id),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithWorkerID should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithWorkerID returned err: %v", err)
+		}
+
+		if !reflect.DeepEqual(_engine.config.WorkerID, test.want) {
+			t.Errorf("WithWorkerID is %v, want %v", _engine.config.WorkerID, test.want)
+		}
+	}
+}
+
+func TestKubernetes_ClientOpt_WithEgressPolicy(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		enabled bool
+		rules   []string
+		want    int
+	}{
+		{
+			failure: false,
+			enabled: true,
+			rules:   []string{"10.0.0.0/8", "192.168.1.10/32=443/tcp"},
+			want:    2,
+		},
+		{
+			failure: false,
+			enabled: false,
+			rules:   []string{},
+			want:    0,
+		},
+		{
+			failure: true,
+			enabled: true,
+			rules:   []string{"10.0.0.1"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := New(
+			WithConfigFile("testdata/config"),
+			WithEgressPolicy(test.enabled, test.rules),
+		)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithEgressPolicy should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithEgressPolicy returned err: %v", err)
+		}
+
+		if _engine.config.EgressPolicy != test.enabled {
+			t.Errorf("WithEgressPolicy is %v, want %v", _engine.config.EgressPolicy, test.enabled)
+		}
+
+		if len(_engine.config.EgressRules) != test.want {
+			t.Errorf("WithEgressPolicy rules is %v, want %d rules", _engine.config.EgressRules, test.want)
+		}
+	}
+}
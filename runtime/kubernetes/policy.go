@@ -0,0 +1,166 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/go-vela/types/pipeline"
+
+	"github.com/sirupsen/logrus"
+
+	v1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// nolint: gomnd // ignore magic number
+//
+// dnsPort represents the port for DNS lookups, which are
+// always allowed so containers can resolve the allowed hosts.
+var dnsPort = intstr.FromInt(53)
+
+// policySpec is a helper function to create the spec for the
+// network policy restricting egress from the pod for the build.
+//
+// https://kubernetes.io/docs/concepts/services-networking/network-policies/
+func (c *client) policySpec(b *pipeline.Build) netv1.NetworkPolicySpec {
+	tcp := v1.ProtocolTCP
+	udp := v1.ProtocolUDP
+
+	// https://pkg.go.dev/k8s.io/api/networking/v1?tab=doc#NetworkPolicySpec
+	spec := netv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{
+			MatchLabels: map[string]string{"pipeline": b.ID},
+		},
+		PolicyTypes: []netv1.PolicyType{netv1.PolicyTypeEgress},
+		Egress: []netv1.NetworkPolicyEgressRule{
+			{
+				Ports: []netv1.NetworkPolicyPort{
+					{Protocol: &udp, Port: &dnsPort},
+					{Protocol: &tcp, Port: &dnsPort},
+				},
+			},
+		},
+	}
+
+	// iterate through all egress rules provided
+	for _, rule := range c.config.EgressRules {
+		// https://pkg.go.dev/k8s.io/api/networking/v1?tab=doc#NetworkPolicyEgressRule
+		egress := netv1.NetworkPolicyEgressRule{
+			To: []netv1.NetworkPolicyPeer{
+				{IPBlock: &netv1.IPBlock{CIDR: rule.CIDR.String()}},
+			},
+		}
+
+		// check if a port is provided for the rule
+		if rule.Port > 0 {
+			protocol := v1.Protocol(rule.Protocol)
+			port := intstr.FromInt(rule.Port)
+
+			egress.Ports = []netv1.NetworkPolicyPort{
+				{Protocol: &protocol, Port: &port},
+			}
+		}
+
+		spec.Egress = append(spec.Egress, egress)
+	}
+
+	return spec
+}
+
+// createPolicy is a helper function to create the network
+// policy restricting egress from the pod for the build.
+//
+// The policy selects the pod by the label for the build,
+// so it applies as soon as the pod is created.
+func (c *client) createPolicy(ctx context.Context, s *buildState, b *pipeline.Build) error {
+	// check if the policy for the build was already created
+	if s.createdPolicy {
+		return nil
+	}
+
+	// https://pkg.go.dev/k8s.io/api/networking/v1?tab=doc#NetworkPolicy
+	s.policy = &netv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   b.ID,
+			Labels: map[string]string{"pipeline": b.ID, LabelWorker: c.config.WorkerID},
+		},
+		Spec: c.policySpec(b),
+	}
+
+	// If the api call to create the policy fails, the policy
+	// might partially exist. So, set this first to make
+	// sure all remnants get deleted.
+	s.createdPolicy = true
+
+	logrus.Infof("creating network policy %s", b.ID)
+	// send API call to create the policy
+	//
+	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/networking/v1?tab=doc#NetworkPolicyInterface
+	_, err := c.Kubernetes.NetworkingV1().
+		NetworkPolicies(c.config.Namespace).
+		Create(ctx, s.policy, metav1.CreateOptions{})
+
+	return err
+}
+
+// ownPolicy is a helper function to set the pod as the owner
+// of the network policy for the build, so the policy is
+// removed with the pod when the build is orphaned.
+func (c *client) ownPolicy(ctx context.Context, s *buildState, pod *v1.Pod) error {
+	// check if the policy for the build was created
+	if !s.createdPolicy {
+		return nil
+	}
+
+	// https://pkg.go.dev/k8s.io/apimachinery/pkg/apis/meta/v1?tab=doc#OwnerReference
+	s.policy.ObjectMeta.OwnerReferences = []metav1.OwnerReference{
+		{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Name:       pod.ObjectMeta.Name,
+			UID:        pod.ObjectMeta.UID,
+		},
+	}
+
+	// send API call to update the policy
+	//
+	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/networking/v1?tab=doc#NetworkPolicyInterface
+	_, err := c.Kubernetes.NetworkingV1().
+		NetworkPolicies(c.config.Namespace).
+		Update(ctx, s.policy, metav1.UpdateOptions{})
+
+	return err
+}
+
+// removePolicy is a helper function to delete the network
+// policy restricting egress from the pod for the build.
+func (c *client) removePolicy(ctx context.Context, s *buildState) error {
+	// check if the policy for the build was created
+	if !s.createdPolicy {
+		s.policy = nil
+
+		return nil
+	}
+
+	logrus.Infof("removing network policy %s", s.policy.ObjectMeta.Name)
+	// send API call to delete the policy
+	//
+	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/networking/v1?tab=doc#NetworkPolicyInterface
+	err := c.Kubernetes.NetworkingV1().
+		NetworkPolicies(c.config.Namespace).
+		Delete(ctx, s.policy.ObjectMeta.Name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	s.policy = nil
+	s.createdPolicy = false
+
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestKubernetes_NetworkPolicy(t *testing.T) {
+	// setup types
+	_engine, err := NewMock(_pod, WithEgressPolicy(true, []string{"10.0.0.0/8", "192.168.1.10/32=443"}))
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	policies := _engine.Kubernetes.NetworkingV1().NetworkPolicies("test")
+
+	tcp := v1.ProtocolTCP
+	udp := v1.ProtocolUDP
+	https := intstr.FromInt(443)
+
+	want := netv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{
+			MatchLabels: map[string]string{"pipeline": "github-octocat-1"},
+		},
+		PolicyTypes: []netv1.PolicyType{netv1.PolicyTypeEgress},
+		Egress: []netv1.NetworkPolicyEgressRule{
+			{
+				Ports: []netv1.NetworkPolicyPort{
+					{Protocol: &udp, Port: &dnsPort},
+					{Protocol: &tcp, Port: &dnsPort},
+				},
+			},
+			{
+				To: []netv1.NetworkPolicyPeer{{IPBlock: &netv1.IPBlock{CIDR: "10.0.0.0/8"}}},
+			},
+			{
+				To:    []netv1.NetworkPolicyPeer{{IPBlock: &netv1.IPBlock{CIDR: "192.168.1.10/32"}}},
+				Ports: []netv1.NetworkPolicyPort{{Protocol: &tcp, Port: &https}},
+			},
+		},
+	}
+
+	// run tests
+	err = _engine.CreateNetwork(context.Background(), _steps)
+	if err != nil {
+		t.Errorf("CreateNetwork returned err: %v", err)
+	}
+
+	got, err := policies.Get(context.Background(), _steps.ID, metav1.GetOptions{})
+	if err != nil {
+		t.Errorf("CreateNetwork did not create network policy: %v", err)
+	} else if !reflect.DeepEqual(got.Spec, want) {
+		t.Errorf("CreateNetwork network policy is %+v, want %+v", got.Spec, want)
+	}
+
+	output, err := _engine.InspectNetwork(context.Background(), _steps)
+	if err != nil {
+		t.Errorf("InspectNetwork returned err: %v", err)
+	}
+
+	for _, want := range []string{"kubectl get networkpolicy", `"cidr": "192.168.1.10/32"`} {
+		if !strings.Contains(string(output), want) {
+			t.Errorf("InspectNetwork is %s, want %s", output, want)
+		}
+	}
+
+	// check the pod for the build owns the network policy
+	err = _engine.ownPolicy(context.Background(), _engine.builds[_steps.ID], &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: _steps.ID, UID: types.UID("1")},
+	})
+	if err != nil {
+		t.Errorf("ownPolicy returned err: %v", err)
+	}
+
+	got, err = policies.Get(context.Background(), _steps.ID, metav1.GetOptions{})
+	if err != nil {
+		t.Errorf("unable to get network policy: %v", err)
+	} else if len(got.ObjectMeta.OwnerReferences) != 1 || got.ObjectMeta.OwnerReferences[0].UID != "1" {
+		t.Errorf("ownPolicy owners is %+v", got.ObjectMeta.OwnerReferences)
+	}
+
+	err = _engine.RemoveNetwork(context.Background(), _steps)
+	if err != nil {
+		t.Errorf("RemoveNetwork returned err: %v", err)
+	}
+
+	_, err = policies.Get(context.Background(), _steps.ID, metav1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("RemoveNetwork did not remove network policy: %v", err)
+	}
+}
+
+func TestKubernetes_NetworkPolicy_Disabled(t *testing.T) {
+	// setup types
+	_engine, err := NewMock(_pod, WithEgressPolicy(false, []string{"10.0.0.0/8"}))
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	// run test
+	err = _engine.CreateNetwork(context.Background(), _steps)
+	if err != nil {
+		t.Errorf("CreateNetwork returned err: %v", err)
+	}
+
+	list, err := _engine.Kubernetes.NetworkingV1().NetworkPolicies("test").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Errorf("unable to list network policies: %v", err)
+	}
+
+	if len(list.Items) > 0 {
+		t.Errorf("CreateNetwork created %d network policies", len(list.Items))
+	}
+
+	output, err := _engine.InspectNetwork(context.Background(), _steps)
+	if err != nil {
+		t.Errorf("InspectNetwork returned err: %v", err)
+	}
+
+	if strings.Contains(string(output), "networkpolicy") {
+		t.Errorf("InspectNetwork is %s", output)
+	}
+}
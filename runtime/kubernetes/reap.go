@@ -0,0 +1,251 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// LabelWorker represents the label containing
+	// the ID of the worker that created the pod.
+	LabelWorker = "vela.worker"
+	// AnnotationHeartbeat represents the annotation containing the
+	// time, in RFC 3339 format, the worker last updated the pod.
+	AnnotationHeartbeat = "vela.heartbeat"
+)
+
+// ReapReport represents the result of removing
+// orphaned pods for builds from the namespace.
+type ReapReport struct {
+	// specifies the list of pods removed from the namespace
+	Pods []string
+	// specifies the list of secrets removed from the namespace
+	Secrets []string
+	// specifies the list of claims removed from the namespace
+	Claims []string
+	// specifies the list of network policies removed from the namespace
+	Policies []string
+	// specifies the pods were only listed and not removed
+	DryRun bool
+}
+
+// Reap removes the pods for builds, and the secrets, claims and
+// network policies depending on them, that are orphaned in the namespace.
+//
+// Only pods labeled with the worker that created them are candidates,
+// so pods in the namespace that weren't created by Vela are never
+// removed. A pod is orphaned when the heartbeat from the worker is
+// older than the duration provided. When dry run is enabled, the
+// pods and objects are only listed.
+func (c *client) Reap(ctx context.Context, olderThan time.Duration, dryRun bool) (*ReapReport, error) {
+	logrus.Tracef("reaping pods with heartbeats older than %s", olderThan)
+
+	report := &ReapReport{DryRun: dryRun}
+
+	// check if the duration provided is negative
+	if olderThan < 0 {
+		return nil, fmt.Errorf("invalid duration provided for reaping: %s", olderThan)
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+
+	// create options for listing the pods for builds created by workers
+	//
+	// https://pkg.go.dev/k8s.io/apimachinery/pkg/apis/meta/v1?tab=doc#ListOptions
+	opts := metav1.ListOptions{LabelSelector: fmt.Sprintf("pipeline,%s", LabelWorker)}
+
+	// send API call to list the pods for builds
+	//
+	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#PodInterface
+	pods, err := c.Kubernetes.CoreV1().
+		Pods(c.config.Namespace).
+		List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+
+		if !orphaned(pod, cutoff) {
+			continue
+		}
+
+		err := c.reapPod(ctx, pod, report)
+		if err != nil {
+			logrus.Errorf("unable to remove orphaned pod %s: %v", pod.ObjectMeta.Name, err)
+		}
+	}
+
+	return report, nil
+}
+
+// orphaned is a helper function to check if the
+// heartbeat from the worker for the pod is stale.
+//
+// Build pods aren't owned by the worker pod, so a build
+// survives the worker pod being replaced and can be
+// reattached until the heartbeat is stale. The heartbeat
+// is only refreshed by the worker running the build, so
+// a stale heartbeat also covers the worker being gone
+// without checking for it.
+//
+// Pods labeled with the worker without a heartbeat,
+// created before the heartbeat was added, use the
+// creation time.
+func orphaned(pod *v1.Pod, cutoff time.Time) bool {
+	// check if the pod was created by a worker
+	if _, ok := pod.ObjectMeta.Labels[LabelWorker]; !ok {
+		if _, ok := pod.ObjectMeta.Annotations[AnnotationHeartbeat]; !ok {
+			return false
+		}
+	}
+
+	heartbeat := pod.ObjectMeta.CreationTimestamp.Time
+
+	// check if the pod has a heartbeat from the worker
+	if value, ok := pod.ObjectMeta.Annotations[AnnotationHeartbeat]; ok {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			// pods with an invalid heartbeat are never considered orphaned
+			return false
+		}
+
+		heartbeat = t
+	}
+
+	return !heartbeat.IsZero() && heartbeat.Before(cutoff)
+}
+
+// reapPod is a helper function to delete the orphaned pod and the
+// secrets, claims and network policies for the build depending on it.
+func (c *client) reapPod(ctx context.Context, pod *v1.Pod, report *ReapReport) error {
+	name := pod.ObjectMeta.Name
+	selector := metav1.ListOptions{LabelSelector: fmt.Sprintf("pipeline=%s", pod.ObjectMeta.Labels["pipeline"])}
+
+	// send API call to list the secrets for the build
+	//
+	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#SecretInterface
+	secrets, err := c.Kubernetes.CoreV1().Secrets(c.config.Namespace).List(ctx, selector)
+	if err != nil {
+		return err
+	}
+
+	// send API call to list the claims for the build
+	//
+	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#PersistentVolumeClaimInterface
+	claims, err := c.Kubernetes.CoreV1().PersistentVolumeClaims(c.config.Namespace).List(ctx, selector)
+	if err != nil {
+		return err
+	}
+
+	// send API call to list the network policies for the build
+	//
+	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/networking/v1?tab=doc#NetworkPolicyInterface
+	policies, err := c.Kubernetes.NetworkingV1().NetworkPolicies(c.config.Namespace).List(ctx, selector)
+	if err != nil {
+		return err
+	}
+
+	if !report.DryRun {
+		logrus.Infof("removing orphaned pod %s", name)
+
+		// the pod is deleted first to release the claims
+		err = c.Kubernetes.CoreV1().
+			Pods(c.config.Namespace).
+			Delete(ctx, name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	report.Pods = append(report.Pods, name)
+
+	for _, secret := range secrets.Items {
+		if !report.DryRun {
+			err = c.Kubernetes.CoreV1().
+				Secrets(c.config.Namespace).
+				Delete(ctx, secret.ObjectMeta.Name, metav1.DeleteOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+
+		report.Secrets = append(report.Secrets, secret.ObjectMeta.Name)
+	}
+
+	for _, claim := range claims.Items {
+		if !report.DryRun {
+			err = c.Kubernetes.CoreV1().
+				PersistentVolumeClaims(c.config.Namespace).
+				Delete(ctx, claim.ObjectMeta.Name, metav1.DeleteOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+
+		report.Claims = append(report.Claims, claim.ObjectMeta.Name)
+	}
+
+	for _, policy := range policies.Items {
+		if !report.DryRun {
+			err = c.Kubernetes.NetworkingV1().
+				NetworkPolicies(c.config.Namespace).
+				Delete(ctx, policy.ObjectMeta.Name, metav1.DeleteOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+
+		report.Policies = append(report.Policies, policy.ObjectMeta.Name)
+	}
+
+	return nil
+}
+
+// heartbeat is a helper function to periodically update the heartbeat
+// annotation on the pod for the build until the context is canceled.
+func (c *client) heartbeat(ctx context.Context, name string) {
+	ticker := time.NewTicker(c.config.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := c.beat(ctx, name)
+			if err != nil && ctx.Err() == nil {
+				logrus.Errorf("unable to update heartbeat for pod %s: %v", name, err)
+			}
+		}
+	}
+}
+
+// beat is a helper function to update the heartbeat
+// annotation on the pod for the build to the current time.
+func (c *client) beat(ctx context.Context, name string) error {
+	patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`,
+		AnnotationHeartbeat, time.Now().UTC().Format(time.RFC3339))
+
+	// send API call to update the heartbeat for the pod
+	//
+	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#PodInterface
+	_, err := c.Kubernetes.CoreV1().
+		Pods(c.config.Namespace).
+		Patch(ctx, name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+
+	return err
+}
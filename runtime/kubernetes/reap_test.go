@@ -0,0 +1,394 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-vela/types/pipeline"
+
+	v1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// buildPod is a helper function to create a pod
+// for a build with the heartbeat provided.
+func buildPod(name string, heartbeat time.Time) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "test",
+			Labels:      map[string]string{"pipeline": name, LabelWorker: "worker-1"},
+			Annotations: map[string]string{AnnotationHeartbeat: heartbeat.UTC().Format(time.RFC3339)},
+		},
+	}
+}
+
+func TestKubernetes_Reap(t *testing.T) {
+	// setup types
+	now := time.Now()
+
+	_worker := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Namespace: "test", UID: types.UID("1")},
+	}
+
+	objects := func() []runtime.Object {
+		return []runtime.Object{
+			_worker,
+			buildPod("stale", now.Add(-2*time.Hour)),
+			buildPod("fresh", now),
+			&v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "foreign",
+					Namespace:         "test",
+					Labels:            map[string]string{"pipeline": "foreign"},
+					CreationTimestamp: metav1.NewTime(now.Add(-2 * time.Hour)),
+				},
+			},
+			&v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foreign",
+					Namespace: "test",
+					Labels:    map[string]string{"pipeline": "foreign"},
+				},
+			},
+			&v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "stale",
+					Namespace: "test",
+					Labels:    map[string]string{"pipeline": "stale"},
+				},
+			},
+			&v1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "stale",
+					Namespace: "test",
+					Labels:    map[string]string{"pipeline": "stale"},
+				},
+			},
+			&netv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "stale",
+					Namespace: "test",
+					Labels:    map[string]string{"pipeline": "stale"},
+				},
+			},
+		}
+	}
+
+	// setup tests
+	tests := []struct {
+		name    string
+		dryRun  bool
+		want    *ReapReport
+		removed bool
+	}{
+		{
+			name: "reap",
+			want: &ReapReport{
+				Pods:     []string{"stale"},
+				Secrets:  []string{"stale"},
+				Claims:   []string{"stale"},
+				Policies: []string{"stale"},
+			},
+			removed: true,
+		},
+		{
+			name:   "dry run",
+			dryRun: true,
+			want: &ReapReport{
+				Pods:     []string{"stale"},
+				Secrets:  []string{"stale"},
+				Claims:   []string{"stale"},
+				Policies: []string{"stale"},
+				DryRun:   true,
+			},
+			removed: false,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := NewMock(&v1.Pod{})
+		if err != nil {
+			t.Errorf("unable to create runtime engine: %v", err)
+		}
+
+		_engine.Kubernetes = fake.NewSimpleClientset(objects()...)
+
+		got, err := _engine.Reap(context.Background(), time.Hour, test.dryRun)
+		if err != nil {
+			t.Errorf("Reap for %s returned err: %v", test.name, err)
+		}
+
+		sort.Strings(got.Pods)
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Reap for %s is %+v, want %+v", test.name, got, test.want)
+		}
+
+		// check the objects remaining in the namespace
+		core := _engine.Kubernetes.CoreV1()
+
+		for _, name := range test.want.Pods {
+			_, err = core.Pods("test").Get(context.Background(), name, metav1.GetOptions{})
+			if errors.IsNotFound(err) != test.removed {
+				t.Errorf("Reap for %s pod %s removed is %v, want %v", test.name, name, !test.removed, test.removed)
+			}
+		}
+
+		_, err = core.Secrets("test").Get(context.Background(), "stale", metav1.GetOptions{})
+		if errors.IsNotFound(err) != test.removed {
+			t.Errorf("Reap for %s secret removed is %v, want %v", test.name, !test.removed, test.removed)
+		}
+
+		_, err = core.PersistentVolumeClaims("test").Get(context.Background(), "stale", metav1.GetOptions{})
+		if errors.IsNotFound(err) != test.removed {
+			t.Errorf("Reap for %s claim removed is %v, want %v", test.name, !test.removed, test.removed)
+		}
+
+		_, err = _engine.Kubernetes.NetworkingV1().
+			NetworkPolicies("test").Get(context.Background(), "stale", metav1.GetOptions{})
+		if errors.IsNotFound(err) != test.removed {
+			t.Errorf("Reap for %s network policy removed is %v, want %v", test.name, !test.removed, test.removed)
+		}
+
+		for _, name := range []string{"worker-1", "fresh", "foreign"} {
+			_, err = core.Pods("test").Get(context.Background(), name, metav1.GetOptions{})
+			if err != nil {
+				t.Errorf("Reap for %s removed pod %s: %v", test.name, name, err)
+			}
+		}
+
+		// check the secret for a pod not created by a worker remains
+		_, err = core.Secrets("test").Get(context.Background(), "foreign", metav1.GetOptions{})
+		if err != nil {
+			t.Errorf("Reap for %s removed secret foreign: %v", test.name, err)
+		}
+	}
+}
+
+func TestKubernetes_Reap_Failure(t *testing.T) {
+	// setup types
+	_engine, err := NewMock(&v1.Pod{})
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	_, err = _engine.Reap(context.Background(), -1*time.Hour, false)
+	if err == nil {
+		t.Errorf("Reap should have returned err")
+	}
+}
+
+// collectGarbage is a helper function to delete the pods
+// owned by a pod that is gone or was replaced, like the
+// garbage collector for the cluster does.
+func collectGarbage(t *testing.T, client *fake.Clientset) {
+	t.Helper()
+
+	pods := client.CoreV1().Pods("test")
+
+	list, err := pods.List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unable to list pods: %v", err)
+	}
+
+	for _, pod := range list.Items {
+		for _, owner := range pod.ObjectMeta.OwnerReferences {
+			_owner, err := pods.Get(context.Background(), owner.Name, metav1.GetOptions{})
+			if err == nil && _owner.ObjectMeta.UID == owner.UID {
+				continue
+			}
+
+			err = pods.Delete(context.Background(), pod.ObjectMeta.Name, metav1.DeleteOptions{})
+			if err != nil {
+				t.Fatalf("unable to delete pod %s: %v", pod.ObjectMeta.Name, err)
+			}
+
+			break
+		}
+	}
+}
+
+func TestKubernetes_Reap_WorkerReplaced(t *testing.T) {
+	// setup types
+	_step := &pipeline.Container{
+		ID:        "step_github_octocat_1_echo",
+		Directory: "/vela/src/github.com/octocat/helloworld",
+		Image:     "alpine:latest",
+		Name:      "echo",
+		Number:    2,
+		Pull:      "not_present",
+	}
+
+	_build := &pipeline.Build{
+		ID:      "github-octocat-1",
+		Version: "1",
+		Steps:   pipeline.ContainerSlice{_step},
+	}
+
+	_worker := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Namespace: "test", UID: types.UID("1")},
+	}
+
+	_engine, err := NewMock(&v1.Pod{}, WithWorkerID("worker-1"))
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	_client := fake.NewSimpleClientset(_worker)
+	_engine.Kubernetes = _client
+
+	err = _engine.SetupBuild(context.Background(), _build)
+	if err != nil {
+		t.Errorf("SetupBuild returned err: %v", err)
+	}
+
+	err = _engine.SetupContainer(context.Background(), _step)
+	if err != nil {
+		t.Errorf("SetupContainer returned err: %v", err)
+	}
+
+	err = _engine.AssembleBuild(context.Background(), _build)
+	if err != nil {
+		t.Errorf("AssembleBuild returned err: %v", err)
+	}
+
+	defer func() {
+		_ = _engine.RemoveBuild(context.Background(), _build)
+	}()
+
+	pods := _client.CoreV1().Pods("test")
+
+	// replace the worker pod with a new pod of the same name
+	err = pods.Delete(context.Background(), _worker.ObjectMeta.Name, metav1.DeleteOptions{})
+	if err != nil {
+		t.Errorf("unable to delete worker pod: %v", err)
+	}
+
+	_replaced := _worker.DeepCopy()
+	_replaced.ObjectMeta.UID = types.UID("2")
+
+	_, err = pods.Create(context.Background(), _replaced, metav1.CreateOptions{})
+	if err != nil {
+		t.Errorf("unable to create worker pod: %v", err)
+	}
+
+	collectGarbage(t, _client)
+
+	report, err := _engine.Reap(context.Background(), time.Hour, false)
+	if err != nil {
+		t.Errorf("Reap returned err: %v", err)
+	}
+
+	if len(report.Pods) > 0 {
+		t.Errorf("Reap removed pods %v", report.Pods)
+	}
+
+	// check the build pod survives the worker pod being replaced
+	pod, err := pods.Get(context.Background(), _build.ID, metav1.GetOptions{})
+	if err != nil {
+		t.Errorf("build pod %s was removed with worker pod: %v", _build.ID, err)
+	}
+
+	if pod != nil && pod.ObjectMeta.Labels[LabelWorker] != "worker-1" {
+		t.Errorf("build pod worker is %s, want worker-1", pod.ObjectMeta.Labels[LabelWorker])
+	}
+}
+
+func TestKubernetes_AssembleBuild_Heartbeat(t *testing.T) {
+	// setup types
+	_step := &pipeline.Container{
+		ID:        "step_github_octocat_1_echo",
+		Directory: "/vela/src/github.com/octocat/helloworld",
+		Image:     "alpine:latest",
+		Name:      "echo",
+		Number:    2,
+		Pull:      "not_present",
+	}
+
+	_build := &pipeline.Build{
+		ID:      "github-octocat-1",
+		Version: "1",
+		Steps:   pipeline.ContainerSlice{_step},
+	}
+
+	_engine, err := NewMock(&v1.Pod{})
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	_engine.config.HeartbeatInterval = 10 * time.Millisecond
+
+	err = _engine.SetupBuild(context.Background(), _build)
+	if err != nil {
+		t.Errorf("SetupBuild returned err: %v", err)
+	}
+
+	err = _engine.SetupContainer(context.Background(), _step)
+	if err != nil {
+		t.Errorf("SetupContainer returned err: %v", err)
+	}
+
+	err = _engine.AssembleBuild(context.Background(), _build)
+	if err != nil {
+		t.Errorf("AssembleBuild returned err: %v", err)
+	}
+
+	_state := _engine.builds[_build.ID]
+
+	pods := _engine.Kubernetes.CoreV1().Pods("test")
+
+	pod, err := pods.Get(context.Background(), _build.ID, metav1.GetOptions{})
+	if err != nil {
+		t.Errorf("AssembleBuild did not create pod: %v", err)
+	}
+
+	if _, err := time.Parse(time.RFC3339, pod.ObjectMeta.Annotations[AnnotationHeartbeat]); err != nil {
+		t.Errorf("AssembleBuild heartbeat is invalid: %v", err)
+	}
+
+	// reset the heartbeat to wait for it to be updated
+	pod.ObjectMeta.Annotations[AnnotationHeartbeat] = "2021-01-01T00:00:00Z"
+
+	_, err = pods.Update(context.Background(), pod, metav1.UpdateOptions{})
+	if err != nil {
+		t.Errorf("unable to update pod: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		pod, err = pods.Get(context.Background(), _build.ID, metav1.GetOptions{})
+		if err == nil && pod.ObjectMeta.Annotations[AnnotationHeartbeat] != "2021-01-01T00:00:00Z" {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("heartbeat was not updated for pod %s", _build.ID)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	err = _engine.RemoveBuild(context.Background(), _build)
+	if err != nil {
+		t.Errorf("RemoveBuild returned err: %v", err)
+	}
+
+	if _state.stopHeartbeat != nil {
+		t.Errorf("RemoveBuild did not stop heartbeat")
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-vela/types/pipeline"
+
+	"github.com/sirupsen/logrus"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReattachBuild rediscovers the pod for a pipeline build still
+// running in the namespace after the worker was restarted.
+//
+// The pod is found by the name for the build, or by the pipeline
+// label, and the secret, claim and network policy for the build
+// are recovered so RemoveBuild deletes them. The heartbeat for
+// the pod is refreshed, so the reaper doesn't remove the pod
+// while the worker was restarting. Tailing the logs for each
+// container in the pod resumes after the time provided, so
+// lines already captured are not repeated.
+func (c *client) ReattachBuild(ctx context.Context, b *pipeline.Build, since time.Time) error {
+	logrus.Tracef("reattaching to build %s", b.ID)
+
+	pod, err := c.findPod(ctx, b)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("reattaching to pod %s", pod.ObjectMeta.Name)
+
+	s := c.setupState(b)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.Pod = pod
+	s.containers = nil
+	s.createdPod = true
+
+	// send API call to capture the secret for the build
+	//
+	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#SecretInterface
+	secret, err := c.Kubernetes.CoreV1().
+		Secrets(c.config.Namespace).
+		Get(ctx, pod.ObjectMeta.Name, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	// check if the build has a secret
+	if err == nil {
+		s.secret = secret
+		s.createdSecret = true
+	}
+
+	// check if the workspace is a persistent volume claim
+	if c.config.Workspace.Type == WorkspaceClaim {
+		// send API call to capture the claim for the build
+		//
+		// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#PersistentVolumeClaimInterface
+		_, err = c.Kubernetes.CoreV1().
+			PersistentVolumeClaims(c.config.Namespace).
+			Get(ctx, b.ID, metav1.GetOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+
+		s.createdClaim = err == nil
+	}
+
+	// send API call to capture the network policy for the build
+	//
+	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/networking/v1?tab=doc#NetworkPolicyInterface
+	policy, err := c.Kubernetes.NetworkingV1().
+		NetworkPolicies(c.config.Namespace).
+		Get(ctx, b.ID, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	// check if the build has a network policy
+	if err == nil {
+		s.policy = policy
+		s.createdPolicy = true
+	}
+
+	// refresh the heartbeat for the pod
+	err = c.beat(ctx, pod.ObjectMeta.Name)
+	if err != nil {
+		return err
+	}
+
+	// record the time to resume tailing the logs from
+	if !since.IsZero() {
+		for _, container := range pod.Spec.Containers {
+			s.resume[container.Name] = since
+		}
+	}
+
+	// resume updating the heartbeat for the pod until the build is removed
+	if s.stopHeartbeat == nil {
+		heartbeat, stop := context.WithCancel(context.Background())
+		s.stopHeartbeat = stop
+
+		go c.heartbeat(heartbeat, pod.ObjectMeta.Name)
+	}
+
+	return nil
+}
+
+// findPod is a helper function to capture the pod for the
+// pipeline build by name, or by the pipeline label.
+func (c *client) findPod(ctx context.Context, b *pipeline.Build) (*v1.Pod, error) {
+	// send API call to capture the pod by name
+	//
+	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#PodInterface
+	pod, err := c.Kubernetes.CoreV1().
+		Pods(c.config.Namespace).
+		Get(ctx, b.ID, metav1.GetOptions{})
+	if err == nil {
+		return pod, nil
+	}
+
+	if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	// send API call to capture the pod by the pipeline label
+	//
+	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#PodInterface
+	pods, err := c.Kubernetes.CoreV1().
+		Pods(c.config.Namespace).
+		List(ctx, metav1.ListOptions{LabelSelector: fmt.Sprintf("pipeline=%s", b.ID)})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pods.Items) == 0 {
+		return nil, fmt.Errorf("unable to find pod for build %s", b.ID)
+	}
+
+	return &pods.Items[0], nil
+}
+
+// resumeSince is a helper function to capture the time to
+// resume tailing the logs for the pipeline container
+// from after reattaching to the build.
+//
+// The time is only used once, so tailing the logs
+// again captures all of the logs for the container.
+func (s *buildState) resumeSince(ctn *pipeline.Container) time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	since := s.resume[ctn.ID]
+	delete(s.resume, ctn.ID)
+
+	return since
+}
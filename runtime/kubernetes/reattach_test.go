@@ -0,0 +1,229 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package kubernetes
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-vela/types/pipeline"
+
+	v1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestKubernetes_ReattachBuild(t *testing.T) {
+	// setup types
+	since := time.Date(2021, time.January, 1, 0, 0, 0, 500000000, time.UTC)
+
+	_terminated := podWithState(v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "Completed"}})
+
+	// setup tests
+	tests := []struct {
+		name string
+		pod  *v1.Pod
+	}{
+		{
+			name: "by name",
+			pod:  _terminated,
+		},
+		{
+			name: "by label",
+			pod: func() *v1.Pod {
+				pod := _terminated.DeepCopy()
+				pod.ObjectMeta.Name = "github-octocat-1-renamed"
+
+				return pod
+			}(),
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := NewMock(&v1.Pod{})
+		if err != nil {
+			t.Errorf("unable to create runtime engine: %v", err)
+		}
+
+		// the secret for the build is named after the pod
+		_secret := &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: test.pod.ObjectMeta.Name, Namespace: "test"},
+		}
+
+		_engine.Kubernetes = fake.NewSimpleClientset(test.pod, _secret)
+
+		var opened []*metav1.Time
+
+		_engine.getLogs = func(ctx context.Context, opts *v1.PodLogOptions) (io.ReadCloser, error) {
+			opened = append(opened, opts.SinceTime)
+
+			return ioutil.NopCloser(strings.NewReader(
+				"2021-01-01T00:00:00.100000000Z one\n" +
+					"2021-01-01T00:00:00.500000000Z two\n" +
+					"2021-01-01T00:00:00.900000000Z three\n",
+			)), nil
+		}
+
+		err = _engine.ReattachBuild(context.Background(), _steps, since)
+		if err != nil {
+			t.Errorf("ReattachBuild for %s returned err: %v", test.name, err)
+		}
+
+		_state := _engine.builds[_steps.ID]
+
+		if _state.Pod.ObjectMeta.Name != test.pod.ObjectMeta.Name || !_state.createdPod {
+			t.Errorf("ReattachBuild for %s pod is %s, want %s", test.name, _state.Pod.ObjectMeta.Name, test.pod.ObjectMeta.Name)
+		}
+
+		if _state.stopHeartbeat == nil {
+			t.Errorf("ReattachBuild for %s did not resume heartbeat", test.name)
+		}
+
+		_, err = _state.podContainer(_container)
+		if err != nil {
+			t.Errorf("ReattachBuild for %s did not index containers: %v", test.name, err)
+		}
+
+		// tail the logs for the container twice
+		want := []string{"three\n", "one\ntwo\nthree\n"}
+
+		for i, output := range want {
+			rc, err := _engine.TailContainer(context.Background(), _container)
+			if err != nil {
+				t.Errorf("TailContainer for %s returned err: %v", test.name, err)
+
+				continue
+			}
+
+			got, _ := ioutil.ReadAll(rc)
+			rc.Close()
+
+			if string(got) != output {
+				t.Errorf("TailContainer %d for %s is %q, want %q", i, test.name, got, output)
+			}
+		}
+
+		if len(opened) != 2 || opened[0] == nil || !opened[0].Time.Equal(since) || opened[1] != nil {
+			t.Errorf("TailContainer for %s since is %v, want %v then none", test.name, opened, since)
+		}
+
+		err = _engine.RemoveBuild(context.Background(), _steps)
+		if err != nil {
+			t.Errorf("RemoveBuild for %s returned err: %v", test.name, err)
+		}
+
+		// the secret for the build is removed with the build
+		_, err = _engine.Kubernetes.CoreV1().Secrets("test").
+			Get(context.Background(), _secret.ObjectMeta.Name, metav1.GetOptions{})
+		if err == nil {
+			t.Errorf("RemoveBuild for %s did not remove secret", test.name)
+		}
+	}
+}
+
+func TestKubernetes_ReattachBuild_RemoveBuild(t *testing.T) {
+	// setup types
+	stale := "2021-01-01T00:00:00Z"
+
+	meta := metav1.ObjectMeta{Name: _steps.ID, Namespace: "test", Labels: map[string]string{"pipeline": _steps.ID}}
+
+	// setup tests
+	tests := []struct {
+		name   string
+		opts   []ClientOpt
+		object runtime.Object
+		get    func(*client) error
+	}{
+		{
+			name:   "secret",
+			object: &v1.Secret{ObjectMeta: meta},
+			get: func(c *client) error {
+				_, err := c.Kubernetes.CoreV1().Secrets("test").
+					Get(context.Background(), _steps.ID, metav1.GetOptions{})
+
+				return err
+			},
+		},
+		{
+			name:   "claim",
+			opts:   []ClientOpt{WithWorkspaceVolume(WorkspaceClaim, "", "1Gi")},
+			object: &v1.PersistentVolumeClaim{ObjectMeta: meta},
+			get: func(c *client) error {
+				_, err := c.Kubernetes.CoreV1().PersistentVolumeClaims("test").
+					Get(context.Background(), _steps.ID, metav1.GetOptions{})
+
+				return err
+			},
+		},
+		{
+			name:   "network policy",
+			object: &netv1.NetworkPolicy{ObjectMeta: meta},
+			get: func(c *client) error {
+				_, err := c.Kubernetes.NetworkingV1().NetworkPolicies("test").
+					Get(context.Background(), _steps.ID, metav1.GetOptions{})
+
+				return err
+			},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := NewMock(&v1.Pod{}, test.opts...)
+		if err != nil {
+			t.Errorf("unable to create runtime engine: %v", err)
+		}
+
+		_pod := podWithState(v1.ContainerState{Running: &v1.ContainerStateRunning{}})
+		_pod.ObjectMeta.Annotations = map[string]string{AnnotationHeartbeat: stale}
+
+		_engine.Kubernetes = fake.NewSimpleClientset(_pod, test.object)
+
+		err = _engine.ReattachBuild(context.Background(), _steps, time.Time{})
+		if err != nil {
+			t.Errorf("ReattachBuild for %s returned err: %v", test.name, err)
+		}
+
+		pod, err := _engine.Kubernetes.CoreV1().Pods("test").
+			Get(context.Background(), _pod.ObjectMeta.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Errorf("unable to get pod for %s: %v", test.name, err)
+		} else if pod.ObjectMeta.Annotations[AnnotationHeartbeat] == stale {
+			t.Errorf("ReattachBuild for %s did not refresh heartbeat", test.name)
+		}
+
+		err = _engine.RemoveBuild(context.Background(), _steps)
+		if err != nil {
+			t.Errorf("RemoveBuild for %s returned err: %v", test.name, err)
+		}
+
+		// the resource for the build is removed with the build
+		err = test.get(_engine)
+		if !errors.IsNotFound(err) {
+			t.Errorf("RemoveBuild for %s did not remove resource: %v", test.name, err)
+		}
+	}
+}
+
+func TestKubernetes_ReattachBuild_NotFound(t *testing.T) {
+	// setup types
+	_engine, err := NewMock(_pod.DeepCopy())
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	err = _engine.ReattachBuild(context.Background(), &pipeline.Build{ID: "github-octocat-2"}, time.Time{})
+	if err == nil {
+		t.Errorf("ReattachBuild should have returned err")
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-vela/pkg-runtime/internal/secret"
+	"github.com/go-vela/types/pipeline"
+
+	"github.com/sirupsen/logrus"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// redacted represents the value displayed in
+// place of a secret when inspecting the build.
+const redacted = "***"
+
+// secretKeyInvalid represents the characters not
+// permitted in the key for a Kubernetes secret.
+var secretKeyInvalid = regexp.MustCompile(`[^-._a-zA-Z0-9]`)
+
+// secretKey is a helper function to add the value for the
+// environment variable to the secret for the build, and
+// return the key for the value in the secret.
+func (s *buildState) secretKey(ctn *pipeline.Container, name, value string) string {
+	// check if the secret for the build is created
+	if s.secret == nil {
+		// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#Secret
+		s.secret = &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   s.Pod.ObjectMeta.Name,
+				Labels: map[string]string{"pipeline": s.Pod.ObjectMeta.Name},
+			},
+			Type: v1.SecretTypeOpaque,
+			Data: make(map[string][]byte),
+		}
+	}
+
+	key := secretKeyInvalid.ReplaceAllString(fmt.Sprintf("%s.%s", ctn.ID, name), "_")
+
+	s.secret.Data[key] = []byte(value)
+
+	return key
+}
+
+// secretEnv is a helper function to create the environment
+// variable referencing the key for the value in the secret.
+func (s *buildState) secretEnv(ctn *pipeline.Container, name, value string) v1.EnvVar {
+	key := s.secretKey(ctn, name, value)
+
+	// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#EnvVar
+	return v1.EnvVar{
+		Name: name,
+		ValueFrom: &v1.EnvVarSource{
+			SecretKeyRef: &v1.SecretKeySelector{
+				LocalObjectReference: v1.LocalObjectReference{Name: s.secret.ObjectMeta.Name},
+				Key:                  key,
+			},
+		},
+	}
+}
+
+// secretVolume is a helper function to mount the files for the
+// secrets for the container from the secret for the build.
+//
+// Kubernetes stores the files for a secret volume in
+// a tmpfs, so the files are removed with the pod.
+func (s *buildState) secretVolume(ctn *pipeline.Container, container *v1.Container, files map[string]string) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+
+	// sort the names to keep the pod spec consistent
+	sort.Strings(names)
+
+	items := []v1.KeyToPath{}
+
+	// iterate through each file for the secrets
+	for _, name := range names {
+		// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#KeyToPath
+		items = append(items, v1.KeyToPath{
+			Key:  s.secretKey(ctn, name, files[name]),
+			Path: name,
+		})
+	}
+
+	mode := int32(0444)
+	volume := fmt.Sprintf("secrets-%d", s.containers[ctn.ID])
+
+	// add the volume for the secret to the pod spec
+	//
+	// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#SecretVolumeSource
+	s.Pod.Spec.Volumes = append(s.Pod.Spec.Volumes, v1.Volume{
+		Name: volume,
+		VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{
+				SecretName:  s.secret.ObjectMeta.Name,
+				Items:       items,
+				DefaultMode: &mode,
+			},
+		},
+	})
+
+	// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#VolumeMount
+	container.VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{
+		Name:      volume,
+		MountPath: secret.Mount,
+		ReadOnly:  true,
+	})
+}
+
+// createSecret is a helper function to create the
+// secret for the build before the pod is created.
+func (c *client) createSecret(ctx context.Context, s *buildState) error {
+	// check if the build has any secrets
+	if s.secret == nil {
+		return nil
+	}
+
+	// If the api call to create the secret fails, the secret
+	// might partially exist. So, set this first to make
+	// sure all remnants get deleted.
+	s.createdSecret = true
+
+	logrus.Infof("creating secret %s", s.secret.ObjectMeta.Name)
+	// send API call to create the secret
+	//
+	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#SecretInterface
+	_, err := c.Kubernetes.CoreV1().
+		Secrets(c.config.Namespace).
+		Create(ctx, s.secret, metav1.CreateOptions{})
+
+	return err
+}
+
+// ownSecret is a helper function to set the pod as the owner
+// of the secret for the build, so the secret is deleted by
+// Kubernetes with the pod if the build is never removed.
+func (c *client) ownSecret(ctx context.Context, s *buildState, pod *v1.Pod) error {
+	// check if the secret for the build was created
+	if !s.createdSecret {
+		return nil
+	}
+
+	// https://pkg.go.dev/k8s.io/apimachinery/pkg/apis/meta/v1?tab=doc#OwnerReference
+	s.secret.ObjectMeta.OwnerReferences = []metav1.OwnerReference{
+		{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Name:       pod.ObjectMeta.Name,
+			UID:        pod.ObjectMeta.UID,
+		},
+	}
+
+	// send API call to update the secret
+	//
+	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#SecretInterface
+	_, err := c.Kubernetes.CoreV1().
+		Secrets(c.config.Namespace).
+		Update(ctx, s.secret, metav1.UpdateOptions{})
+
+	return err
+}
+
+// removeSecret is a helper function to
+// delete the secret for the build.
+func (c *client) removeSecret(ctx context.Context, s *buildState) error {
+	// check if the secret for the build was created
+	if !s.createdSecret {
+		s.secret = nil
+
+		return nil
+	}
+
+	logrus.Infof("removing secret %s", s.secret.ObjectMeta.Name)
+	// send API call to delete the secret
+	//
+	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#SecretInterface
+	err := c.Kubernetes.CoreV1().
+		Secrets(c.config.Namespace).
+		Delete(ctx, s.secret.ObjectMeta.Name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	s.secret = nil
+	s.createdSecret = false
+
+	return nil
+}
+
+// redactPod is a helper function to create a copy of the
+// pod with the values for any secrets in the environment
+// for the containers replaced for inspecting the build.
+func (s *buildState) redactPod() *v1.Pod {
+	pod := s.Pod.DeepCopy()
+
+	// check if the build has any secrets
+	if s.secret == nil {
+		return pod
+	}
+
+	// iterate through each container in the pod
+	for i := range pod.Spec.Containers {
+		env := pod.Spec.Containers[i].Env
+
+		// iterate through each environment variable for the container
+		for j := range env {
+			// iterate through each value in the secret for the build
+			for _, value := range s.secret.Data {
+				if len(value) > 0 && strings.Contains(env[j].Value, string(value)) {
+					env[j].Value = strings.ReplaceAll(env[j].Value, string(value), redacted)
+				}
+			}
+		}
+	}
+
+	return pod
+}
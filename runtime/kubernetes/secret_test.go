@@ -0,0 +1,250 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-vela/types/pipeline"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestKubernetes_BuildSecret(t *testing.T) {
+	// setup types
+	_step := &pipeline.Container{
+		ID:          "step_github_octocat_1_echo",
+		Directory:   "/vela/src/github.com/octocat/helloworld",
+		Environment: map[string]string{"FOO": "bar", "NPM_TOKEN": "t0k3n", "TOKEN": "sup3rs3cr3t"},
+		Image:       "alpine:latest",
+		Name:        "echo",
+		Number:      2,
+		Pull:        "not_present",
+		Secrets: pipeline.StepSecretSlice{
+			{Source: "token", Target: "token"},
+			{Source: "npm_token", Target: "npm_token"},
+		},
+	}
+
+	_build := &pipeline.Build{
+		ID:      "github-octocat-1",
+		Version: "1",
+		Steps:   pipeline.ContainerSlice{_step},
+	}
+
+	_engine, err := NewMock(&v1.Pod{})
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	err = _engine.SetupBuild(context.Background(), _build)
+	if err != nil {
+		t.Errorf("SetupBuild returned err: %v", err)
+	}
+
+	err = _engine.SetupContainer(context.Background(), _step)
+	if err != nil {
+		t.Errorf("SetupContainer returned err: %v", err)
+	}
+
+	err = _engine.AssembleBuild(context.Background(), _build)
+	if err != nil {
+		t.Errorf("AssembleBuild returned err: %v", err)
+	}
+
+	_state := _engine.builds[_build.ID]
+
+	// check the environment for the container
+	for _, env := range _state.Pod.Spec.Containers[0].Env {
+		switch env.Name {
+		case "FOO":
+			if env.Value != "bar" {
+				t.Errorf("AssembleBuild FOO is %s, want bar", env.Value)
+			}
+		case "TOKEN":
+			if len(env.Value) > 0 || env.ValueFrom == nil || env.ValueFrom.SecretKeyRef == nil {
+				t.Errorf("AssembleBuild TOKEN is %+v, want secret reference", env)
+
+				continue
+			}
+
+			if env.ValueFrom.SecretKeyRef.Name != _build.ID {
+				t.Errorf("AssembleBuild TOKEN secret is %s, want %s", env.ValueFrom.SecretKeyRef.Name, _build.ID)
+			}
+		}
+	}
+
+	secrets := _engine.Kubernetes.CoreV1().Secrets(_engine.config.Namespace)
+
+	secret, err := secrets.Get(context.Background(), _build.ID, metav1.GetOptions{})
+	if err != nil {
+		t.Errorf("AssembleBuild did not create secret: %v", err)
+	}
+
+	if got := string(secret.Data["step_github_octocat_1_echo.TOKEN"]); got != "sup3rs3cr3t" {
+		t.Errorf("AssembleBuild secret value is %s, want sup3rs3cr3t", got)
+	}
+
+	if len(secret.OwnerReferences) != 1 || secret.OwnerReferences[0].Name != _build.ID {
+		t.Errorf("AssembleBuild secret owner is %+v, want pod %s", secret.OwnerReferences, _build.ID)
+	}
+
+	// simulate a literal value for a secret in the pod
+	_state.Pod.Spec.Containers[0].Env = append(_state.Pod.Spec.Containers[0].Env,
+		v1.EnvVar{Name: "AUTH", Value: "Bearer sup3rs3cr3t"},
+	)
+
+	output, err := _engine.InspectBuild(context.Background(), _build)
+	if err != nil {
+		t.Errorf("InspectBuild returned err: %v", err)
+	}
+
+	if strings.Contains(string(output), "sup3rs3cr3t") {
+		t.Errorf("InspectBuild leaked secret: %s", output)
+	}
+
+	if !strings.Contains(string(output), "Bearer ***") {
+		t.Errorf("InspectBuild did not redact secret: %s", output)
+	}
+
+	err = _engine.RemoveBuild(context.Background(), _build)
+	if err != nil {
+		t.Errorf("RemoveBuild returned err: %v", err)
+	}
+
+	_, err = secrets.Get(context.Background(), _build.ID, metav1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("RemoveBuild did not remove secret: %v", err)
+	}
+
+	if _state.createdSecret {
+		t.Errorf("RemoveBuild did not reset secret")
+	}
+}
+
+func TestKubernetes_BuildSecret_None(t *testing.T) {
+	// setup types
+	_engine, err := NewMock(_pod.DeepCopy())
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	_state := _engine.builds[_pod.ObjectMeta.Name]
+
+	err = _engine.createSecret(context.Background(), _state)
+	if err != nil {
+		t.Errorf("createSecret returned err: %v", err)
+	}
+
+	if _state.createdSecret {
+		t.Errorf("createSecret created secret without any secrets")
+	}
+
+	err = _engine.removeSecret(context.Background(), _state)
+	if err != nil {
+		t.Errorf("removeSecret returned err: %v", err)
+	}
+}
+
+func TestKubernetes_BuildSecret_Files(t *testing.T) {
+	// setup types
+	_step := &pipeline.Container{
+		ID:          "step_github_octocat_1_echo",
+		Directory:   "/vela/src/github.com/octocat/helloworld",
+		Environment: map[string]string{"FOO": "bar", "TOKEN": "sup3rs3cr3t"},
+		Image:       "alpine:latest",
+		Name:        "echo",
+		Number:      2,
+		Pull:        "not_present",
+		Secrets:     pipeline.StepSecretSlice{{Source: "token", Target: "token"}},
+	}
+
+	_build := &pipeline.Build{
+		ID:      "github-octocat-1",
+		Version: "1",
+		Steps:   pipeline.ContainerSlice{_step},
+	}
+
+	_engine, err := NewMock(&v1.Pod{}, WithSecretFiles([]string{"token"}))
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	err = _engine.SetupBuild(context.Background(), _build)
+	if err != nil {
+		t.Errorf("SetupBuild returned err: %v", err)
+	}
+
+	err = _engine.SetupContainer(context.Background(), _step)
+	if err != nil {
+		t.Errorf("SetupContainer returned err: %v", err)
+	}
+
+	err = _engine.AssembleBuild(context.Background(), _build)
+	if err != nil {
+		t.Errorf("AssembleBuild returned err: %v", err)
+	}
+
+	_state := _engine.builds[_build.ID]
+
+	container := _state.Pod.Spec.Containers[0]
+
+	// check the environment for the container
+	for _, env := range container.Env {
+		switch env.Name {
+		case "TOKEN":
+			t.Errorf("AssembleBuild added secret to environment: %+v", env)
+		case "TOKEN_FILE":
+			if env.Value != "/vela/secrets/TOKEN" {
+				t.Errorf("AssembleBuild TOKEN_FILE is %s, want /vela/secrets/TOKEN", env.Value)
+			}
+		case "NPM_TOKEN":
+			if env.ValueFrom == nil || env.ValueFrom.SecretKeyRef == nil {
+				t.Errorf("AssembleBuild NPM_TOKEN is %+v, want secret reference", env)
+			}
+		case "NPM_TOKEN_FILE":
+			t.Errorf("AssembleBuild delivered NPM_TOKEN as a file: %+v", env)
+		}
+	}
+
+	mode := int32(0444)
+
+	wantVolume := v1.Volume{
+		Name: "secrets-0",
+		VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{
+				SecretName:  _build.ID,
+				Items:       []v1.KeyToPath{{Key: "step_github_octocat_1_echo.TOKEN", Path: "TOKEN"}},
+				DefaultMode: &mode,
+			},
+		},
+	}
+
+	if len(_state.Pod.Spec.Volumes) != 1 || !reflect.DeepEqual(_state.Pod.Spec.Volumes[0], wantVolume) {
+		t.Errorf("AssembleBuild volumes is %+v, want %+v", _state.Pod.Spec.Volumes, wantVolume)
+	}
+
+	wantMount := v1.VolumeMount{Name: "secrets-0", MountPath: "/vela/secrets", ReadOnly: true}
+
+	if len(container.VolumeMounts) != 1 || !reflect.DeepEqual(container.VolumeMounts[0], wantMount) {
+		t.Errorf("AssembleBuild volume mounts is %+v, want %+v", container.VolumeMounts, wantMount)
+	}
+
+	secret, err := _engine.Kubernetes.CoreV1().
+		Secrets(_engine.config.Namespace).
+		Get(context.Background(), _build.ID, metav1.GetOptions{})
+	if err != nil {
+		t.Errorf("AssembleBuild did not create secret: %v", err)
+	}
+
+	if got := string(secret.Data["step_github_octocat_1_echo.TOKEN"]); got != "sup3rs3cr3t" {
+		t.Errorf("AssembleBuild secret value is %s, want sup3rs3cr3t", got)
+	}
+}
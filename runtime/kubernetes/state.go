@@ -0,0 +1,159 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-vela/types/pipeline"
+
+	v1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+)
+
+// buildState represents the state for a pipeline
+// build running in a pod with the Kubernetes client.
+//
+// The Kubernetes client runs many builds at once,
+// so the state is tracked separately for each build.
+type buildState struct {
+	// mutex guards the state for the build
+	mutex sync.Mutex
+	// https://pkg.go.dev/k8s.io/api/core/v1#Pod
+	Pod *v1.Pod
+	// commonVolumeMounts includes workspace mount and any global host mounts (VELA_RUNTIME_VOLUMES)
+	commonVolumeMounts []v1.VolumeMount
+	// containers indexes the containers in the pod spec by name
+	containers map[string]int
+	// indicates when the pod has been created in kubernetes
+	createdPod bool
+	// indicates when the claim for the workspace has been created in kubernetes
+	createdClaim bool
+	// https://pkg.go.dev/k8s.io/api/core/v1#Secret
+	secret *v1.Secret
+	// indicates when the secret for the build has been created in kubernetes
+	createdSecret bool
+	// https://pkg.go.dev/k8s.io/api/networking/v1#NetworkPolicy
+	policy *netv1.NetworkPolicy
+	// indicates when the network policy for the build has been created in kubernetes
+	createdPolicy bool
+	// stopHeartbeat stops updating the heartbeat on the pod for the build
+	stopHeartbeat context.CancelFunc
+	// resume tracks the time to resume tailing the logs for containers reattached to
+	resume map[string]time.Time
+}
+
+// newBuildState is a helper function to create
+// the state for a pipeline build with the pod.
+func newBuildState(pod *v1.Pod) *buildState {
+	return &buildState{
+		Pod:    pod,
+		resume: make(map[string]time.Time),
+	}
+}
+
+// name is a helper function to capture
+// the name of the pod for the build.
+func (s *buildState) name() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.Pod.ObjectMeta.Name
+}
+
+// setupState is a helper function to capture the state for
+// the pipeline build, creating it when the build is new, and
+// record the build for each pipeline container in the build.
+func (c *client) setupState(b *pipeline.Build) *buildState {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	s, ok := c.builds[b.ID]
+	if !ok {
+		s = newBuildState(&v1.Pod{})
+
+		c.builds[b.ID] = s
+	}
+
+	// record the build for each container in the build
+	for _, ctn := range buildContainers(b) {
+		c.containerBuilds[ctn.ID] = b.ID
+	}
+
+	return s
+}
+
+// buildState is a helper function to capture
+// the state for the pipeline build.
+func (c *client) buildState(b *pipeline.Build) (*buildState, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	s, ok := c.builds[b.ID]
+	if !ok {
+		return nil, fmt.Errorf("build %s not found", b.ID)
+	}
+
+	return s, nil
+}
+
+// containerState is a helper function to capture the
+// state for the build of the pipeline container.
+func (c *client) containerState(ctn *pipeline.Container) (*buildState, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// check if the build for the container was recorded
+	if id, ok := c.containerBuilds[ctn.ID]; ok {
+		if s, ok := c.builds[id]; ok {
+			return s, nil
+		}
+	}
+
+	return nil, fmt.Errorf("build for container %s not found", ctn.ID)
+}
+
+// removeState is a helper function to remove the state
+// for the pipeline build and the containers in the build.
+func (c *client) removeState(b *pipeline.Build) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.builds, b.ID)
+
+	// remove the build for each container in the build
+	for ctn, id := range c.containerBuilds {
+		if id == b.ID {
+			delete(c.containerBuilds, ctn)
+		}
+	}
+}
+
+// buildContainers is a helper function to capture
+// all of the pipeline containers in the build.
+func buildContainers(b *pipeline.Build) pipeline.ContainerSlice {
+	containers := pipeline.ContainerSlice{}
+
+	containers = append(containers, b.Services...)
+
+	for _, stage := range b.Stages {
+		containers = append(containers, stage.Steps...)
+	}
+
+	containers = append(containers, b.Steps...)
+
+	for _, secret := range b.Secrets {
+		if secret.Origin.Empty() {
+			continue
+		}
+
+		containers = append(containers, secret.Origin)
+	}
+
+	return containers
+}
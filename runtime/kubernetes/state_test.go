@@ -0,0 +1,262 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-vela/types/pipeline"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestKubernetes_ConcurrentBuilds(t *testing.T) {
+	// setup types
+	builds := 8
+
+	_engine, err := NewMock(&v1.Pod{}, WithLogLimits("1KB", 0))
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	_engine.getLogs = func(ctx context.Context, opts *v1.PodLogOptions) (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader(
+			fmt.Sprintf("2021-01-01T00:00:00.000000000Z hello from %s\n", opts.Container),
+		)), nil
+	}
+
+	pods := _engine.Kubernetes.CoreV1().Pods(_engine.config.Namespace)
+
+	var wg sync.WaitGroup
+
+	// run tests
+	for i := 1; i <= builds; i++ {
+		_step := &pipeline.Container{
+			ID:          fmt.Sprintf("step_github_octocat_%d_echo", i),
+			Directory:   "/vela/src/github.com/octocat/helloworld",
+			Environment: map[string]string{"BUILD": fmt.Sprint(i)},
+			Image:       "alpine:latest",
+			Name:        "echo",
+			Number:      2,
+			Pull:        "not_present",
+		}
+
+		_build := &pipeline.Build{
+			ID:      fmt.Sprintf("github-octocat-%d", i),
+			Version: "1",
+			Steps:   pipeline.ContainerSlice{_step},
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			ctx := context.Background()
+
+			err := _engine.SetupBuild(ctx, _build)
+			if err != nil {
+				t.Errorf("SetupBuild for %s returned err: %v", _build.ID, err)
+
+				return
+			}
+
+			err = _engine.CreateVolume(ctx, _build)
+			if err != nil {
+				t.Errorf("CreateVolume for %s returned err: %v", _build.ID, err)
+			}
+
+			err = _engine.CreateNetwork(ctx, _build)
+			if err != nil {
+				t.Errorf("CreateNetwork for %s returned err: %v", _build.ID, err)
+			}
+
+			err = _engine.SetupContainer(ctx, _step)
+			if err != nil {
+				t.Errorf("SetupContainer for %s returned err: %v", _build.ID, err)
+			}
+
+			_, err = _engine.InspectBuild(ctx, _build)
+			if err != nil {
+				t.Errorf("InspectBuild for %s returned err: %v", _build.ID, err)
+			}
+
+			err = _engine.AssembleBuild(ctx, _build)
+			if err != nil {
+				t.Errorf("AssembleBuild for %s returned err: %v", _build.ID, err)
+			}
+
+			err = _engine.RunContainer(ctx, _step, _build)
+			if err != nil {
+				t.Errorf("RunContainer for %s returned err: %v", _build.ID, err)
+			}
+
+			pod, err := pods.Get(ctx, _build.ID, metav1.GetOptions{})
+			if err != nil {
+				t.Errorf("AssembleBuild for %s did not create pod: %v", _build.ID, err)
+
+				return
+			}
+
+			// check the pod only contains the container for the build
+			if len(pod.Spec.Containers) != 1 || pod.Spec.Containers[0].Name != _step.ID {
+				t.Errorf("AssembleBuild for %s containers is %+v", _build.ID, pod.Spec.Containers)
+			}
+
+			if len(pod.Spec.Volumes) != 1 || pod.Spec.Volumes[0].Name != _build.ID {
+				t.Errorf("AssembleBuild for %s volumes is %+v", _build.ID, pod.Spec.Volumes)
+			}
+
+			// simulate the container completing in the pod
+			pod.Status.ContainerStatuses = []v1.ContainerStatus{
+				{
+					Name:  _step.ID,
+					Image: pod.Spec.Containers[0].Image,
+					State: v1.ContainerState{
+						Terminated: &v1.ContainerStateTerminated{Reason: "Completed"},
+					},
+				},
+			}
+
+			_, err = pods.UpdateStatus(ctx, pod, metav1.UpdateOptions{})
+			if err != nil {
+				t.Errorf("unable to update pod %s: %v", _build.ID, err)
+			}
+
+			rc, err := _engine.TailContainer(ctx, _step)
+			if err != nil {
+				t.Errorf("TailContainer for %s returned err: %v", _build.ID, err)
+			} else {
+				got, err := ioutil.ReadAll(rc)
+				if err != nil {
+					t.Errorf("TailContainer for %s returned err: %v", _build.ID, err)
+				}
+
+				want := fmt.Sprintf("hello from %s\n", _step.ID)
+				if string(got) != want {
+					t.Errorf("TailContainer for %s is %q, want %q", _build.ID, got, want)
+				}
+			}
+
+			err = _engine.WaitContainer(ctx, _step)
+			if err != nil {
+				t.Errorf("WaitContainer for %s returned err: %v", _build.ID, err)
+			}
+
+			err = _engine.RemoveNetwork(ctx, _build)
+			if err != nil {
+				t.Errorf("RemoveNetwork for %s returned err: %v", _build.ID, err)
+			}
+
+			err = _engine.RemoveVolume(ctx, _build)
+			if err != nil {
+				t.Errorf("RemoveVolume for %s returned err: %v", _build.ID, err)
+			}
+
+			err = _engine.RemoveBuild(ctx, _build)
+			if err != nil {
+				t.Errorf("RemoveBuild for %s returned err: %v", _build.ID, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	list, err := pods.List(context.Background(), metav1.ListOptions{LabelSelector: "pipeline"})
+	if err != nil {
+		t.Errorf("unable to list pods: %v", err)
+	}
+
+	if len(list.Items) > 0 {
+		t.Errorf("RemoveBuild did not remove %d pods", len(list.Items))
+	}
+
+	for i := 1; i <= builds; i++ {
+		id := fmt.Sprintf("github-octocat-%d", i)
+
+		if _, ok := _engine.builds[id]; ok {
+			t.Errorf("RemoveBuild did not remove state for %s", id)
+		}
+	}
+}
+
+func TestKubernetes_ContainerState(t *testing.T) {
+	// setup types
+	_engine, err := NewMock(&v1.Pod{})
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	_one := &pipeline.Build{ID: "github-octocat-1", Steps: pipeline.ContainerSlice{{ID: "step_github_octocat_1_echo"}}}
+	_two := &pipeline.Build{ID: "github-octocat-2", Steps: pipeline.ContainerSlice{{ID: "step_github_octocat_2_echo"}}}
+
+	for _, b := range []*pipeline.Build{_one, _two} {
+		err = _engine.SetupBuild(context.Background(), b)
+		if err != nil {
+			t.Errorf("SetupBuild for %s returned err: %v", b.ID, err)
+		}
+	}
+
+	// setup tests
+	tests := []struct {
+		failure   bool
+		container *pipeline.Container
+		want      *buildState
+	}{
+		{
+			failure:   false,
+			container: _one.Steps[0],
+			want:      _engine.builds[_one.ID],
+		},
+		{
+			failure:   false,
+			container: _two.Steps[0],
+			want:      _engine.builds[_two.ID],
+		},
+		{
+			failure:   true,
+			container: &pipeline.Container{ID: "step_github_octocat_3_echo"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got, err := _engine.containerState(test.container)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("containerState for %s should have returned err", test.container.ID)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("containerState for %s returned err: %v", test.container.ID, err)
+		}
+
+		if got != test.want {
+			t.Errorf("containerState for %s is the state for %s", test.container.ID, got.Pod.ObjectMeta.Name)
+		}
+	}
+
+	_single, err := NewMock(_pod.DeepCopy())
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	// the container isn't matched to the only build running
+	_, err = _single.containerState(&pipeline.Container{ID: "step_github_octocat_3_echo"})
+	if err == nil {
+		t.Errorf("containerState for a single build should have returned err")
+	}
+}
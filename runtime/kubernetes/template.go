@@ -0,0 +1,272 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package kubernetes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// podTemplate represents the customizations from a pod
+// template file merged into the pod for every build.
+//
+// Only the fields below are permitted in the template. The
+// name, namespace, containers, volumes and restart policy
+// for the pod are always generated by the runtime.
+//
+// The precedence for merging the template into the pod is:
+//
+//   * the pipeline and worker labels and the heartbeat annotation from the runtime can't be set by the template
+//   * the labels, annotations and node selector from the template are added to the pod
+//   * the tolerations, priority class and service account from the template are used for the pod
+//   * the affinity from the template is used for the pod, with the node affinity
+//     for the platform of the build required in addition to any from the template
+type podTemplate struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Metadata struct {
+		Labels      map[string]string `json:"labels,omitempty"`
+		Annotations map[string]string `json:"annotations,omitempty"`
+	} `json:"metadata,omitempty"`
+
+	Spec struct {
+		NodeSelector                 map[string]string `json:"nodeSelector,omitempty"`
+		Tolerations                  []v1.Toleration   `json:"tolerations,omitempty"`
+		Affinity                     *v1.Affinity      `json:"affinity,omitempty"`
+		PriorityClassName            string            `json:"priorityClassName,omitempty"`
+		ServiceAccountName           string            `json:"serviceAccountName,omitempty"`
+		AutomountServiceAccountToken *bool             `json:"automountServiceAccountToken,omitempty"`
+	} `json:"spec,omitempty"`
+}
+
+// loadPodTemplate is a helper function to read
+// and validate the pod template from the file.
+func loadPodTemplate(file string) (*podTemplate, error) {
+	// read the pod template from the file
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read pod template %s: %w", file, err)
+	}
+
+	// convert the pod template from YAML to JSON
+	//
+	// https://pkg.go.dev/k8s.io/apimachinery/pkg/util/yaml?tab=doc#ToJSON
+	data, err = yaml.ToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse pod template %s: %w", file, err)
+	}
+
+	t := new(podTemplate)
+
+	// decode the pod template rejecting any fields not permitted
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	err = decoder.Decode(t)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pod template %s: %w", file, err)
+	}
+
+	err = t.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid pod template %s: %w", file, err)
+	}
+
+	return t, nil
+}
+
+// Validate verifies the pod template is valid
+// for merging into the pod for a build.
+func (t *podTemplate) Validate() error {
+	// check if the template is for a pod
+	if len(t.Kind) > 0 && t.Kind != "Pod" {
+		return fmt.Errorf("kind %s is not Pod", t.Kind)
+	}
+
+	// check if the template is for the core API version
+	if len(t.APIVersion) > 0 && t.APIVersion != "v1" {
+		return fmt.Errorf("apiVersion %s is not v1", t.APIVersion)
+	}
+
+	// check if a reserved label is set by the template
+	for _, label := range []string{"pipeline", LabelWorker} {
+		if _, ok := t.Metadata.Labels[label]; ok {
+			return fmt.Errorf("label %s is reserved for the runtime", label)
+		}
+	}
+
+	// check if the heartbeat annotation is set by the template
+	if _, ok := t.Metadata.Annotations[AnnotationHeartbeat]; ok {
+		return fmt.Errorf("annotation %s is reserved for the runtime", AnnotationHeartbeat)
+	}
+
+	err := validateLabels("label", t.Metadata.Labels)
+	if err != nil {
+		return err
+	}
+
+	err = validateLabels("node selector", t.Spec.NodeSelector)
+	if err != nil {
+		return err
+	}
+
+	// iterate through each annotation in the template
+	for key := range t.Metadata.Annotations {
+		// https://pkg.go.dev/k8s.io/apimachinery/pkg/util/validation?tab=doc#IsQualifiedName
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid annotation %s: %s", key, strings.Join(errs, "; "))
+		}
+	}
+
+	// iterate through each toleration in the template
+	for _, toleration := range t.Spec.Tolerations {
+		err = validateToleration(toleration)
+		if err != nil {
+			return err
+		}
+	}
+
+	// check if the priority class is a valid name
+	//
+	// https://pkg.go.dev/k8s.io/apimachinery/pkg/util/validation?tab=doc#IsDNS1123Subdomain
+	if len(t.Spec.PriorityClassName) > 0 {
+		if errs := validation.IsDNS1123Subdomain(t.Spec.PriorityClassName); len(errs) > 0 {
+			return fmt.Errorf("invalid priorityClassName %s: %s", t.Spec.PriorityClassName, strings.Join(errs, "; "))
+		}
+	}
+
+	// check if the service account is a valid name
+	//
+	// https://pkg.go.dev/k8s.io/apimachinery/pkg/util/validation?tab=doc#IsDNS1123Subdomain
+	if len(t.Spec.ServiceAccountName) > 0 {
+		if errs := validation.IsDNS1123Subdomain(t.Spec.ServiceAccountName); len(errs) > 0 {
+			return fmt.Errorf("invalid serviceAccountName %s: %s", t.Spec.ServiceAccountName, strings.Join(errs, "; "))
+		}
+	}
+
+	return nil
+}
+
+// Apply merges the pod template into the pod for a build.
+func (t *podTemplate) Apply(pod *v1.Pod) {
+	// check if the pod has labels
+	if pod.ObjectMeta.Labels == nil {
+		pod.ObjectMeta.Labels = make(map[string]string)
+	}
+
+	// add the labels from the template without
+	// replacing any labels from the runtime
+	for key, value := range t.Metadata.Labels {
+		if _, ok := pod.ObjectMeta.Labels[key]; !ok {
+			pod.ObjectMeta.Labels[key] = value
+		}
+	}
+
+	pod.ObjectMeta.Annotations = copyMap(pod.ObjectMeta.Annotations, t.Metadata.Annotations)
+	pod.Spec.NodeSelector = copyMap(pod.Spec.NodeSelector, t.Spec.NodeSelector)
+
+	// check if tolerations are provided
+	if len(t.Spec.Tolerations) > 0 {
+		pod.Spec.Tolerations = make([]v1.Toleration, len(t.Spec.Tolerations))
+
+		for i := range t.Spec.Tolerations {
+			t.Spec.Tolerations[i].DeepCopyInto(&pod.Spec.Tolerations[i])
+		}
+	}
+
+	// check if affinity is provided
+	if t.Spec.Affinity != nil {
+		pod.Spec.Affinity = t.Spec.Affinity.DeepCopy()
+	}
+
+	// check if a priority class is provided
+	if len(t.Spec.PriorityClassName) > 0 {
+		pod.Spec.PriorityClassName = t.Spec.PriorityClassName
+	}
+
+	// check if a service account is provided
+	if len(t.Spec.ServiceAccountName) > 0 {
+		pod.Spec.ServiceAccountName = t.Spec.ServiceAccountName
+	}
+
+	// check if mounting the service account token is provided
+	if t.Spec.AutomountServiceAccountToken != nil {
+		automount := *t.Spec.AutomountServiceAccountToken
+
+		pod.Spec.AutomountServiceAccountToken = &automount
+	}
+}
+
+// validateLabels is a helper function to verify
+// the keys and values for a set of labels.
+func validateLabels(kind string, labels map[string]string) error {
+	// iterate through each label provided
+	for key, value := range labels {
+		// https://pkg.go.dev/k8s.io/apimachinery/pkg/util/validation?tab=doc#IsQualifiedName
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid %s %s: %s", kind, key, strings.Join(errs, "; "))
+		}
+
+		// https://pkg.go.dev/k8s.io/apimachinery/pkg/util/validation?tab=doc#IsValidLabelValue
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return fmt.Errorf("invalid %s %s value %s: %s", kind, key, value, strings.Join(errs, "; "))
+		}
+	}
+
+	return nil
+}
+
+// validateToleration is a helper function to
+// verify the operator and effect for a toleration.
+func validateToleration(toleration v1.Toleration) error {
+	// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#TolerationOperator
+	switch toleration.Operator {
+	case "", v1.TolerationOpEqual:
+	case v1.TolerationOpExists:
+		// check if a value is provided for the toleration
+		if len(toleration.Value) > 0 {
+			return fmt.Errorf("invalid toleration %s: value must be empty for operator Exists", toleration.Key)
+		}
+	default:
+		return fmt.Errorf("invalid toleration %s: unsupported operator %s", toleration.Key, toleration.Operator)
+	}
+
+	// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#TaintEffect
+	switch toleration.Effect {
+	case "", v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute:
+	default:
+		return fmt.Errorf("invalid toleration %s: unsupported effect %s", toleration.Key, toleration.Effect)
+	}
+
+	return nil
+}
+
+// copyMap is a helper function to add the
+// entries from one map into another map.
+func copyMap(dst, src map[string]string) map[string]string {
+	// check if any entries are provided
+	if len(src) == 0 {
+		return dst
+	}
+
+	// check if the map is created
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+
+	for key, value := range src {
+		dst[key] = value
+	}
+
+	return dst
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-vela/types/pipeline"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestKubernetes_loadPodTemplate(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		file    string
+	}{
+		{
+			failure: false,
+			file:    "testdata/pod_template/valid.yml",
+		},
+		{
+			failure: true,
+			file:    "testdata/pod_template/missing.yml",
+		},
+		{
+			failure: true,
+			file:    "testdata/pod_template/unknown_field.yml",
+		},
+		{
+			failure: true,
+			file:    "testdata/pod_template/reserved_label.yml",
+		},
+		{
+			failure: true,
+			file:    "testdata/pod_template/reserved_annotation.yml",
+		},
+		{
+			failure: true,
+			file:    "testdata/pod_template/invalid_kind.yml",
+		},
+		{
+			failure: true,
+			file:    "testdata/pod_template/invalid_label.yml",
+		},
+		{
+			failure: true,
+			file:    "testdata/pod_template/invalid_toleration.yml",
+		},
+		{
+			failure: true,
+			file:    "testdata/pod_template/invalid_service_account.yml",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_, err := loadPodTemplate(test.file)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("loadPodTemplate for %s should have returned err", test.file)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("loadPodTemplate for %s returned err: %v", test.file, err)
+		}
+	}
+}
+
+func TestKubernetes_SetupBuild_PodTemplate(t *testing.T) {
+	// setup types
+	_arm64 := *_steps
+	_arm64.Worker = pipeline.Worker{Platform: "linux/arm64"}
+
+	automount := false
+
+	// setup tests
+	tests := []struct {
+		name     string
+		pipeline *pipeline.Build
+		want     []v1.NodeSelectorRequirement
+	}{
+		{
+			name:     "template affinity",
+			pipeline: _steps,
+			want: []v1.NodeSelectorRequirement{
+				{Key: "example.com/zone", Operator: v1.NodeSelectorOpIn, Values: []string{"us-east-1a"}},
+			},
+		},
+		{
+			name:     "template and platform affinity",
+			pipeline: &_arm64,
+			want: []v1.NodeSelectorRequirement{
+				{Key: "example.com/zone", Operator: v1.NodeSelectorOpIn, Values: []string{"us-east-1a"}},
+				{Key: v1.LabelOSStable, Operator: v1.NodeSelectorOpIn, Values: []string{"linux"}},
+				{Key: v1.LabelArchStable, Operator: v1.NodeSelectorOpIn, Values: []string{"arm64"}},
+			},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := NewMock(&v1.Pod{}, WithPodTemplate("testdata/pod_template/valid.yml"), WithWorkerID("worker-1"))
+		if err != nil {
+			t.Errorf("unable to create runtime engine: %v", err)
+		}
+
+		err = _engine.SetupBuild(context.Background(), test.pipeline)
+		if err != nil {
+			t.Errorf("SetupBuild for %s returned err: %v", test.name, err)
+		}
+
+		pod := _engine.builds[test.pipeline.ID].Pod
+
+		wantLabels := map[string]string{"pipeline": test.pipeline.ID, "team": "octocat", LabelWorker: "worker-1"}
+		if !reflect.DeepEqual(pod.ObjectMeta.Labels, wantLabels) {
+			t.Errorf("SetupBuild for %s labels is %v, want %v", test.name, pod.ObjectMeta.Labels, wantLabels)
+		}
+
+		wantAnnotations := map[string]string{"example.com/cost-center": "1234"}
+		if !reflect.DeepEqual(pod.ObjectMeta.Annotations, wantAnnotations) {
+			t.Errorf("SetupBuild for %s annotations is %v, want %v", test.name, pod.ObjectMeta.Annotations, wantAnnotations)
+		}
+
+		wantSelector := map[string]string{"node-pool": "builds"}
+		if !reflect.DeepEqual(pod.Spec.NodeSelector, wantSelector) {
+			t.Errorf("SetupBuild for %s node selector is %v, want %v", test.name, pod.Spec.NodeSelector, wantSelector)
+		}
+
+		if len(pod.Spec.Tolerations) != 1 || pod.Spec.Tolerations[0].Key != "dedicated" {
+			t.Errorf("SetupBuild for %s tolerations is %v", test.name, pod.Spec.Tolerations)
+		}
+
+		if pod.Spec.PriorityClassName != "vela-builds" {
+			t.Errorf("SetupBuild for %s priority class is %s, want vela-builds", test.name, pod.Spec.PriorityClassName)
+		}
+
+		if pod.Spec.ServiceAccountName != "vela-build" {
+			t.Errorf("SetupBuild for %s service account is %s, want vela-build", test.name, pod.Spec.ServiceAccountName)
+		}
+
+		if !reflect.DeepEqual(pod.Spec.AutomountServiceAccountToken, &automount) {
+			t.Errorf("SetupBuild for %s automount is %v, want %v", test.name, pod.Spec.AutomountServiceAccountToken, automount)
+		}
+
+		if pod.Spec.RestartPolicy != v1.RestartPolicyNever {
+			t.Errorf("SetupBuild for %s restart policy is %s, want %s", test.name, pod.Spec.RestartPolicy, v1.RestartPolicyNever)
+		}
+
+		terms := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+		if len(terms) != 1 || !reflect.DeepEqual(terms[0].MatchExpressions, test.want) {
+			t.Errorf("SetupBuild for %s node selector terms is %v, want %v", test.name, terms, test.want)
+		}
+
+		// the template must not be modified by merging the platform
+		template := _engine.config.PodTemplate.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+		if len(template.NodeSelectorTerms[0].MatchExpressions) != 1 {
+			t.Errorf("SetupBuild for %s modified the pod template affinity", test.name)
+		}
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"strings"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	vol "github.com/go-vela/pkg-runtime/internal/volume"
 	"github.com/go-vela/types/constants"
@@ -23,6 +25,14 @@ import (
 func (c *client) CreateVolume(ctx context.Context, b *pipeline.Build) error {
 	logrus.Tracef("creating volume for pipeline %s", b.ID)
 
+	s, err := c.buildState(b)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	// create the workspace volume for the pod
 	//
 	// This is done due to the nature of how volumes works inside
@@ -30,7 +40,7 @@ func (c *client) CreateVolume(ctx context.Context, b *pipeline.Build) error {
 	// the same volume. This allows them to share this volume
 	// throughout the life of the pod. However, to keep the
 	// runtime behavior consistent, Vela uses an emtpyDir volume
-	// because that volume only exists for the life
+	// by default because that volume only exists for the life
 	// of the pod.
 	//
 	// More info:
@@ -39,10 +49,16 @@ func (c *client) CreateVolume(ctx context.Context, b *pipeline.Build) error {
 	//
 	// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#Volume
 	workspaceVolume := v1.Volume{
-		Name: b.ID,
-		VolumeSource: v1.VolumeSource{
-			EmptyDir: &v1.EmptyDirVolumeSource{},
-		},
+		Name:         b.ID,
+		VolumeSource: c.workspaceSource(b),
+	}
+
+	// check if the workspace is a persistent volume claim
+	if c.config.Workspace.Type == WorkspaceClaim {
+		err = c.createClaim(ctx, s, b)
+		if err != nil {
+			return err
+		}
 	}
 
 	// create the workspace volumeMount for the pod
@@ -56,10 +72,10 @@ func (c *client) CreateVolume(ctx context.Context, b *pipeline.Build) error {
 	// add the volume definition to the pod spec
 	//
 	// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#PodSpec
-	c.Pod.Spec.Volumes = append(c.Pod.Spec.Volumes, workspaceVolume)
+	s.Pod.Spec.Volumes = append(s.Pod.Spec.Volumes, workspaceVolume)
 
 	// save the volumeMount to add to each of the containers in the pod spec later
-	c.commonVolumeMounts = append(c.commonVolumeMounts, workspaceVolumeMount)
+	s.commonVolumeMounts = append(s.commonVolumeMounts, workspaceVolumeMount)
 
 	// check if global host volumes were provided (VELA_RUNTIME_VOLUMES)
 	if len(c.config.Volumes) > 0 {
@@ -70,7 +86,7 @@ func (c *client) CreateVolume(ctx context.Context, b *pipeline.Build) error {
 			_volumeName := fmt.Sprintf("%s_%d", b.ID, k)
 
 			// add the volume to the set of pod volumes
-			c.Pod.Spec.Volumes = append(c.Pod.Spec.Volumes, v1.Volume{
+			s.Pod.Spec.Volumes = append(s.Pod.Spec.Volumes, v1.Volume{
 				Name: _volumeName,
 				VolumeSource: v1.VolumeSource{
 					HostPath: &v1.HostPathVolumeSource{
@@ -80,7 +96,7 @@ func (c *client) CreateVolume(ctx context.Context, b *pipeline.Build) error {
 			})
 
 			// save the volumeMounts for later addition to each container's mounts
-			c.commonVolumeMounts = append(c.commonVolumeMounts, v1.VolumeMount{
+			s.commonVolumeMounts = append(s.commonVolumeMounts, v1.VolumeMount{
 				Name:      _volumeName,
 				MountPath: _volume.Destination,
 			})
@@ -96,6 +112,14 @@ func (c *client) CreateVolume(ctx context.Context, b *pipeline.Build) error {
 func (c *client) InspectVolume(ctx context.Context, b *pipeline.Build) ([]byte, error) {
 	logrus.Tracef("inspecting volume for pipeline %s", b.ID)
 
+	s, err := c.buildState(b)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	// TODO: consider updating this command
 	//
 	// create output for inspecting volume
@@ -104,7 +128,7 @@ func (c *client) InspectVolume(ctx context.Context, b *pipeline.Build) ([]byte,
 	)
 
 	// marshal the volume information from the pod
-	volume, err := json.MarshalIndent(c.Pod.Spec.Volumes, "", " ")
+	volume, err := json.MarshalIndent(s.Pod.Spec.Volumes, "", " ")
 	if err != nil {
 		return nil, err
 	}
@@ -120,24 +144,192 @@ func (c *client) InspectVolume(ctx context.Context, b *pipeline.Build) ([]byte,
 func (c *client) RemoveVolume(ctx context.Context, b *pipeline.Build) error {
 	logrus.Tracef("removing volume for pipeline %s", b.ID)
 
+	s, err := c.buildState(b)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	// remove the volume definition from the pod spec
 	//
 	// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#PodSpec
-	c.Pod.Spec.Volumes = []v1.Volume{}
+	s.Pod.Spec.Volumes = []v1.Volume{}
+
+	return nil
+}
+
+// workspaceSource is a helper function to create
+// the source for the workspace volume for the build.
+func (c *client) workspaceSource(b *pipeline.Build) v1.VolumeSource {
+	w := c.config.Workspace
+
+	switch w.Type {
+	case WorkspaceMemory:
+		// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#EmptyDirVolumeSource
+		return v1.VolumeSource{
+			EmptyDir: &v1.EmptyDirVolumeSource{
+				Medium:    v1.StorageMediumMemory,
+				SizeLimit: w.Size,
+			},
+		}
+	case WorkspaceClaim:
+		// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#PersistentVolumeClaimVolumeSource
+		return v1.VolumeSource{
+			PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+				ClaimName: b.ID,
+			},
+		}
+	case WorkspaceEphemeral:
+		// the claim for a generic ephemeral volume is
+		// created and deleted by Kubernetes with the pod
+		//
+		// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#EphemeralVolumeSource
+		return v1.VolumeSource{
+			Ephemeral: &v1.EphemeralVolumeSource{
+				VolumeClaimTemplate: &v1.PersistentVolumeClaimTemplate{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{"pipeline": b.ID},
+					},
+					Spec: c.claimSpec(),
+				},
+			},
+		}
+	default:
+		// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#EmptyDirVolumeSource
+		return v1.VolumeSource{
+			EmptyDir: &v1.EmptyDirVolumeSource{},
+		}
+	}
+}
+
+// claimSpec is a helper function to create the spec
+// for the claim for the workspace volume for the build.
+func (c *client) claimSpec() v1.PersistentVolumeClaimSpec {
+	// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#PersistentVolumeClaimSpec
+	spec := v1.PersistentVolumeClaimSpec{
+		AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceStorage: *c.config.Workspace.Size,
+			},
+		},
+	}
+
+	// check if a storage class is provided
+	if len(c.config.Workspace.StorageClass) > 0 {
+		storageClass := c.config.Workspace.StorageClass
+
+		spec.StorageClassName = &storageClass
+	}
+
+	return spec
+}
+
+// createClaim is a helper function to create the
+// claim for the workspace volume for the build.
+func (c *client) createClaim(ctx context.Context, s *buildState, b *pipeline.Build) error {
+	// https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#PersistentVolumeClaim
+	claim := &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   b.ID,
+			Labels: map[string]string{"pipeline": b.ID},
+		},
+		Spec: c.claimSpec(),
+	}
+
+	// If the api call to create the claim fails, the claim
+	// might partially exist. So, set this first to make
+	// sure all remnants get deleted.
+	s.createdClaim = true
+
+	logrus.Infof("creating persistent volume claim %s", b.ID)
+	// send API call to create the claim
+	//
+	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#PersistentVolumeClaimInterface
+	_, err := c.Kubernetes.CoreV1().
+		PersistentVolumeClaims(c.config.Namespace).
+		Create(ctx, claim, metav1.CreateOptions{})
+
+	return err
+}
+
+// ownClaim is a helper function to set the pod as the owner
+// of the claim for the workspace volume for the build, so the
+// claim is deleted by Kubernetes with the pod if the build is
+// never removed.
+func (c *client) ownClaim(ctx context.Context, s *buildState, b *pipeline.Build, pod *v1.Pod) error {
+	// check if the claim was created
+	if !s.createdClaim {
+		return nil
+	}
+
+	claims := c.Kubernetes.CoreV1().PersistentVolumeClaims(c.config.Namespace)
+
+	// send API call to capture the claim
+	//
+	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#PersistentVolumeClaimInterface
+	claim, err := claims.Get(ctx, b.ID, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	// https://pkg.go.dev/k8s.io/apimachinery/pkg/apis/meta/v1?tab=doc#OwnerReference
+	claim.ObjectMeta.OwnerReferences = []metav1.OwnerReference{
+		{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Name:       pod.ObjectMeta.Name,
+			UID:        pod.ObjectMeta.UID,
+		},
+	}
+
+	// send API call to update the claim
+	//
+	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#PersistentVolumeClaimInterface
+	_, err = claims.Update(ctx, claim, metav1.UpdateOptions{})
+
+	return err
+}
+
+// removeClaim is a helper function to delete the
+// claim for the workspace volume for the build.
+func (c *client) removeClaim(ctx context.Context, s *buildState, b *pipeline.Build) error {
+	// check if the claim was created
+	if !s.createdClaim {
+		return nil
+	}
+
+	logrus.Infof("removing persistent volume claim %s", b.ID)
+	// send API call to delete the claim
+	//
+	// The claim is protected from removal until
+	// the pod using the claim has been deleted.
+	//
+	// https://pkg.go.dev/k8s.io/client-go/kubernetes/typed/core/v1?tab=doc#PersistentVolumeClaimInterface
+	err := c.Kubernetes.CoreV1().
+		PersistentVolumeClaims(c.config.Namespace).
+		Delete(ctx, b.ID, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	s.createdClaim = false
 
 	return nil
 }
 
 // setupVolumeMounts generates the VolumeMounts for a given container.
 // nolint:unparam // keep signature similar to Engine interface methods despite unused ctx and err
-func (c *client) setupVolumeMounts(ctx context.Context, ctn *pipeline.Container) (
+func (c *client) setupVolumeMounts(ctx context.Context, s *buildState, ctn *pipeline.Container) (
 	volumeMounts []v1.VolumeMount,
 	err error,
 ) {
 	logrus.Tracef("setting up VolumeMounts for container %s", ctn.ID)
 
 	// add workspace mount and any global host mounts (VELA_RUNTIME_VOLUMES)
-	volumeMounts = append(volumeMounts, c.commonVolumeMounts...)
+	volumeMounts = append(volumeMounts, s.commonVolumeMounts...)
 
 	// -------------------- Start of TODO: --------------------
 	//
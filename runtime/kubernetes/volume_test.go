@@ -6,11 +6,15 @@ package kubernetes
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
 	"github.com/go-vela/types/pipeline"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestKubernetes_CreateVolume(t *testing.T) {
@@ -53,6 +57,182 @@ func TestKubernetes_CreateVolume(t *testing.T) {
 	}
 }
 
+func TestKubernetes_CreateVolume_Workspace(t *testing.T) {
+	// setup types
+	size := resource.MustParse("10Gi")
+	storageClass := "fast"
+
+	claimSpec := v1.PersistentVolumeClaimSpec{
+		AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+		StorageClassName: &storageClass,
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceStorage: size},
+		},
+	}
+
+	// setup tests
+	tests := []struct {
+		name  string
+		opt   ClientOpt
+		want  v1.VolumeSource
+		claim bool
+	}{
+		{
+			name: "emptyDir",
+			opt:  WithWorkspaceVolume("", "", ""),
+			want: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}},
+		},
+		{
+			name: "memory",
+			opt:  WithWorkspaceVolume(WorkspaceMemory, "", "10Gi"),
+			want: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{Medium: v1.StorageMediumMemory, SizeLimit: &size}},
+		},
+		{
+			name: "ephemeral",
+			opt:  WithWorkspaceVolume(WorkspaceEphemeral, storageClass, "10Gi"),
+			want: v1.VolumeSource{
+				Ephemeral: &v1.EphemeralVolumeSource{
+					VolumeClaimTemplate: &v1.PersistentVolumeClaimTemplate{
+						ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"pipeline": _steps.ID}},
+						Spec:       claimSpec,
+					},
+				},
+			},
+		},
+		{
+			name:  "pvc",
+			opt:   WithWorkspaceVolume(WorkspaceClaim, storageClass, "10Gi"),
+			want:  v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: _steps.ID}},
+			claim: true,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_engine, err := NewMock(&v1.Pod{}, test.opt)
+		if err != nil {
+			t.Errorf("unable to create runtime engine: %v", err)
+		}
+
+		err = _engine.SetupBuild(context.Background(), _steps)
+		if err != nil {
+			t.Errorf("SetupBuild for %s returned err: %v", test.name, err)
+		}
+
+		err = _engine.CreateVolume(context.Background(), _steps)
+		if err != nil {
+			t.Errorf("CreateVolume for %s returned err: %v", test.name, err)
+		}
+
+		got := _engine.builds[_steps.ID].Pod.Spec.Volumes[0].VolumeSource
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("CreateVolume for %s is %+v, want %+v", test.name, got, test.want)
+		}
+
+		claims := _engine.Kubernetes.CoreV1().PersistentVolumeClaims(_engine.config.Namespace)
+
+		claim, err := claims.Get(context.Background(), _steps.ID, metav1.GetOptions{})
+		if test.claim != (err == nil) {
+			t.Errorf("CreateVolume for %s created claim is %v, want %v", test.name, err == nil, test.claim)
+		}
+
+		if test.claim && !reflect.DeepEqual(claim.Spec, claimSpec) {
+			t.Errorf("CreateVolume for %s claim is %+v, want %+v", test.name, claim.Spec, claimSpec)
+		}
+
+		err = _engine.RemoveBuild(context.Background(), _steps)
+		if err != nil {
+			t.Errorf("RemoveBuild for %s returned err: %v", test.name, err)
+		}
+
+		_, err = claims.Get(context.Background(), _steps.ID, metav1.GetOptions{})
+		if !errors.IsNotFound(err) {
+			t.Errorf("RemoveBuild for %s did not remove claim: %v", test.name, err)
+		}
+	}
+}
+
+func TestKubernetes_RemoveBuild_Claim(t *testing.T) {
+	// setup types
+	_engine, err := NewMock(&v1.Pod{}, WithWorkspaceVolume(WorkspaceClaim, "", "1Gi"))
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	_state := newBuildState(&v1.Pod{})
+
+	// the claim may already be deleted
+	_state.createdClaim = true
+
+	_engine.builds = map[string]*buildState{_steps.ID: _state}
+
+	err = _engine.RemoveBuild(context.Background(), _steps)
+	if err != nil {
+		t.Errorf("RemoveBuild returned err: %v", err)
+	}
+
+	if _state.createdClaim {
+		t.Errorf("RemoveBuild did not reset created claim")
+	}
+}
+
+func TestKubernetes_AssembleBuild_Claim(t *testing.T) {
+	// setup types
+	_step := &pipeline.Container{
+		ID:        "step_github_octocat_1_echo",
+		Directory: "/vela/src/github.com/octocat/helloworld",
+		Image:     "alpine:latest",
+		Name:      "echo",
+		Number:    2,
+		Pull:      "not_present",
+	}
+
+	_build := &pipeline.Build{
+		ID:      "github-octocat-1",
+		Version: "1",
+		Steps:   pipeline.ContainerSlice{_step},
+	}
+
+	_engine, err := NewMock(&v1.Pod{}, WithWorkspaceVolume(WorkspaceClaim, "", "1Gi"))
+	if err != nil {
+		t.Errorf("unable to create runtime engine: %v", err)
+	}
+
+	err = _engine.SetupBuild(context.Background(), _build)
+	if err != nil {
+		t.Errorf("SetupBuild returned err: %v", err)
+	}
+
+	err = _engine.CreateVolume(context.Background(), _build)
+	if err != nil {
+		t.Errorf("CreateVolume returned err: %v", err)
+	}
+
+	err = _engine.SetupContainer(context.Background(), _step)
+	if err != nil {
+		t.Errorf("SetupContainer returned err: %v", err)
+	}
+
+	// run test
+	err = _engine.AssembleBuild(context.Background(), _build)
+	if err != nil {
+		t.Errorf("AssembleBuild returned err: %v", err)
+	}
+
+	claims := _engine.Kubernetes.CoreV1().PersistentVolumeClaims(_engine.config.Namespace)
+
+	claim, err := claims.Get(context.Background(), _build.ID, metav1.GetOptions{})
+	if err != nil {
+		t.Errorf("AssembleBuild did not create claim: %v", err)
+	}
+
+	// check the pod is the owner of the claim
+	if len(claim.OwnerReferences) != 1 || claim.OwnerReferences[0].Name != _build.ID {
+		t.Errorf("AssembleBuild claim owner is %+v, want pod %s", claim.OwnerReferences, _build.ID)
+	}
+}
+
 func TestKubernetes_InspectVolume(t *testing.T) {
 	// setup tests
 	tests := []struct {
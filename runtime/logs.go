@@ -0,0 +1,60 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package runtime
+
+import (
+	"context"
+
+	"github.com/go-vela/pkg-runtime/internal/logs"
+	"github.com/go-vela/types/pipeline"
+)
+
+const (
+	// LogStdout represents the stream for
+	// standard output from a container.
+	LogStdout = logs.StreamStdout
+	// LogStderr represents the stream for
+	// standard error from a container.
+	LogStderr = logs.StreamStderr
+)
+
+// LogRecord represents a single line of
+// output captured from a container.
+type LogRecord = logs.Record
+
+// LogReader represents a stream of log records
+// captured from a container by the runtime.
+//
+// Use Next to capture each record until io.EOF:
+//
+// 	for {
+// 		record, err := reader.Next()
+// 		if err != nil {
+// 			break
+// 		}
+//
+// 		fmt.Println(record.Stream, record.Timestamp, record.Line)
+// 	}
+type LogReader = logs.Reader
+
+// LogLimitError represents the error returned by the runtime
+// once all logs have been read from a container that exceeded
+// the configured log limits.
+type LogLimitError = logs.LimitError
+
+// RecordTailer represents an optional interface for a runtime
+// capable of capturing the logs on a container as records.
+//
+// Use a type assertion on the Engine to check for support:
+//
+// 	if tailer, ok := engine.(runtime.RecordTailer); ok {
+// 		reader, err := tailer.TailRecords(ctx, ctn)
+// 	}
+type RecordTailer interface {
+	// TailRecords defines a function that captures the
+	// log records, with the stream and timestamp for
+	// each line, on the pipeline container.
+	TailRecords(context.Context, *pipeline.Container) (LogReader, error)
+}
@@ -0,0 +1,331 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package runtime
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// Mask represents the value written in
+	// place of a secret in container logs.
+	Mask = "***"
+
+	// minLineLength represents the minimum length of a
+	// single line from a multi-line secret that is masked
+	// on its own. Shorter lines, like a closing brace in
+	// a JSON secret, would mask too much of the logs.
+	minLineLength = 4
+
+	// maskChunkSize represents the size in bytes of
+	// each read from the underlying container logs.
+	maskChunkSize = 32 * 1024
+)
+
+// Masker represents a set of registered secret
+// values to mask in the logs for a container.
+type Masker struct {
+	mutex    sync.RWMutex
+	patterns []string
+}
+
+// NewMasker creates a Masker with the
+// provided secret values registered.
+func NewMasker(secrets ...string) *Masker {
+	m := new(Masker)
+
+	m.Register(secrets...)
+
+	return m
+}
+
+// Register adds the provided secret values to the
+// Masker. Each value is masked as provided, as each
+// line for a multi-line value, and as the standard
+// and URL-safe base64 encodings of the value.
+func (m *Masker) Register(secrets ...string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// capture the existing patterns to avoid duplicates
+	seen := make(map[string]bool)
+	for _, pattern := range m.patterns {
+		seen[pattern] = true
+	}
+
+	add := func(pattern string) {
+		if len(pattern) == 0 || seen[pattern] {
+			return
+		}
+
+		seen[pattern] = true
+
+		m.patterns = append(m.patterns, pattern)
+	}
+
+	// iterate through all secret values provided
+	for _, secret := range secrets {
+		// skip empty secret values
+		if len(strings.TrimSpace(secret)) == 0 {
+			continue
+		}
+
+		add(secret)
+
+		// capture each line for multi-line secret values
+		if strings.ContainsAny(secret, "\r\n") {
+			for _, line := range strings.FieldsFunc(secret, func(r rune) bool { return r == '\r' || r == '\n' }) {
+				if len(strings.TrimSpace(line)) >= minLineLength {
+					add(line)
+				}
+			}
+		}
+
+		// capture the base64 encodings for the secret value
+		// and the secret value with a trailing newline, as
+		// produced by commands like `echo $SECRET | base64`
+		for _, value := range []string{secret, secret + "\n"} {
+			for _, encoding := range []*base64.Encoding{
+				base64.StdEncoding,
+				base64.RawStdEncoding,
+				base64.URLEncoding,
+				base64.RawURLEncoding,
+			} {
+				add(encoding.EncodeToString([]byte(value)))
+			}
+		}
+	}
+
+	// sort the patterns so the longest match is preferred
+	sort.SliceStable(m.patterns, func(i, j int) bool {
+		return len(m.patterns[i]) > len(m.patterns[j])
+	})
+}
+
+// Wrap returns an io.ReadCloser that replaces every registered
+// secret value from the provided container logs with the Mask.
+//
+// Output that could be the start of a secret value is held
+// back from the logs until more output arrives, so a secret
+// split across reads from the container is still masked.
+func (m *Masker) Wrap(rc io.ReadCloser) io.ReadCloser {
+	return &maskReader{
+		masker: m,
+		logs:   rc,
+		chunk:  make([]byte, maskChunkSize),
+	}
+}
+
+// WrapRecords returns a LogReader that replaces every
+// registered secret value from the line for each log
+// record from the provided container logs with the Mask.
+func (m *Masker) WrapRecords(records LogReader) LogReader {
+	return &maskRecords{
+		masker:  m,
+		records: records,
+	}
+}
+
+// snapshot is a helper function to capture the registered
+// patterns and the length of the longest pattern.
+func (m *Masker) snapshot() ([]string, int) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	// check if any patterns are registered
+	if len(m.patterns) == 0 {
+		return nil, 0
+	}
+
+	// patterns are sorted by length in descending order
+	return m.patterns, len(m.patterns[0])
+}
+
+// maskReader represents an io.ReadCloser that
+// masks secret values from container logs.
+type maskReader struct {
+	masker *Masker
+	logs   io.ReadCloser
+	chunk  []byte
+
+	// pending output not yet checked for secret values
+	pending []byte
+	// masked output not yet returned to the caller
+	masked bytes.Buffer
+	err    error
+}
+
+// Read reads the masked output from the container logs.
+func (r *maskReader) Read(p []byte) (int, error) {
+	for r.masked.Len() == 0 {
+		// check if the container logs have been exhausted
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		n, err := r.logs.Read(r.chunk)
+
+		r.pending = append(r.pending, r.chunk[:n]...)
+
+		// mask the pending output, flushing it all
+		// once the container logs are exhausted
+		r.mask(err != nil)
+
+		r.err = err
+	}
+
+	return r.masked.Read(p)
+}
+
+// Close closes the underlying container logs.
+func (r *maskReader) Close() error {
+	return r.logs.Close()
+}
+
+// mask is a helper function to replace secret values in the
+// pending output and move the output that can no longer be
+// part of a secret value to the masked output.
+func (r *maskReader) mask(flush bool) {
+	patterns, longest := r.masker.snapshot()
+
+	for {
+		i, n := match(r.pending, patterns)
+		if i < 0 {
+			break
+		}
+
+		// check if a secret value starting at or before
+		// the match could still match once more output
+		// arrives, since the leftmost, then longest,
+		// secret value is preferred
+		if !flush && partial(r.pending, patterns, longest) <= i {
+			break
+		}
+
+		r.masked.Write(r.pending[:i])
+		r.masked.WriteString(Mask)
+
+		r.pending = r.pending[i+n:]
+	}
+
+	// capture the output that can no longer be part of a secret
+	//
+	// Only the output at the end that is the start of a secret
+	// value is held back, since any secret value contained in
+	// the pending output would have been matched above.
+	safe := len(r.pending)
+	if !flush {
+		safe = partial(r.pending, patterns, longest)
+	}
+
+	if safe <= 0 {
+		return
+	}
+
+	r.masked.Write(r.pending[:safe])
+
+	r.pending = append(r.pending[:0], r.pending[safe:]...)
+}
+
+// maskRecords represents a LogReader that masks
+// secret values from container log records.
+type maskRecords struct {
+	masker  *Masker
+	records LogReader
+}
+
+// Next returns the next masked log record from the container logs.
+func (r *maskRecords) Next() (*LogRecord, error) {
+	record, err := r.records.Next()
+	if record == nil {
+		return nil, err
+	}
+
+	patterns, _ := r.masker.snapshot()
+
+	// create a copy of the log record with the masked line
+	masked := *record
+	masked.Line = maskLine(record.Line, patterns)
+
+	return &masked, err
+}
+
+// Close closes the underlying container log records.
+func (r *maskRecords) Close() error {
+	return r.records.Close()
+}
+
+// maskLine is a helper function to replace
+// every secret value in the line with the Mask.
+func maskLine(line string, patterns []string) string {
+	output := []byte(line)
+	masked := new(strings.Builder)
+
+	for {
+		i, n := match(output, patterns)
+		if i < 0 {
+			break
+		}
+
+		masked.Write(output[:i])
+		masked.WriteString(Mask)
+
+		output = output[i+n:]
+	}
+
+	masked.Write(output)
+
+	return masked.String()
+}
+
+// partial is a helper function to find the index of the
+// earliest output that is the start of a secret value
+// continuing past the end of the output. It returns the
+// length of the output if no secret value could continue.
+func partial(output []byte, patterns []string, longest int) int {
+	// only output within the length of the longest
+	// secret value from the end could continue past it
+	start := len(output) - longest + 1
+	if start < 0 {
+		start = 0
+	}
+
+	for i := start; i < len(output); i++ {
+		for _, pattern := range patterns {
+			if len(pattern) > len(output)-i && strings.HasPrefix(pattern, string(output[i:])) {
+				return i
+			}
+		}
+	}
+
+	return len(output)
+}
+
+// match is a helper function to find the leftmost, then
+// longest, secret value in the output. It returns the
+// index and length of the match, or -1 if none is found.
+func match(output []byte, patterns []string) (int, int) {
+	index, length := -1, 0
+
+	for _, pattern := range patterns {
+		i := bytes.Index(output, []byte(pattern))
+		if i < 0 {
+			continue
+		}
+
+		// patterns are sorted by length in descending
+		// order, so the first match at an index wins
+		if index < 0 || i < index {
+			index, length = i, len(pattern)
+		}
+	}
+
+	return index, length
+}
@@ -0,0 +1,404 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package runtime
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/go-vela/pkg-runtime/runtime/docker"
+	"github.com/go-vela/pkg-runtime/runtime/kubernetes"
+	"github.com/go-vela/types/pipeline"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// chunkReader represents an io.ReadCloser returning
+// the output in chunks of a fixed size.
+type chunkReader struct {
+	output string
+	size   int
+	err    error
+}
+
+// Read returns the next chunk of the output.
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if len(r.output) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		return 0, io.EOF
+	}
+
+	n := r.size
+	if n > len(r.output) {
+		n = len(r.output)
+	}
+
+	if n > len(p) {
+		n = len(p)
+	}
+
+	copy(p, r.output[:n])
+	r.output = r.output[n:]
+
+	return n, nil
+}
+
+// Close is a no-op for the chunkReader.
+func (r *chunkReader) Close() error {
+	return nil
+}
+
+func TestRuntime_Masker_Wrap(t *testing.T) {
+	// setup types
+	multiline := "-----BEGIN KEY-----\nMIIBfoobar\n}\n-----END KEY-----"
+
+	// setup tests
+	tests := []struct {
+		name    string
+		secrets []string
+		output  string
+		want    string
+	}{
+		{
+			name:    "plain",
+			secrets: []string{"hunter2"},
+			output:  "the password is hunter2, right?\n",
+			want:    "the password is ***, right?\n",
+		},
+		{
+			name:    "start and end",
+			secrets: []string{"hunter2"},
+			output:  "hunter2 and hunter2",
+			want:    "*** and ***",
+		},
+		{
+			name:    "adjacent",
+			secrets: []string{"hunter2"},
+			output:  "hunter2hunter2hunter2\n",
+			want:    "*********\n",
+		},
+		{
+			name:    "overlapping secrets",
+			secrets: []string{"pass", "password"},
+			output:  "password pass passw\n",
+			want:    "*** *** ***w\n",
+		},
+		{
+			name:    "partial secret",
+			secrets: []string{"hunter2"},
+			output:  "hunter hunte hunter",
+			want:    "hunter hunte hunter",
+		},
+		{
+			name:    "multi-line",
+			secrets: []string{multiline},
+			output:  "key:\n" + multiline + "\ndone\n",
+			want:    "key:\n***\ndone\n",
+		},
+		{
+			name:    "multi-line by line",
+			secrets: []string{multiline},
+			output:  "-----BEGIN KEY-----\r\nMIIBfoobar\r\n}\r\n-----END KEY-----\r\n",
+			want:    "***\r\n***\r\n}\r\n***\r\n",
+		},
+		{
+			name:    "base64",
+			secrets: []string{"hunter2?>"},
+			output: base64.StdEncoding.EncodeToString([]byte("hunter2?>")) + " " +
+				base64.URLEncoding.EncodeToString([]byte("hunter2?>")) + "\n",
+			want: "*** ***\n",
+		},
+		{
+			name:    "base64 without padding",
+			secrets: []string{"hunter2!"},
+			output:  base64.RawStdEncoding.EncodeToString([]byte("hunter2!")) + "\n",
+			want:    "***\n",
+		},
+		{
+			name:    "base64 with newline",
+			secrets: []string{"hunter2"},
+			output:  base64.StdEncoding.EncodeToString([]byte("hunter2\n")) + "\n",
+			want:    "***\n",
+		},
+		{
+			name:    "empty secret",
+			secrets: []string{"", "  "},
+			output:  "hello world\n",
+			want:    "hello world\n",
+		},
+		{
+			name:    "no secrets",
+			secrets: nil,
+			output:  "hello world\n",
+			want:    "hello world\n",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		m := NewMasker(test.secrets...)
+
+		// read the output in every possible chunk size
+		for size := 1; size <= len(test.output)+1; size++ {
+			r := m.Wrap(&chunkReader{output: test.output, size: size})
+
+			got, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Errorf("Wrap for %s with chunk size %d returned err: %v", test.name, size, err)
+			}
+
+			if string(got) != test.want {
+				t.Errorf("Wrap for %s with chunk size %d is %q, want %q", test.name, size, got, test.want)
+			}
+		}
+	}
+}
+
+func TestRuntime_Masker_Wrap_Error(t *testing.T) {
+	// setup types
+	m := NewMasker("hunter2")
+
+	want := errors.New("connection reset")
+
+	// run test
+	got, err := ioutil.ReadAll(m.Wrap(&chunkReader{output: "hello hunter2 hunt", size: 3, err: want}))
+
+	if !errors.Is(err, want) {
+		t.Errorf("Wrap returned err %v, want %v", err, want)
+	}
+
+	if string(got) != "hello *** hunt" {
+		t.Errorf("Wrap is %q, want %q", got, "hello *** hunt")
+	}
+}
+
+func TestRuntime_Masker_Wrap_Partial(t *testing.T) {
+	// setup types
+	m := NewMasker("hunter2")
+
+	pr, pw := io.Pipe()
+	defer pw.Close()
+
+	r := m.Wrap(pr)
+
+	// setup tests
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{
+			name:   "partial line",
+			output: "building...",
+			want:   "building...",
+		},
+		{
+			name:   "partial line ending with start of secret",
+			output: " hello hun",
+			want:   " hello ",
+		},
+		{
+			name:   "partial line completing secret",
+			output: "ter2 world",
+			want:   "*** world",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		go func(output string) {
+			_, _ = pw.Write([]byte(output))
+		}(test.output)
+
+		// read the output before the container logs are exhausted
+		got := make([]byte, 64)
+
+		n, err := r.Read(got)
+		if err != nil {
+			t.Errorf("Read for %s returned err: %v", test.name, err)
+		}
+
+		if string(got[:n]) != test.want {
+			t.Errorf("Read for %s is %q, want %q", test.name, got[:n], test.want)
+		}
+	}
+}
+
+// recordReader represents a LogReader
+// returning a fixed set of log records.
+type recordReader struct {
+	records []*LogRecord
+}
+
+// Next returns the next log record.
+func (r *recordReader) Next() (*LogRecord, error) {
+	if len(r.records) == 0 {
+		return nil, io.EOF
+	}
+
+	record := r.records[0]
+	r.records = r.records[1:]
+
+	return record, nil
+}
+
+// Close is a no-op for the recordReader.
+func (r *recordReader) Close() error {
+	return nil
+}
+
+func TestRuntime_Masker_WrapRecords(t *testing.T) {
+	// setup types
+	m := NewMasker("hunter2", "-----BEGIN KEY-----\nMIIBfoobar\n-----END KEY-----")
+
+	records := &recordReader{
+		records: []*LogRecord{
+			{Stream: LogStdout, Line: "the password is hunter2, right?"},
+			{Stream: LogStderr, Line: "hunter2hunter2 " + base64.StdEncoding.EncodeToString([]byte("hunter2"))},
+			{Stream: LogStdout, Line: "-----BEGIN KEY-----"},
+			{Stream: LogStdout, Line: "MIIBfoobar"},
+			{Stream: LogStdout, Line: "hello world"},
+		},
+	}
+
+	want := []LogRecord{
+		{Stream: LogStdout, Line: "the password is ***, right?"},
+		{Stream: LogStderr, Line: "****** ***"},
+		{Stream: LogStdout, Line: "***"},
+		{Stream: LogStdout, Line: "***"},
+		{Stream: LogStdout, Line: "hello world"},
+	}
+
+	// run test
+	r := m.WrapRecords(records)
+
+	for _, record := range want {
+		got, err := r.Next()
+		if err != nil {
+			t.Errorf("Next returned err: %v", err)
+
+			return
+		}
+
+		if *got != record {
+			t.Errorf("Next is %+v, want %+v", got, record)
+		}
+	}
+
+	_, err := r.Next()
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("Next returned err %v, want %v", err, io.EOF)
+	}
+
+	err = r.Close()
+	if err != nil {
+		t.Errorf("Close returned err: %v", err)
+	}
+}
+
+func TestRuntime_Masker_Register(t *testing.T) {
+	// setup types
+	m := NewMasker()
+
+	r := m.Wrap(&chunkReader{output: "hello hunter2\n", size: 4})
+
+	// register a secret after wrapping the logs
+	m.Register("hunter2", "hunter2")
+
+	// run test
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Errorf("Wrap returned err: %v", err)
+	}
+
+	if string(got) != "hello ***\n" {
+		t.Errorf("Wrap is %q, want %q", got, "hello ***\n")
+	}
+}
+
+func TestRuntime_Masker_Drivers(t *testing.T) {
+	// setup types
+	_container := &pipeline.Container{
+		ID:     "step_github_octocat_1_clone",
+		Image:  "target/vela-git:v0.4.0",
+		Name:   "clone",
+		Number: 2,
+	}
+
+	_docker, err := docker.NewMock()
+	if err != nil {
+		t.Errorf("unable to create docker runtime engine: %v", err)
+	}
+
+	_kubernetes, err := kubernetes.NewMock(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "github-octocat-1", Namespace: "test"},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name:  "step_github_octocat_1_clone",
+					Image: "target/vela-git:v0.4.0",
+				},
+			},
+		},
+		Status: v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					Name:  "step_github_octocat_1_clone",
+					Image: "target/vela-git:v0.4.0",
+					State: v1.ContainerState{
+						Terminated: &v1.ContainerStateTerminated{Reason: "Completed"},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Errorf("unable to create kubernetes runtime engine: %v", err)
+	}
+
+	// setup tests
+	tests := []struct {
+		engine Engine
+		secret string
+		want   string
+	}{
+		{
+			engine: _docker,
+			secret: "github.com/go-vela/mock",
+			want:   "hello to stdout from ***/dockerhello to stderr from ***/docker",
+		},
+		{
+			engine: _kubernetes,
+			secret: "fake",
+			want:   "*** logs",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		logs, err := test.engine.TailContainer(context.Background(), _container)
+		if err != nil {
+			t.Errorf("TailContainer for %s returned err: %v", test.engine.Driver(), err)
+		}
+
+		got, err := ioutil.ReadAll(NewMasker(test.secret).Wrap(logs))
+		if err != nil {
+			t.Errorf("Wrap for %s returned err: %v", test.engine.Driver(), err)
+		}
+
+		if !strings.EqualFold(string(got), test.want) {
+			t.Errorf("Wrap for %s is %q, want %q", test.engine.Driver(), got, test.want)
+		}
+	}
+}
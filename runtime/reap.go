@@ -0,0 +1,52 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package runtime
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-vela/pkg-runtime/runtime/docker"
+	"github.com/go-vela/pkg-runtime/runtime/kubernetes"
+)
+
+// ReapReport represents the result of removing
+// orphaned resources for builds from the host.
+type ReapReport = docker.ReapReport
+
+// Reaper represents an optional interface for a runtime
+// capable of removing orphaned resources for builds.
+//
+// Use a type assertion on the Engine to check for support:
+//
+// 	if reaper, ok := engine.(runtime.Reaper); ok {
+// 		report, err := reaper.Reap(ctx, 24*time.Hour, false)
+// 	}
+type Reaper interface {
+	// Reap defines a function that removes the resources
+	// for builds older than the duration provided, or
+	// only lists them when dry run is enabled.
+	Reap(context.Context, time.Duration, bool) (*ReapReport, error)
+}
+
+// PodReapReport represents the result of removing
+// orphaned pods for builds from the namespace.
+type PodReapReport = kubernetes.ReapReport
+
+// PodReaper represents an optional interface for a runtime
+// capable of removing orphaned pods for builds.
+//
+// Use a type assertion on the Engine to check for support:
+//
+// 	if reaper, ok := engine.(runtime.PodReaper); ok {
+// 		report, err := reaper.Reap(ctx, 10*time.Minute, false)
+// 	}
+type PodReaper interface {
+	// Reap defines a function that removes the pods for builds
+	// owned by a worker that is gone or with a heartbeat older
+	// than the duration provided, or only lists them when dry
+	// run is enabled.
+	Reap(context.Context, time.Duration, bool) (*PodReapReport, error)
+}
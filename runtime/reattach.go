@@ -0,0 +1,31 @@
+// Copyright (c) 2021 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package runtime
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-vela/types/pipeline"
+)
+
+// Reattacher represents an optional interface for a runtime
+// capable of reattaching to a pipeline build still running
+// after the worker was restarted.
+//
+// Use a type assertion on the Engine to check for support:
+//
+// 	if reattacher, ok := engine.(runtime.Reattacher); ok {
+// 		err := reattacher.ReattachBuild(ctx, b, lastLog)
+// 	}
+//
+// After reattaching, TailContainer and WaitContainer
+// resume for the containers still running for the build.
+type Reattacher interface {
+	// ReattachBuild defines a function that rediscovers the
+	// resources for the pipeline build, resuming tailing the
+	// logs for the containers after the time provided.
+	ReattachBuild(context.Context, *pipeline.Build, time.Time) error
+}
@@ -6,6 +6,7 @@ package runtime
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/go-vela/pkg-runtime/runtime/docker"
 	"github.com/go-vela/pkg-runtime/runtime/kubernetes"
@@ -25,12 +26,90 @@ type Setup struct {
 	Driver string
 	// specifies the path to a configuration file to use for the runtime client
 	ConfigFile string
+	// specifies the host (unix://, tcp://, npipe:// or ssh://) for the daemon to use for the runtime client (only used by docker)
+	Host string
+	// specifies the path to the certificate authority for the daemon to use for the runtime client (only used by docker)
+	TLSCA string
+	// specifies the path to the certificate for connecting to the daemon to use for the runtime client (only used by docker)
+	TLSCert string
+	// specifies the path to the key for connecting to the daemon to use for the runtime client (only used by docker)
+	TLSKey string
+	// specifies the timeout for requests to the daemon to use for the runtime client (only used by docker)
+	Timeout time.Duration
 	// specifies a list of host volumes to use for the runtime client
 	HostVolumes []string
+	// specifies the driver for the network for builds for the runtime client (only used by docker)
+	NetworkDriver string
+	// specifies a list of subnet pools, in the form <cidr>[=<size>], for the network for builds for the runtime client (only used by docker)
+	NetworkSubnets []string
+	// specifies to restrict the network for builds from external access for the runtime client (only used by docker)
+	NetworkInternal bool
+	// specifies to enable IPv6 for the network for builds for the runtime client (only used by docker)
+	NetworkIPv6 bool
+	// specifies a list of DNS servers for containers on the network for builds for the runtime client (only used by docker)
+	NetworkDNS []string
+	// specifies a list of DNS search domains for containers on the network for builds for the runtime client (only used by docker)
+	NetworkDNSSearch []string
+	// specifies a list of extra hosts, in the form <host>:<ip>, for containers on the network for builds for the runtime client (only used by docker)
+	NetworkExtraHosts []string
+	// specifies to restrict the destinations builds are able to reach for the runtime client
+	EgressPolicy bool
+	// specifies a list of destinations, in the form <cidr>[=<port>[/<protocol>]], builds are allowed to reach for the runtime client (only used by kubernetes)
+	EgressAllow []string
+	// specifies the container running the proxy, in the form <container>:<port>, builds reach external hosts through for the runtime client (only used by docker)
+	EgressProxy string
 	// specifies the namespace to use for the runtime client (only used by kubernetes)
 	Namespace string
+	// specifies the path to a pod template file merged into build pods for the runtime client (only used by kubernetes)
+	PodTemplate string
+	// specifies the type of volume (emptyDir, memory, pvc or ephemeral) for the workspace for the runtime client (only used by kubernetes)
+	WorkspaceVolume string
+	// specifies the storage class for the workspace volume for the runtime client (only used by kubernetes)
+	WorkspaceStorageClass string
+	// specifies the size (i.e. 10Gi) for the workspace volume for the runtime client (only used by kubernetes)
+	WorkspaceSize string
 	// specifies a list of privileged images to use for the runtime client
 	PrivilegedImages []string
+	// specifies a list of image patterns permitted to run for the runtime client
+	AllowedImages []string
+	// specifies a list of image patterns not permitted to run for the runtime client
+	DeniedImages []string
+	// specifies a list of registries permitted to host images for the runtime client
+	AllowedRegistries []string
+	// specifies a list of registries not permitted to host images for the runtime client
+	DeniedRegistries []string
+	// specifies a list of registry mirrors, in the form <registry>=<mirror>, to use for the runtime client
+	RegistryMirrors []string
+	// specifies to pull from the origin registry when a mirror fails for the runtime client (only used by docker)
+	RegistryMirrorFallback bool
+	// specifies a list of paths to public keys for verifying image signatures for the runtime client
+	SignatureKeys []string
+	// specifies a list of image patterns that require a valid signature for the runtime client
+	SignedImages []string
+	// specifies the default platform, in the form <os>/<arch>[/<variant>], for images for the runtime client
+	Platform string
+	// specifies the maximum bytes (i.e. 10MB) of logs for each container for the runtime client
+	LogLimitBytes string
+	// specifies the maximum lines of logs for each container for the runtime client
+	LogLimitLines int64
+	// specifies the number of lines retained from the end of the logs exceeding the limits for the runtime client
+	LogLimitTail int
+	// specifies to kill a container when the logs exceed the limits for the runtime client
+	LogLimitKill bool
+	// specifies the secrets, by name, delivered to containers as files in /vela/secrets for the runtime client
+	SecretFiles []string
+	// specifies the ID of the worker labeled on resources for the runtime client
+	WorkerID string
+	// specifies the duration an image can go unused before it is removed for the runtime client (only used by docker)
+	ImageGCMaxAge time.Duration
+	// specifies the minimum duration between image removal after builds for the runtime client (only used by docker)
+	ImageGCInterval time.Duration
+	// specifies the disk usage of images that triggers image removal for the runtime client (only used by docker)
+	ImageGCHighWater string
+	// specifies the disk usage of images to reduce to during image removal for the runtime client (only used by docker)
+	ImageGCLowWater string
+	// specifies a list of image patterns never removed for the runtime client (only used by docker)
+	ImageGCProtectedImages []string
 }
 
 // Docker creates and returns a Vela engine capable of
@@ -42,8 +121,33 @@ func (s *Setup) Docker() (Engine, error) {
 	//
 	// https://pkg.go.dev/github.com/go-vela/pkg-runtime/runtime/docker?tab=doc#New
 	return docker.New(
+		docker.WithHost(s.Host),
+		docker.WithTLS(s.TLSCA, s.TLSCert, s.TLSKey),
+		docker.WithTimeout(s.Timeout),
 		docker.WithHostVolumes(s.HostVolumes),
+		docker.WithNetworkDriver(s.NetworkDriver),
+		docker.WithNetworkSubnets(s.NetworkSubnets),
+		docker.WithNetworkInternal(s.NetworkInternal),
+		docker.WithNetworkIPv6(s.NetworkIPv6),
+		docker.WithNetworkDNS(s.NetworkDNS, s.NetworkDNSSearch),
+		docker.WithNetworkExtraHosts(s.NetworkExtraHosts),
+		docker.WithEgressPolicy(s.EgressPolicy, s.EgressProxy),
 		docker.WithPrivilegedImages(s.PrivilegedImages),
+		docker.WithImagePolicy(s.AllowedImages, s.DeniedImages),
+		docker.WithRegistryPolicy(s.AllowedRegistries, s.DeniedRegistries),
+		docker.WithRegistryMirrors(s.RegistryMirrors),
+		docker.WithRegistryMirrorFallback(s.RegistryMirrorFallback),
+		docker.WithImageVerification(s.SignatureKeys, s.SignedImages),
+		docker.WithImageGCMaxAge(s.ImageGCMaxAge),
+		docker.WithImageGCInterval(s.ImageGCInterval),
+		docker.WithImageGCThresholds(s.ImageGCHighWater, s.ImageGCLowWater),
+		docker.WithImageGCProtectedImages(s.ImageGCProtectedImages),
+		docker.WithPlatform(s.Platform),
+		docker.WithLogLimits(s.LogLimitBytes, s.LogLimitLines),
+		docker.WithLogLimitTail(s.LogLimitTail),
+		docker.WithLogLimitKill(s.LogLimitKill),
+		docker.WithSecretFiles(s.SecretFiles),
+		docker.WithWorkerID(s.WorkerID),
 	)
 }
 
@@ -59,7 +163,20 @@ func (s *Setup) Kubernetes() (Engine, error) {
 		kubernetes.WithConfigFile(s.ConfigFile),
 		kubernetes.WithHostVolumes(s.HostVolumes),
 		kubernetes.WithNamespace(s.Namespace),
+		kubernetes.WithPodTemplate(s.PodTemplate),
+		kubernetes.WithWorkspaceVolume(s.WorkspaceVolume, s.WorkspaceStorageClass, s.WorkspaceSize),
 		kubernetes.WithPrivilegedImages(s.PrivilegedImages),
+		kubernetes.WithImagePolicy(s.AllowedImages, s.DeniedImages),
+		kubernetes.WithRegistryPolicy(s.AllowedRegistries, s.DeniedRegistries),
+		kubernetes.WithRegistryMirrors(s.RegistryMirrors),
+		kubernetes.WithImageVerification(s.SignatureKeys, s.SignedImages),
+		kubernetes.WithPlatform(s.Platform),
+		kubernetes.WithLogLimits(s.LogLimitBytes, s.LogLimitLines),
+		kubernetes.WithLogLimitTail(s.LogLimitTail),
+		kubernetes.WithLogLimitKill(s.LogLimitKill),
+		kubernetes.WithSecretFiles(s.SecretFiles),
+		kubernetes.WithWorkerID(s.WorkerID),
+		kubernetes.WithEgressPolicy(s.EgressPolicy, s.EgressAllow),
 	)
 }
 
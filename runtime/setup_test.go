@@ -6,6 +6,7 @@ package runtime
 
 import (
 	"testing"
+	"time"
 
 	"github.com/go-vela/types/constants"
 )
@@ -23,6 +24,97 @@ func TestRuntime_Setup_Docker(t *testing.T) {
 	}
 }
 
+func TestRuntime_Setup_Docker_Mirrors(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		mirrors []string
+	}{
+		{
+			failure: false,
+			mirrors: []string{"docker.io=mirror.company.com:5000"},
+		},
+		{
+			failure: true,
+			mirrors: []string{"docker.io"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_setup := &Setup{
+			Driver:          constants.DriverDocker,
+			RegistryMirrors: test.mirrors,
+		}
+
+		_, err := _setup.Docker()
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("Docker should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Docker returned err: %v", err)
+		}
+	}
+}
+
+func TestRuntime_Setup_Docker_Host(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		host    string
+		ca      string
+	}{
+		{
+			failure: false,
+			host:    "tcp://127.0.0.1:2376",
+			ca:      "docker/testdata/tls/ca.pem",
+		},
+		{
+			failure: false,
+			host:    "ssh://vela@docker.example.com",
+		},
+		{
+			failure: true,
+			host:    "127.0.0.1:2376",
+		},
+		{
+			failure: true,
+			host:    "tcp://127.0.0.1:2376",
+			ca:      "docker/testdata/tls/notfound.pem",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_setup := &Setup{
+			Driver:  constants.DriverDocker,
+			Host:    test.host,
+			TLSCA:   test.ca,
+			Timeout: time.Minute,
+		}
+
+		_, err := _setup.Docker()
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("Docker for %s should have returned err", test.host)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Docker for %s returned err: %v", test.host, err)
+		}
+	}
+}
+
 func TestRuntime_Setup_Kubernetes(t *testing.T) {
 	// setup types
 	_setup := &Setup{
@@ -89,3 +181,121 @@ func TestRuntime_Validate(t *testing.T) {
 		}
 	}
 }
+
+func TestRuntime_Setup_Docker_Network(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		subnets []string
+		dns     []string
+		hosts   []string
+	}{
+		{
+			failure: false,
+			subnets: []string{"10.200.0.0/16=24"},
+			dns:     []string{"10.0.0.2"},
+			hosts:   []string{"registry.company.com:10.0.0.5"},
+		},
+		{
+			failure: true,
+			subnets: []string{"10.200.0.0"},
+		},
+		{
+			failure: true,
+			dns:     []string{"dns.company.com"},
+		},
+		{
+			failure: true,
+			hosts:   []string{"registry.company.com"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_setup := &Setup{
+			Driver:            constants.DriverDocker,
+			NetworkDriver:     "bridge",
+			NetworkSubnets:    test.subnets,
+			NetworkInternal:   true,
+			NetworkDNS:        test.dns,
+			NetworkDNSSearch:  []string{"company.com"},
+			NetworkExtraHosts: test.hosts,
+		}
+
+		_, err := _setup.Docker()
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("Docker should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Docker returned err: %v", err)
+		}
+	}
+}
+
+func TestRuntime_Setup_EgressPolicy(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		setup   *Setup
+	}{
+		{
+			failure: false,
+			setup: &Setup{
+				Driver:       constants.DriverDocker,
+				EgressPolicy: true,
+				EgressProxy:  "vela-proxy:3128",
+			},
+		},
+		{
+			failure: true,
+			setup: &Setup{
+				Driver:       constants.DriverDocker,
+				EgressPolicy: true,
+				EgressProxy:  "vela-proxy",
+			},
+		},
+		{
+			failure: false,
+			setup: &Setup{
+				Driver:       constants.DriverKubernetes,
+				ConfigFile:   "testdata/config",
+				Namespace:    "docker",
+				EgressPolicy: true,
+				EgressAllow:  []string{"10.0.0.0/8", "192.168.1.10/32=443/tcp"},
+			},
+		},
+		{
+			failure: true,
+			setup: &Setup{
+				Driver:       constants.DriverKubernetes,
+				ConfigFile:   "testdata/config",
+				Namespace:    "docker",
+				EgressPolicy: true,
+				EgressAllow:  []string{"10.0.0.1"},
+			},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_, err := New(test.setup)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("New for %s should have returned err", test.setup.Driver)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("New for %s returned err: %v", test.setup.Driver, err)
+		}
+	}
+}